@@ -36,6 +36,11 @@ func New(ns, hostname string) *Server {
 	return server
 }
 
+// StatusCode returns the http status the error is rendered with
+func (e Error) StatusCode() int {
+	return e.Code
+}
+
 func Errorf(code int, format string, args ...interface{}) Error {
 	return Error{
 		error: fmt.Errorf(format, args...),
@@ -12,13 +12,21 @@ import (
 )
 
 type Context struct {
+	actor   string
 	context context.Context
 	id      string
 	logger  *logger.Logger
+	name    string
 	request *http.Request
+	token   string
 	writer  io.Writer
 }
 
+// Actor returns who made the request and the id of the token they used, if any
+func (c *Context) Actor() (string, string) {
+	return c.actor, c.token
+}
+
 func (c *Context) Context() context.Context {
 	return c.context
 }
@@ -52,10 +60,21 @@ func (c *Context) Header(name string) string {
 	return c.request.Header.Get(name)
 }
 
+// Identify records who made the request
+func (c *Context) Identify(actor, token string) {
+	c.actor = actor
+	c.token = token
+}
+
 func (c *Context) Logf(format string, args ...interface{}) {
 	c.logger.Logf(format, args...)
 }
 
+// Name returns the name of the route handling the request
+func (c *Context) Name() string {
+	return c.name
+}
+
 func (c *Context) Query(name string) string {
 	return c.request.URL.Query().Get(name)
 }
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Endpoint describes a route for the api description
+// routes record their method, path and name, the rest is declared where the route is registered
+type Endpoint struct {
+	Method string
+	Name   string
+	Path   string
+	Stream bool
+
+	body     string
+	form     []param
+	headers  []param
+	produces string
+	query    []param
+	response interface{}
+}
+
+type param struct {
+	name     string
+	kind     string
+	required bool
+}
+
+var pathVar = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Body declares that the request body is sent as is with the given content type
+func (e *Endpoint) Body(contentType string) *Endpoint {
+	e.body = contentType
+	return e
+}
+
+// Header declares a request header, streams take their options as headers
+func (e *Endpoint) Header(name, kind string) *Endpoint {
+	e.headers = append(e.headers, param{name: name, kind: kind})
+	return e
+}
+
+// Form declares a form encoded request parameter, kind is a json schema type such as string or boolean
+// parameters that may be repeated have the kind array
+func (e *Endpoint) Form(name, kind string) *Endpoint {
+	e.form = append(e.form, param{name: name, kind: kind})
+	return e
+}
+
+// Query declares a query string parameter, kind is a json schema type such as string or integer
+func (e *Endpoint) Query(name, kind string) *Endpoint {
+	e.query = append(e.query, param{name: name, kind: kind})
+	return e
+}
+
+// Required marks previously declared parameters as required
+func (e *Endpoint) Required(names ...string) *Endpoint {
+	for _, n := range names {
+		for _, ps := range [][]param{e.form, e.headers, e.query} {
+			for i := range ps {
+				if ps[i].name == n {
+					ps[i].required = true
+				}
+			}
+		}
+	}
+
+	return e
+}
+
+// Produces declares that the response is sent as is with the given content type rather than as json
+func (e *Endpoint) Produces(contentType string) *Endpoint {
+	e.produces = contentType
+	return e
+}
+
+// Returns declares the type of the json response with a value of that type
+func (e *Endpoint) Returns(v interface{}) *Endpoint {
+	e.response = v
+	return e
+}
+
+// Vars returns the names of the variables in the endpoint path
+func (e *Endpoint) Vars() []string {
+	vars := []string{}
+
+	for _, m := range pathVar.FindAllStringSubmatch(e.Path, -1) {
+		vars = append(vars, m[1])
+	}
+
+	return vars
+}
+
+// template returns the path with variable patterns removed, as openapi expects
+func (e *Endpoint) template() string {
+	return pathVar.ReplaceAllString(e.Path, "{$1}")
+}
+
+func (rt *Router) endpoint(method, path, name string) *Endpoint {
+	e := &Endpoint{
+		Method: method,
+		Name:   name,
+		Path:   strings.TrimRight(rt.prefix, "/") + path,
+	}
+
+	if rt.Server != nil {
+		rt.Server.endpoints = append(rt.Server.endpoints, e)
+	}
+
+	return e
+}
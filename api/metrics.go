@@ -0,0 +1,20 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/convox/praxis/metrics"
+)
+
+var (
+	metricRequests        = metrics.NewCounter("convox_api_requests_total", "api requests by route, method and response code", "route", "method", "code")
+	metricRequestDuration = metrics.NewHistogram("convox_api_request_duration_seconds", "api request latency by route", metrics.DefaultBuckets, "route")
+	metricStreams         = metrics.NewGauge("convox_api_streams_active", "open api streams by route", "route")
+)
+
+// measure records the count and latency of a request
+func measure(c *Context, code int, start time.Time) {
+	metricRequests.Inc(c.Name(), c.Request().Method, strconv.Itoa(code))
+	metricRequestDuration.Observe(time.Since(start).Seconds(), c.Name())
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// OpenAPIPath is where servers publish their api description
+const OpenAPIPath = "/openapi.json"
+
+type schemas map[string]interface{}
+
+// OpenAPI returns an openapi 3 description of every endpoint registered on the server
+// response schemas are generated from the values declared with Endpoint.Returns
+func (s *Server) OpenAPI(title, version string) map[string]interface{} {
+	components := schemas{}
+	paths := map[string]map[string]interface{}{}
+
+	for _, e := range s.endpoints {
+		path := e.template()
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+
+		paths[path][strings.ToLower(e.Method)] = e.operation(components)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": components,
+			"securitySchemes": map[string]interface{}{
+				"basic": map[string]interface{}{"type": "http", "scheme": "basic"},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"basic": []string{}},
+		},
+	}
+}
+
+// OpenAPIHandler serves the api description as json
+func (s *Server) OpenAPIHandler(title, version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.MarshalIndent(s.OpenAPI(title, version), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+func (e *Endpoint) operation(components schemas) map[string]interface{} {
+	params := []interface{}{}
+
+	for _, v := range e.Vars() {
+		params = append(params, map[string]interface{}{
+			"in":       "path",
+			"name":     v,
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	for _, q := range e.query {
+		params = append(params, map[string]interface{}{
+			"in":       "query",
+			"name":     q.name,
+			"required": q.required,
+			"schema":   paramSchema(q.kind),
+		})
+	}
+
+	for _, h := range e.headers {
+		params = append(params, map[string]interface{}{
+			"in":       "header",
+			"name":     h.name,
+			"required": h.required,
+			"schema":   paramSchema(h.kind),
+		})
+	}
+
+	op := map[string]interface{}{
+		"operationId": e.Name,
+		"parameters":  params,
+		"responses": map[string]interface{}{
+			"200": e.success(components),
+			"default": map[string]interface{}{
+				"description": "error",
+				"content": map[string]interface{}{
+					"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+
+	switch {
+	case e.Stream:
+		op["x-convox-stream"] = true
+		op["requestBody"] = binaryBody("application/octet-stream")
+	case e.body != "":
+		op["requestBody"] = binaryBody(e.body)
+	case len(e.form) > 0:
+		props := map[string]interface{}{}
+		required := []string{}
+
+		for _, f := range e.form {
+			props[f.name] = paramSchema(f.kind)
+
+			if f.required {
+				required = append(required, f.name)
+			}
+		}
+
+		schema := map[string]interface{}{"type": "object", "properties": props}
+
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+
+		op["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/x-www-form-urlencoded": map[string]interface{}{"schema": schema},
+			},
+		}
+	}
+
+	return op
+}
+
+func (e *Endpoint) success(components schemas) map[string]interface{} {
+	switch {
+	case e.Stream:
+		return map[string]interface{}{
+			"description": "stream",
+			"content": map[string]interface{}{
+				"application/octet-stream": map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+			},
+		}
+	case e.produces != "":
+		return map[string]interface{}{
+			"description": "ok",
+			"content": map[string]interface{}{
+				e.produces: map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+			},
+		}
+	case e.response == nil:
+		return map[string]interface{}{"description": "ok"}
+	}
+
+	return map[string]interface{}{
+		"description": "ok",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": components.schema(reflect.TypeOf(e.response))},
+		},
+	}
+}
+
+func binaryBody(contentType string) map[string]interface{} {
+	return map[string]interface{}{
+		"content": map[string]interface{}{
+			contentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+		},
+	}
+}
+
+func paramSchema(kind string) map[string]interface{} {
+	if kind == "array" {
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	}
+
+	return map[string]interface{}{"type": kind}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns the json schema for a type as encoding/json would render it
+// named structs are added to the components and referenced
+func (s schemas) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+
+		if _, ok := s[t.Name()]; !ok {
+			// reserve the name first so recursive types terminate
+			s[t.Name()] = nil
+			s[t.Name()] = s.object(t)
+		}
+
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (s schemas) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+
+	s.properties(t, props)
+
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+func (s schemas) properties(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+
+		switch {
+		case tag == "-":
+			continue
+		case f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct:
+			s.properties(f.Type, props)
+			continue
+		case f.PkgPath != "":
+			continue
+		case name == "":
+			name = f.Name
+		}
+
+		props[name] = s.schema(f.Type)
+	}
+}
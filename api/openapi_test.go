@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widget struct {
+	Id      string            `json:"id"`
+	Created time.Time         `json:"created"`
+	Labels  map[string]string `json:"labels"`
+	Parts   []part            `json:"parts"`
+	Secret  string            `json:"-"`
+}
+
+type part struct {
+	Name string
+}
+
+func WidgetCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error { return nil }
+func WidgetGet(w http.ResponseWriter, r *http.Request, c *api.Context) error    { return nil }
+
+func testOpenAPI(t *testing.T) map[string]interface{} {
+	s := api.New("test", "test")
+
+	sr := s.Subrouter("/")
+	sr.Route("POST", "/widgets", WidgetCreate).Form("name", "string").Form("tag", "array").Required("name").Returns(widget{})
+	sr.Route("GET", "/widgets/{id}/files/{key:.*}", WidgetGet).Query("limit", "integer").Produces("application/octet-stream")
+
+	s.Router.HandleFunc(api.OpenAPIPath, s.OpenAPIHandler("Test", "1"))
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", api.OpenAPIPath, nil))
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+
+	return doc
+}
+
+func TestOpenAPIPaths(t *testing.T) {
+	doc := testOpenAPI(t)
+
+	assert.Equal(t, "3.0.0", doc["openapi"])
+
+	paths := doc["paths"].(map[string]interface{})
+	require.Len(t, paths, 2)
+
+	create := paths["/widgets"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, "WidgetCreate", create["operationId"])
+
+	form := create["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/x-www-form-urlencoded"].(map[string]interface{})["schema"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"name"}, form["required"])
+	assert.Equal(t, "array", form["properties"].(map[string]interface{})["tag"].(map[string]interface{})["type"])
+
+	res := create["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/widget", res["$ref"])
+
+	get := paths["/widgets/{id}/files/{key}"].(map[string]interface{})["get"].(map[string]interface{})
+	params := get["parameters"].([]interface{})
+	require.Len(t, params, 3)
+	assert.Equal(t, "id", params[0].(map[string]interface{})["name"])
+	assert.Equal(t, "key", params[1].(map[string]interface{})["name"])
+	assert.Equal(t, "query", params[2].(map[string]interface{})["in"])
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := testOpenAPI(t)
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	props := schemas["widget"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Len(t, props, 4)
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, props["created"])
+	assert.Equal(t, map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}, props["labels"])
+	assert.Equal(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/part"}}, props["parts"])
+
+	part := schemas["part"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, part["Name"])
+}
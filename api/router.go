@@ -1,13 +1,16 @@
 package api
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 
 	"golang.org/x/net/websocket"
 
@@ -20,19 +23,30 @@ type Router struct {
 	Middleware []Middleware
 	Parent     *Router
 	Server     *Server
+
+	prefix string
 }
 
-func (rt *Router) Route(method, path string, fn HandlerFunc) {
+// Route registers a handler named after its function and returns its endpoint to describe it further
+func (rt *Router) Route(method, path string, fn HandlerFunc) *Endpoint {
 	sig := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
 	parts := strings.Split(sig, ".")
 	name := parts[len(parts)-1]
 
 	rt.Handle(path, rt.api(name, fn)).Methods(method)
+
+	return rt.endpoint(method, path, name)
 }
 
-func (rt *Router) Stream(name, path string, fn StreamFunc) {
-	rt.Handle(path, rt.streamWebsocket(name, fn)).Methods("GET").Headers("Upgrade", "websocket")
+// Stream registers a bidirectional stream served over a websocket upgrade or an http2 POST
+func (rt *Router) Stream(name, path string, fn StreamFunc) *Endpoint {
+	rt.Handle(path, rt.upgrade(name, rt.streamWebsocket(name, fn))).Methods("GET").Headers("Upgrade", "websocket")
 	rt.Handle(path, rt.streamHTTP2(name, fn)).Methods("POST")
+
+	e := rt.endpoint("POST", path, name)
+	e.Stream = true
+
+	return e
 }
 
 func (rt *Router) Use(mw Middleware) {
@@ -50,10 +64,40 @@ func (rt *Router) UseHandlerFunc(fn http.HandlerFunc) {
 
 func (rt *Router) streamHTTP2(at string, fn StreamFunc) http.HandlerFunc {
 	return rt.api(at, func(w http.ResponseWriter, r *http.Request, c *Context) error {
+		metricStreams.Inc(at)
+		defer metricStreams.Dec(at)
+
 		return fn(types.Stream{Reader: r.Body, Writer: w}, c)
 	})
 }
 
+// upgrade runs the middleware for a websocket stream before the connection is upgraded
+func (rt *Router) upgrade(at string, h http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		lw := responseWriter{ResponseWriter: w, code: 200}
+
+		c, err := rt.context(at, &lw, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fn := rt.wrap(func(w http.ResponseWriter, r *http.Request, c *Context) error {
+			h.ServeHTTP(w, r)
+			return nil
+		}, rt.middleware()...)
+
+		if err := fn(&lw, r, c); err != nil {
+			c.Error(err)
+		}
+
+		rt.audit(c, lw.code, start)
+		measure(c, lw.code, start)
+	}
+}
+
 func (rt *Router) streamWebsocket(at string, fn StreamFunc) websocket.Handler {
 	return func(ws *websocket.Conn) {
 		c, err := rt.context(at, ws, ws.Request())
@@ -62,6 +106,9 @@ func (rt *Router) streamWebsocket(at string, fn StreamFunc) websocket.Handler {
 			return
 		}
 
+		metricStreams.Inc(at)
+		defer metricStreams.Dec(at)
+
 		if err := fn(ws, c); err != nil {
 			fmt.Printf("err = %+v\n", err)
 			return
@@ -71,6 +118,8 @@ func (rt *Router) streamWebsocket(at string, fn StreamFunc) websocket.Handler {
 
 func (rt *Router) api(at string, fn HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
 		lw := responseWriter{ResponseWriter: w, code: 200}
 
 		c, err := rt.context(at, &lw, r)
@@ -85,24 +134,35 @@ func (rt *Router) api(at string, fn HandlerFunc) http.HandlerFunc {
 
 		c.logger = c.logger.Start()
 
-		mw := []Middleware{}
-
-		if rt.Parent != nil {
-			mw = append(mw, rt.Parent.Middleware...)
-		}
-
-		mw = append(mw, rt.Middleware...)
-
-		fnmw := rt.wrap(fn, mw...)
+		fnmw := rt.wrap(fn, rt.middleware()...)
 
 		if err := fnmw(&lw, r, c); err != nil {
 			c.Error(err)
 		}
 
+		rt.audit(c, lw.code, start)
+		measure(c, lw.code, start)
+
 		c.logger.Logf("code=%d bytes=%d", lw.code, lw.bytes)
 	}
 }
 
+// audit reports requests that may change state, which is anything other than a read
+// streams are always reported as they are requested with an upgraded GET
+func (rt *Router) audit(c *Context, code int, start time.Time) {
+	if rt.Server.Audit == nil {
+		return
+	}
+
+	r := c.Request()
+
+	if (r.Method == "GET" || r.Method == "HEAD") && r.Header.Get("Upgrade") == "" {
+		return
+	}
+
+	rt.Server.Audit(c, code, time.Since(start))
+}
+
 func (rt *Router) context(name string, w io.Writer, r *http.Request) (*Context, error) {
 	id, err := Key(12)
 	if err != nil {
@@ -113,11 +173,22 @@ func (rt *Router) context(name string, w io.Writer, r *http.Request) (*Context,
 		context: context.WithValue(r.Context(), "request.id", id),
 		id:      id,
 		logger:  rt.Server.Logger.Prepend("id=%s", id).At(name),
+		name:    name,
 		request: r,
 		writer:  w,
 	}, nil
 }
 
+func (rt *Router) middleware() []Middleware {
+	mw := []Middleware{}
+
+	if rt.Parent != nil {
+		mw = append(mw, rt.Parent.Middleware...)
+	}
+
+	return append(mw, rt.Middleware...)
+}
+
 func (rt *Router) wrap(fn HandlerFunc, m ...Middleware) HandlerFunc {
 	if len(m) == 0 {
 		return fn
@@ -138,6 +209,17 @@ func (w *responseWriter) Flush() {
 	}
 }
 
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response can not be hijacked")
+	}
+
+	w.code = http.StatusSwitchingProtocols
+
+	return h.Hijack()
+}
+
 func (w *responseWriter) Write(data []byte) (int, error) {
 	n, err := w.ResponseWriter.Write(data)
 	w.bytes += int64(n)
@@ -4,17 +4,25 @@ import (
 	"crypto/tls"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/convox/praxis/logger"
+	"github.com/convox/praxis/types"
 )
 
 type Server struct {
-	Hostname   string
-	Logger     *logger.Logger
-	Router     *Router
-	middleware []Middleware
+	Audit       AuditFunc
+	Certificate *tls.Certificate
+	Hostname    string
+	Logger      *logger.Logger
+	Router      *Router
+	endpoints   []*Endpoint
+	middleware  []Middleware
 }
 
+// AuditFunc is called after each request that may change state
+type AuditFunc func(c *Context, code int, duration time.Duration)
+
 func (s *Server) Listen(proto, addr string) error {
 	s.Logger.At("listen").Logf("hostname=%q proto=%q addr=%q", s.Hostname, proto, addr)
 
@@ -29,12 +37,16 @@ func (s *Server) Listen(proto, addr string) error {
 			NextProtos: []string{"h2"},
 		}
 
-		cert, err := generateSelfSignedCertificate(s.Hostname)
-		if err != nil {
-			return err
+		if s.Certificate == nil {
+			cert, err := GenerateSelfSignedCertificate(s.Hostname)
+			if err != nil {
+				return err
+			}
+
+			s.Certificate = &cert
 		}
 
-		config.Certificates = append(config.Certificates, cert)
+		config.Certificates = append(config.Certificates, *s.Certificate)
 
 		l = tls.NewListener(l, config)
 	}
@@ -42,8 +54,22 @@ func (s *Server) Listen(proto, addr string) error {
 	return http.Serve(l, s)
 }
 
-func (s *Server) Route(method, path string, fn HandlerFunc) {
-	s.Router.Route(method, path, fn)
+// Fingerprint returns the fingerprint of the certificate the server presents
+func (s *Server) Fingerprint() string {
+	if s.Certificate == nil || len(s.Certificate.Certificate) == 0 {
+		return ""
+	}
+
+	return types.Fingerprint(s.Certificate.Certificate[0])
+}
+
+// Endpoints returns every route registered on the server and its subrouters
+func (s *Server) Endpoints() []*Endpoint {
+	return s.endpoints
+}
+
+func (s *Server) Route(method, path string, fn HandlerFunc) *Endpoint {
+	return s.Router.Route(method, path, fn)
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -55,6 +81,7 @@ func (s *Server) Subrouter(prefix string) Router {
 		Parent: s.Router,
 		Router: s.Router.PathPrefix(prefix).Subrouter(),
 		Server: s,
+		prefix: prefix,
 	}
 }
 
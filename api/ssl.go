@@ -7,21 +7,63 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
 	"time"
 )
 
-func generateSelfSignedCertificate(host string) (tls.Certificate, error) {
-	rkey, err := rsa.GenerateKey(rand.Reader, 2048)
+// LoadCertificate reads a certificate and key from disk, generating and saving a self-signed certificate if they do not exist
+// a stable certificate lets clients pin its fingerprint across restarts
+func LoadCertificate(host, certFile, keyFile string) (tls.Certificate, error) {
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && time.Now().Before(leaf.NotAfter) {
+			return cert, nil
+		}
+	}
+
+	pub, key, err := generateSelfSignedPEM(host)
 	if err != nil {
 		return tls.Certificate{}, err
 	}
 
-	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	if err := ioutil.WriteFile(certFile, pub, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(pub, key)
+}
+
+// GenerateSelfSignedCertificate returns a new certificate for host that is not saved anywhere
+func GenerateSelfSignedCertificate(host string) (tls.Certificate, error) {
+	pub, key, err := generateSelfSignedPEM(host)
 	if err != nil {
 		return tls.Certificate{}, err
 	}
 
+	return tls.X509KeyPair(pub, key)
+}
+
+func generateSelfSignedPEM(host string) ([]byte, []byte, error) {
+	rkey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	template := x509.Certificate{
 		SerialNumber: serial,
 		Subject: pkix.Name{
@@ -38,16 +80,11 @@ func generateSelfSignedCertificate(host string) (tls.Certificate, error) {
 
 	data, err := x509.CreateCertificate(rand.Reader, &template, &template, &rkey.PublicKey, rkey)
 	if err != nil {
-		return tls.Certificate{}, err
+		return nil, nil, err
 	}
 
 	pub := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: data})
 	key := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rkey)})
 
-	cert, err := tls.X509KeyPair(pub, key)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-
-	return cert, nil
+	return pub, key, nil
 }
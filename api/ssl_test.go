@@ -0,0 +1,33 @@
+package api_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/praxis/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	crt := filepath.Join(dir, "certs", "rack.crt")
+	key := filepath.Join(dir, "certs", "rack.key")
+
+	c1, err := api.LoadCertificate("rack.convox", crt, key)
+	require.NoError(t, err)
+
+	c2, err := api.LoadCertificate("rack.convox", crt, key)
+	require.NoError(t, err)
+
+	assert.Equal(t, c1.Certificate[0], c2.Certificate[0])
+
+	fi, err := os.Stat(key)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -64,6 +66,10 @@ func main() {
 		flagApp = v
 	}
 
+	if v := os.Getenv("BUILD_AUTH"); v != "" {
+		flagAuth = v
+	}
+
 	if v := os.Getenv("BUILD_DEVELOPMENT"); v != "" {
 		flagDevelopment = (v == "true")
 	}
@@ -105,9 +111,18 @@ func main() {
 }
 
 func auth() error {
-	registries, err := Rack.RegistryList()
-	if err != nil {
-		return err
+	registries := types.Registries{}
+
+	// the rack passes the credentials of its registries in the environment, app tokens can not list them
+	if flagAuth != "" {
+		data, err := base64.StdEncoding.DecodeString(flagAuth)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, &registries); err != nil {
+			return err
+		}
 	}
 
 	ar, err := Rack.AppRegistry(flagApp)
@@ -2,13 +2,17 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh/terminal"
 
+	"github.com/convox/praxis/router"
 	"github.com/convox/praxis/sdk/rack"
 	"github.com/convox/praxis/stdcli"
 	cli "gopkg.in/urfave/cli.v1"
@@ -50,6 +54,13 @@ func init() {
 				Action:      runAppsInfo,
 				Flags:       globalFlags,
 			},
+			cli.Command{
+				Name:        "maintenance",
+				Description: "take an application in or out of maintenance",
+				Usage:       "<on|off>",
+				Action:      runAppsMaintenance,
+				Flags:       append(globalFlags, routerFlag),
+			},
 		},
 	})
 }
@@ -168,6 +179,75 @@ func runAppsInfo(c *cli.Context) error {
 	return nil
 }
 
+func runAppsMaintenance(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	state := ""
+
+	switch c.Args()[0] {
+	case "on":
+		state = router.StateMaintenance
+	case "off":
+		state = router.StateServing
+	default:
+		return stdcli.Usage(c)
+	}
+
+	app, err := appName(c, ".")
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	s, err := Rack(c).SystemGet()
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	res, err := routerRequest(c, "GET", "/endpoints", nil)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	defer res.Body.Close()
+
+	var endpoints map[string]json.RawMessage
+
+	if err := json.NewDecoder(res.Body).Decode(&endpoints); err != nil {
+		return stdcli.Error(err)
+	}
+
+	hosts := []string{}
+
+	for host := range endpoints {
+		if strings.HasSuffix(host, fmt.Sprintf(".%s.%s", app, s.Name)) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return stdcli.Errorf("no endpoints found for app: %s", app)
+	}
+
+	sort.Strings(hosts)
+
+	stdcli.Startf("setting <name>%s</name> to <id>%s</id>", app, state)
+
+	for _, host := range hosts {
+		res, err := routerRequest(c, "POST", fmt.Sprintf("/endpoints/%s/state", host), url.Values{"state": {state}})
+		if err != nil {
+			return stdcli.Error(err)
+		}
+
+		res.Body.Close()
+	}
+
+	stdcli.OK()
+
+	return nil
+}
+
 func isAppStatus(r rack.Rack, app, status string) func() (bool, error) {
 	return func() (bool, error) {
 		app, err := r.AppGet(app)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func init() {
+	stdcli.RegisterCommand(cli.Command{
+		Name:        "audit",
+		Description: "show the audit log of changes made to the rack",
+		Action:      runAudit,
+		Flags: []cli.Flag{
+			rackFlag,
+			cli.StringFlag{
+				Name:  "actor",
+				Usage: "filter by token name or id",
+			},
+			cli.StringFlag{
+				Name:  "app, a",
+				Usage: "filter by app",
+			},
+			cli.IntFlag{
+				Name:  "limit",
+				Usage: "maximum number of events to show",
+				Value: 100,
+			},
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "how far back to retrieve events",
+				Value: "24h",
+			},
+		},
+	})
+}
+
+func runAudit(c *cli.Context) error {
+	since, err := time.ParseDuration(c.String("since"))
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	opts := types.SystemAuditOptions{
+		Actor: c.String("actor"),
+		App:   c.String("app"),
+		Limit: c.Int("limit"),
+		Since: time.Now().Add(-1 * since),
+	}
+
+	events, err := Rack(c).SystemAudit(opts)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	t := stdcli.NewTable("TIME", "ACTOR", "METHOD", "ROUTE", "APP", "CODE", "DURATION")
+
+	for _, e := range events {
+		t.AddRow(helpers.HumanizeTime(e.Time), e.Actor, e.Method, e.Route, e.App, fmt.Sprintf("%d", e.Code), fmt.Sprintf("%dms", e.Duration))
+	}
+
+	t.Print()
+
+	return nil
+}
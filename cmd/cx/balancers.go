@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/stdcli"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func init() {
+	stdcli.RegisterCommand(cli.Command{
+		Name:        "balancers",
+		Description: "list balancers",
+		Action:      runBalancers,
+		Flags:       globalFlags,
+	})
+}
+
+func runBalancers(c *cli.Context) error {
+	app, err := appName(c, ".")
+	if err != nil {
+		return err
+	}
+
+	bs, err := Rack(c).BalancerList(app)
+	if err != nil {
+		return err
+	}
+
+	t := stdcli.NewTable("NAME", "ENDPOINT", "PORT", "TARGET")
+
+	for _, b := range bs {
+		for _, e := range b.Endpoints {
+			target := e.Target
+
+			if e.Redirect != "" {
+				target = fmt.Sprintf("redirect %s", e.Redirect)
+			}
+
+			t.AddRow(b.Name, b.Endpoint, fmt.Sprintf("%d/%s", e.Port, e.Protocol), target)
+		}
+	}
+
+	t.Print()
+
+	return nil
+}
@@ -99,16 +99,13 @@ func runBuildsLogs(c *cli.Context) error {
 		return err
 	}
 
-	logs, err := Rack(c).BuildLogs(app, id)
-	if err != nil {
-		return err
-	}
+	r := Rack(c)
 
-	if _, err := io.Copy(os.Stdout, logs); err != nil {
-		return err
-	}
+	f := rack.NewLogFollower(r, types.LogsOptions{}, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		return r.BuildLogs(app, id, opts)
+	})
 
-	return nil
+	return f.Copy(os.Stdout)
 }
 
 func buildDirectory(r rack.Rack, app, dir string, opts types.BuildCreateOptions, w io.Writer) (*types.Build, error) {
@@ -178,12 +175,11 @@ func buildDirectory(r rack.Rack, app, dir string, opts types.BuildCreateOptions,
 }
 
 func buildLogs(r rack.Rack, build *types.Build, w io.Writer) error {
-	logs, err := r.BuildLogs(build.App, build.Id)
-	if err != nil {
-		return err
-	}
+	f := rack.NewLogFollower(r, types.LogsOptions{}, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		return r.BuildLogs(build.App, build.Id, opts)
+	})
 
-	go io.Copy(w, logs)
+	go f.Copy(w)
 
 	for {
 		b, err := r.BuildGet(build.App, build.Id)
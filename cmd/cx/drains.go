@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/stdcli"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func runLogsDrains(c *cli.Context) error {
+	app, err := appName(c, ".")
+	if err != nil {
+		return err
+	}
+
+	r := Rack(c)
+
+	if err := drainsSupported(r); err != nil {
+		return err
+	}
+
+	drains, err := r.DrainList(app)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	t := stdcli.NewTable("ID", "URL", "CREATED")
+
+	for _, d := range drains {
+		t.AddRow(d.Id, d.Url, helpers.HumanizeTime(d.Created))
+	}
+
+	t.Print()
+
+	return nil
+}
+
+func runLogsDrainsAdd(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	app, err := appName(c, ".")
+	if err != nil {
+		return err
+	}
+
+	url := c.Args()[0]
+
+	r := Rack(c)
+
+	if err := drainsSupported(r); err != nil {
+		return err
+	}
+
+	stdcli.Startf("adding <url>%s</url>", url)
+
+	drain, err := r.DrainCreate(app, url)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	fmt.Printf("drain: %s\n", drain.Id)
+
+	return nil
+}
+
+func runLogsDrainsRemove(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	app, err := appName(c, ".")
+	if err != nil {
+		return err
+	}
+
+	id := c.Args()[0]
+
+	stdcli.Startf("removing <id>%s</id>", id)
+
+	if err := Rack(c).DrainDelete(app, id); err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	return nil
+}
+
+func drainsSupported(r rack.Rack) error {
+	if !rack.Supports(r, "logs.drains") {
+		return fmt.Errorf("rack does not support log drains")
+	}
+
+	return nil
+}
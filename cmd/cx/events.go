@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func init() {
+	stdcli.RegisterCommand(cli.Command{
+		Name:        "events",
+		Description: "stream rack events",
+		Action:      runEvents,
+		Flags: []cli.Flag{
+			rackFlag,
+			cli.StringSliceFlag{
+				Name:  "action",
+				Usage: "only show these actions such as release:promote or build:* (repeatable)",
+			},
+			cli.StringFlag{
+				Name:  "app, a",
+				Usage: "only show events for an app",
+			},
+		},
+	})
+}
+
+func runEvents(c *cli.Context) error {
+	opts := types.EventStreamOptions{
+		Actions: c.StringSlice("action"),
+		App:     c.String("app"),
+	}
+
+	r, err := Rack(c).EventStream(opts)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	defer r.Close()
+
+	dec := json.NewDecoder(r)
+
+	for {
+		var e types.Event
+
+		if err := dec.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return stdcli.Error(err)
+		}
+
+		fmt.Println(eventLine(e))
+	}
+}
+
+func eventLine(e types.Event) string {
+	parts := []string{e.Timestamp.Local().Format("2006-01-02 15:04:05"), e.Action, e.Status}
+
+	if e.App != "" {
+		parts = append(parts, fmt.Sprintf("app=%s", e.App))
+	}
+
+	keys := []string{}
+
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, e.Data[k]))
+	}
+
+	if e.Error != "" {
+		parts = append(parts, fmt.Sprintf("error=%q", e.Error))
+	}
+
+	return strings.Join(parts, " ")
+}
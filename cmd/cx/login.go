@@ -14,7 +14,9 @@ import (
 
 	"golang.org/x/crypto/ssh/terminal"
 
+	"github.com/convox/praxis/sdk/rack"
 	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
@@ -70,8 +72,19 @@ func runLogin(c *cli.Context) error {
 	fmt.Println()
 	stdcli.Startf("Authenticating with <name>%s</name>", console)
 
+	pin, err := pinGet(console)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	config := &tls.Config{InsecureSkipVerify: true}
+
+	if pin != "" {
+		config.VerifyPeerCertificate = rack.VerifyFingerprint(pin)
+	}
+
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		TLSClientConfig: config,
 	}
 
 	var client = &http.Client{
@@ -113,6 +126,12 @@ func runLogin(c *cli.Context) error {
 		return stdcli.Errorf(p.Error)
 	}
 
+	if pin == "" && response.TLS != nil && len(response.TLS.PeerCertificates) > 0 {
+		if err := pinSet(console, types.Fingerprint(response.TLS.PeerCertificates[0].Raw)); err != nil {
+			return stdcli.Error(err)
+		}
+	}
+
 	if err := setConsoleHost(console); err != nil {
 		return stdcli.Error(err)
 	}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"time"
 
+	"github.com/convox/praxis/sdk/rack"
 	"github.com/convox/praxis/stdcli"
 	"github.com/convox/praxis/types"
 	cli "gopkg.in/urfave/cli.v1"
@@ -14,13 +16,17 @@ func init() {
 	flags := []cli.Flag{
 		cli.StringFlag{
 			Name:  "filter",
-			Usage: "filter logs",
+			Usage: "filter logs, such as: service=web status>=500",
 			Value: "",
 		},
 		cli.BoolFlag{
 			Name:  "follow, f",
 			Usage: "stream logs continuously",
 		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "output one json object per line",
+		},
 		cli.StringFlag{
 			Name:  "since",
 			Usage: "how far back to retrieve logs",
@@ -32,6 +38,31 @@ func init() {
 		Description: "show app logs",
 		Action:      runLogs,
 		Flags:       append(flags, globalFlags...),
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name:        "drains",
+				Description: "list log drains",
+				Action:      runLogsDrains,
+				Flags:       globalFlags,
+				Subcommands: []cli.Command{
+					cli.Command{
+						Name:        "add",
+						Description: "forward app logs to a syslog server, an http endpoint or a file",
+						Action:      runLogsDrainsAdd,
+						Usage:       "<syslog+tls://host:port | https://host/path | file:///path>",
+						Flags:       globalFlags,
+					},
+					cli.Command{
+						Name:        "remove",
+						Aliases:     []string{"rm"},
+						Description: "remove a log drain",
+						Action:      runLogsDrainsRemove,
+						Usage:       "<id>",
+						Flags:       globalFlags,
+					},
+				},
+			},
+		},
 	})
 }
 
@@ -49,18 +80,46 @@ func runLogs(c *cli.Context) error {
 	opts := types.LogsOptions{
 		Filter: c.String("filter"),
 		Follow: c.Bool("follow"),
+		Format: logsFormat(c),
 		Prefix: true,
 		Since:  time.Now().Add(-1 * since),
 	}
 
-	logs, err := Rack(c).AppLogs(app, opts)
-	if err != nil {
+	r := Rack(c)
+
+	if err := logsSupported(r, opts); err != nil {
 		return err
 	}
 
-	if _, err := io.Copy(os.Stdout, logs); err != nil {
+	f := rack.NewLogFollower(r, opts, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		return r.AppLogs(app, opts)
+	})
+
+	return f.Copy(os.Stdout)
+}
+
+func logsFormat(c *cli.Context) string {
+	if c.Bool("json") {
+		return types.LogFormatJSON
+	}
+
+	return ""
+}
+
+// logsSupported returns an error if the rack is too old for the format or filter of opts
+func logsSupported(r rack.Rack, opts types.LogsOptions) error {
+	if opts.Format == types.LogFormatJSON && !rack.Supports(r, "logs.json") {
+		return fmt.Errorf("rack does not support json logs, update with: cx rack update")
+	}
+
+	f, err := types.ParseLogFilter(opts.Filter)
+	if err != nil {
 		return err
 	}
 
+	if len(f.Words()) < len(f) && !rack.Supports(r, "logs.filters") {
+		return fmt.Errorf("rack does not support field filters, update with: cx rack update")
+	}
+
 	return nil
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -157,8 +158,6 @@ func autoUpdate(ch chan error) {
 var errMissingProxyEndpoint = errors.New("Rack endpoint was not found, try cx login")
 
 func Rack(c *cli.Context) rack.Rack {
-	var endpoint *url.URL
-
 	exit := func(err error) {
 		if err != nil {
 			fmt.Fprint(os.Stderr, stdcli.Error(err))
@@ -166,39 +165,23 @@ func Rack(c *cli.Context) rack.Rack {
 		}
 	}
 
-	local, err := url.Parse("https://localhost:5443")
+	endpoint, err := rackEndpoint(c)
 	if err != nil {
 		exit(err)
 	}
 
-	if os.Getenv("RACK_URL") == "" {
-		proxy, err := consoleProxy()
+	system := false
+
+	// pin the rack certificate unless it is pinned or verified another way
+	if os.Getenv("RACK_FINGERPRINT") == "" && os.Getenv("RACK_CA") == "" {
+		fp, err := pinRack(endpoint.Host)
 		if err != nil {
 			exit(err)
 		}
 
-		if proxy != nil {
-			rack, err := rackFromContext(c)
-			if err != nil {
-				exit(err)
-			}
-
-			switch rack {
-			case "":
-				fmt.Println("No Rack selected, try cx racks. Using local rack")
-				fallthrough
-			case "local":
-				endpoint = local
-			default:
-				proxy.Path = fmt.Sprintf("racks/%s", rack)
-				endpoint = proxy
-			}
+		os.Setenv("RACK_FINGERPRINT", fp)
 
-		} else {
-			endpoint = local
-		}
-
-		os.Setenv("RACK_URL", endpoint.String())
+		system = fp == ""
 	}
 
 	r, err := rack.NewFromEnv()
@@ -206,9 +189,63 @@ func Rack(c *cli.Context) rack.Rack {
 		exit(err)
 	}
 
+	// a certificate that chains to the system roots is verified against them
+	if system {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			exit(err)
+		}
+
+		r.(*rack.Client).CA = pool
+	}
+
 	return r
 }
 
+// rackEndpoint returns the url of the current rack and sets RACK_URL to it
+func rackEndpoint(c *cli.Context) (*url.URL, error) {
+	if u := os.Getenv("RACK_URL"); u != "" {
+		return url.Parse(u)
+	}
+
+	endpoint, err := url.Parse("https://localhost:5443")
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := consoleProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy != nil {
+		rack, err := rackFromContext(c)
+		if err != nil {
+			return nil, err
+		}
+
+		switch rack {
+		case "":
+			fmt.Println("No Rack selected, try cx racks. Using local rack")
+		case "local":
+		default:
+			proxy.Path = fmt.Sprintf("racks/%s", rack)
+			endpoint = proxy
+		}
+	}
+
+	// the local rack requires its password, which is read from the running rack
+	if endpoint.Host == "localhost:5443" {
+		if password := localRackPassword(); password != "" {
+			endpoint.User = url.User(password)
+		}
+	}
+
+	os.Setenv("RACK_URL", endpoint.String())
+
+	return endpoint, nil
+}
+
 func cliID() (string, error) {
 	fn, err := homedir.Expand("~/.convox/id")
 	if err != nil {
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/convox/praxis/types"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// pins returns the fingerprints of the certificates rack endpoints presented the first time they were used
+func pins() (map[string]string, error) {
+	fn, err := homedir.Expand("~/.convox/fingerprints")
+	if err != nil {
+		return nil, err
+	}
+
+	pins := map[string]string{}
+
+	data, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, err
+	}
+
+	return pins, nil
+}
+
+func pinGet(host string) (string, error) {
+	ps, err := pins()
+	if err != nil {
+		return "", err
+	}
+
+	return ps[pinKey(host)], nil
+}
+
+func pinSet(host, fingerprint string) error {
+	ps, err := pins()
+	if err != nil {
+		return err
+	}
+
+	ps[pinKey(host)] = fingerprint
+
+	data, err := json.MarshalIndent(ps, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fn, err := homedir.Expand("~/.convox/fingerprints")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fn, data, 0600)
+}
+
+// pinFetch returns the fingerprint of the certificate a host presents and whether it chains to the system roots
+func pinFetch(host string) (string, bool, error) {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	cn, err := tls.DialWithDialer(dialer, "tcp", pinKey(host), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return "", false, err
+	}
+
+	defer cn.Close()
+
+	certs := cn.ConnectionState().PeerCertificates
+
+	if len(certs) == 0 {
+		return "", false, fmt.Errorf("no certificate presented by %s", host)
+	}
+
+	name, _, err := net.SplitHostPort(pinKey(host))
+	if err != nil {
+		return "", false, err
+	}
+
+	opts := x509.VerifyOptions{
+		DNSName:       name,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, c := range certs[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+
+	_, err = certs[0].Verify(opts)
+
+	return types.Fingerprint(certs[0].Raw), err == nil, nil
+}
+
+// pinRack returns the pinned fingerprint for a host, pinning the certificate it presents now if there is none
+// a host whose certificate chains to the system roots is not pinned and an empty fingerprint is returned,
+// it rotates its certificate and should be verified against the system roots instead
+func pinRack(host string) (string, error) {
+	fp, err := pinGet(host)
+	if err != nil {
+		return "", err
+	}
+
+	if fp != "" {
+		return fp, nil
+	}
+
+	fp, trusted, err := pinFetch(host)
+	if err != nil {
+		return "", fmt.Errorf("unable to verify rack certificate for %s: %s", host, err)
+	}
+
+	if trusted {
+		return "", nil
+	}
+
+	if err := pinSet(host, fp); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(os.Stderr, "pinned certificate for %s: %s\n", host, fp)
+
+	return fp, nil
+}
+
+func pinKey(host string) string {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return net.JoinHostPort(host, "443")
+	}
+
+	return host
+}
@@ -2,18 +2,21 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
 
 	"github.com/convox/praxis/provider"
+	"github.com/convox/praxis/sdk/rack"
 	"github.com/convox/praxis/stdcli"
 	"github.com/convox/praxis/types"
 	cli "gopkg.in/urfave/cli.v1"
@@ -56,13 +59,17 @@ func init() {
 					rackFlag,
 					cli.StringFlag{
 						Name:  "filter",
-						Usage: "filter logs",
+						Usage: "filter logs, such as: service=web status>=500",
 						Value: "",
 					},
 					cli.BoolFlag{
 						Name:  "follow, f",
 						Usage: "stream logs continuously",
 					},
+					cli.BoolFlag{
+						Name:  "json",
+						Usage: "output one json object per line",
+					},
 					cli.StringFlag{
 						Name:  "since",
 						Usage: "how far back to retrieve logs",
@@ -70,6 +77,29 @@ func init() {
 					},
 				},
 			},
+			cli.Command{
+				Name:        "resources",
+				Description: "list rack resources",
+				Action:      runRackResources,
+				Flags:       []cli.Flag{rackFlag},
+				Subcommands: cli.Commands{
+					cli.Command{
+						Name:        "create",
+						Description: "create a rack resource",
+						Usage:       "<type> <name>",
+						Action:      runRackResourcesCreate,
+						Flags:       []cli.Flag{rackFlag},
+					},
+					cli.Command{
+						Name:        "delete",
+						Aliases:     []string{"rm"},
+						Description: "delete a rack resource",
+						Usage:       "<name>",
+						Action:      runRackResourcesDelete,
+						Flags:       []cli.Flag{rackFlag},
+					},
+				},
+			},
 			cli.Command{
 				Name:        "start",
 				Description: "start a local rack",
@@ -82,6 +112,12 @@ func init() {
 					},
 				},
 			},
+			cli.Command{
+				Name:        "trust",
+				Description: "pin the certificate the rack presents now, use after the rack certificate has changed",
+				Action:      runRackTrust,
+				Flags:       []cli.Flag{rackFlag},
+			},
 			cli.Command{
 				Name:        "uninstall",
 				Description: "uninstall a rack",
@@ -125,6 +161,10 @@ func runRack(c *cli.Context) error {
 	info.Add("Status", rack.Status)
 	info.Add("Version", rack.Version)
 
+	if rack.Fingerprint != "" {
+		info.Add("Fingerprint", rack.Fingerprint)
+	}
+
 	info.Print()
 
 	return nil
@@ -203,19 +243,71 @@ func runRackLogs(c *cli.Context) error {
 	opts := types.LogsOptions{
 		Filter: c.String("filter"),
 		Follow: c.Bool("follow"),
+		Format: logsFormat(c),
 		Prefix: true,
 		Since:  time.Now().Add(-1 * since),
 	}
 
-	logs, err := Rack(c).SystemLogs(opts)
-	if err != nil {
+	if err := logsSupported(Rack(c), opts); err != nil {
 		return err
 	}
 
-	if _, err := io.Copy(os.Stdout, logs); err != nil {
-		return err
+	f := rack.NewLogFollower(Rack(c), opts, Rack(c).SystemLogs)
+
+	return f.Copy(os.Stdout)
+}
+
+func runRackResources(c *cli.Context) error {
+	rs, err := Rack(c).SystemResourceList()
+	if err != nil {
+		return stdcli.Error(err)
 	}
 
+	t := stdcli.NewTable("NAME", "TYPE", "APPS", "ENDPOINT")
+
+	for _, r := range rs {
+		t.AddRow(r.Name, r.Type, strings.Join(r.Apps, ","), r.Endpoint)
+	}
+
+	t.Print()
+
+	return nil
+}
+
+func runRackResourcesCreate(c *cli.Context) error {
+	if len(c.Args()) != 2 {
+		return stdcli.Usage(c)
+	}
+
+	kind := c.Args()[0]
+	name := c.Args()[1]
+
+	stdcli.Startf("creating <name>%s</name>", name)
+
+	if _, err := Rack(c).SystemResourceCreate(name, kind); err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	return nil
+}
+
+func runRackResourcesDelete(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	name := c.Args()[0]
+
+	stdcli.Startf("deleting <name>%s</name>", name)
+
+	if err := Rack(c).SystemResourceDelete(name); err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
 	return nil
 }
 
@@ -255,6 +347,30 @@ func runRackStart(c *cli.Context) error {
 	return cmd.Run()
 }
 
+func runRackTrust(c *cli.Context) error {
+	u, err := rackEndpoint(c)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.Startf("pinning certificate for <url>%s</url>", u.Host)
+
+	fp, _, err := pinFetch(u.Host)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	if err := pinSet(u.Host, fp); err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	fmt.Println(fp)
+
+	return nil
+}
+
 func runRackUninstall(c *cli.Context) error {
 	if len(c.Args()) != 1 {
 		return stdcli.Usage(c)
@@ -322,11 +438,11 @@ func runRackUpdate(c *cli.Context) error {
 func rackCommand(version string, router string) (*exec.Cmd, error) {
 	name := "convox"
 
-	config := "/var/convox"
+	config := localConfig()
 
-	switch runtime.GOOS {
-	case "darwin":
-		config = "/Users/Shared/convox"
+	password, err := localPassword()
+	if err != nil {
+		return nil, err
 	}
 
 	exec.Command("docker", "rm", "-f", name).Run()
@@ -334,6 +450,7 @@ func rackCommand(version string, router string) (*exec.Cmd, error) {
 	args := []string{"run", "--rm"}
 	args = append(args, "-m", "256m")
 	args = append(args, "-i", fmt.Sprintf("--name=%s", name))
+	args = append(args, "-e", "PASSWORD")
 	args = append(args, "-e", "PROVIDER=local")
 	args = append(args, "-e", fmt.Sprintf("PROVIDER_ROUTER=%s", router))
 	args = append(args, "-e", fmt.Sprintf("VERSION=%s", version))
@@ -342,7 +459,70 @@ func rackCommand(version string, router string) (*exec.Cmd, error) {
 	args = append(args, "-v", "/var/run/docker.sock:/var/run/docker.sock")
 	args = append(args, fmt.Sprintf("convox/praxis:%s", version))
 
-	return exec.Command("docker", args...), nil
+	cmd := exec.Command("docker", args...)
+
+	// passed through the environment so that it does not show in the process list
+	cmd.Env = append(os.Environ(), fmt.Sprintf("PASSWORD=%s", password))
+
+	return cmd, nil
+}
+
+// localConfig returns the directory a local rack keeps its data in
+func localConfig() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Users/Shared/convox"
+	}
+
+	return "/var/convox"
+}
+
+// localPassword returns the password of the local rack, generating it the first time
+// without one any container could reach the rack api with full access by leaving out its app token
+func localPassword() (string, error) {
+	fn := filepath.Join(localConfig(), "password")
+
+	data, err := ioutil.ReadFile(fn)
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	key := make([]byte, 32)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	password := hex.EncodeToString(key)
+
+	if err := os.MkdirAll(localConfig(), 0755); err != nil {
+		return "", err
+	}
+
+	if err := ioutil.WriteFile(fn, []byte(password), 0600); err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// localRackPassword returns the password of the running local rack, reading it needs the docker access that running it does
+func localRackPassword() string {
+	data, err := exec.Command("docker", "inspect", "--format", "{{range .Config.Env}}{{println .}}{{end}}", "convox").Output()
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "PASSWORD=") {
+			return strings.TrimPrefix(line, "PASSWORD=")
+		}
+	}
+
+	return ""
 }
 
 func aws(args ...string) ([]byte, error) {
@@ -2,7 +2,6 @@ package main
 
 import (
 	"io"
-	"io/ioutil"
 	"os"
 	"time"
 
@@ -144,31 +143,17 @@ func notReleaseStatus(r rack.Rack, app, id, status string) func() (bool, error)
 	}
 }
 
-type progress int64
-
-func (p *progress) Write(data []byte) (int, error) {
-	*p += progress(len(data))
-	return len(data), nil
-}
-
 func releaseLogs(r rack.Rack, app string, id string, w io.Writer, opts types.LogsOptions) error {
 	if err := tickWithTimeout(2*time.Second, 5*time.Minute, notReleaseStatus(r, app, id, "created")); err != nil {
 		return err
 	}
 
-	var p progress
+	f := rack.NewLogFollower(r, opts, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		return r.ReleaseLogs(app, id, opts)
+	})
 
 	for {
-		logs, err := r.ReleaseLogs(app, id, opts)
-		if err != nil {
-			return err
-		}
-
-		if _, err := io.CopyN(ioutil.Discard, logs, int64(p)); err != nil {
-			return err
-		}
-
-		if _, err := io.Copy(io.MultiWriter(w, &p), logs); err != nil {
+		if err := f.Copy(w); err != nil {
 			return err
 		}
 
@@ -1,19 +1,95 @@
 package main
 
 import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
 	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/convox/praxis/router"
 	"github.com/convox/praxis/stdcli"
 	"gopkg.in/urfave/cli.v1"
 )
 
+var routerFlag = cli.StringFlag{
+	Name:  "router",
+	Usage: "router address",
+	Value: "10.42.0.0",
+}
+
 func init() {
 	stdcli.RegisterCommand(cli.Command{
 		Name:        "router",
 		Description: "start a local router",
 		Action:      runRouter,
+		Subcommands: cli.Commands{
+			cli.Command{
+				Name:        "logs",
+				Description: "show router access logs",
+				Action:      runRouterLogs,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "follow, f",
+						Usage: "stream logs continuously",
+					},
+					cli.BoolFlag{
+						Name:  "json",
+						Usage: "output logs as json",
+					},
+					routerFlag,
+				},
+			},
+			cli.Command{
+				Name:        "ca",
+				Description: "show the router certificate authority",
+				Action:      runRouterCA,
+				Flags:       []cli.Flag{routerFlag},
+				Subcommands: cli.Commands{
+					cli.Command{
+						Name:        "export",
+						Description: "export the certificate authority as pem",
+						Action:      runRouterCAExport,
+						Flags:       []cli.Flag{routerFlag},
+					},
+					cli.Command{
+						Name:        "fingerprint",
+						Description: "show the sha256 fingerprint of the certificate authority",
+						Action:      runRouterCAFingerprint,
+						Flags:       []cli.Flag{routerFlag},
+					},
+					cli.Command{
+						Name:        "install",
+						Description: "trust the certificate authority on this system",
+						Action:      runRouterCAInstall,
+						Flags: []cli.Flag{
+							routerFlag,
+							cli.StringFlag{
+								Name:  "fingerprint",
+								Usage: "sha256 fingerprint of the certificate authority as shown on the router host",
+							},
+						},
+					},
+					cli.Command{
+						Name:        "rotate",
+						Description: "replace the certificate authority",
+						Action:      runRouterCARotate,
+						Flags:       []cli.Flag{routerFlag},
+					},
+				},
+			},
+		},
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:  "domain, d",
@@ -25,6 +101,20 @@ func init() {
 				Usage: "interface name",
 				Value: "vlan2",
 			},
+			cli.StringFlag{
+				Name:  "pages",
+				Usage: "directory of custom error pages",
+				Value: "/etc/convox/pages",
+			},
+			cli.StringSliceFlag{
+				Name:  "resolver",
+				Usage: "upstream dns resolver (defaults to /etc/resolv.conf)",
+			},
+			cli.StringFlag{
+				Name:  "storage",
+				Usage: "file to persist endpoints",
+				Value: "/etc/convox/router.json",
+			},
 			cli.StringFlag{
 				Name:  "subnet, s",
 				Usage: "subnet",
@@ -44,14 +134,264 @@ func runRouter(c *cli.Context) error {
 		return fmt.Errorf("must run as root")
 	}
 
+	// the router reaches the local rack with its password and pins the certificate it writes
+	if os.Getenv("RACK_URL") == "" {
+		password, err := localPassword()
+		if err != nil {
+			return err
+		}
+
+		os.Setenv("RACK_URL", fmt.Sprintf("https://%s@localhost:5443", password))
+
+		router.RackCertificate = filepath.Join(localConfig(), "rack.crt")
+	}
+
 	r, err := router.New(Version, c.String("domain"), c.String("interface"), c.String("subnet"))
 	if err != nil {
 		return err
 	}
 
+	r.Pages = &router.Pages{Dir: c.String("pages")}
+	r.Resolvers = c.StringSlice("resolver")
+	r.Storage = c.String("storage")
+
 	if err := r.Serve(); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func runRouterCA(c *cli.Context) error {
+	ca, err := routerCA(c, "GET", "/ca")
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	info := stdcli.NewInfo()
+
+	info.Add("Fingerprint", ca.Fingerprint)
+	info.Add("Expires", ca.Expires.Format(time.RFC3339))
+
+	info.Print()
+
+	return nil
+}
+
+func runRouterCAExport(c *cli.Context) error {
+	ca, err := routerCA(c, "GET", "/ca")
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	fmt.Print(ca.Certificate)
+
+	return nil
+}
+
+func runRouterCAFingerprint(c *cli.Context) error {
+	ca, err := routerCA(c, "GET", "/ca")
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	fmt.Println(ca.Fingerprint)
+
+	return nil
+}
+
+func runRouterCAInstall(c *cli.Context) error {
+	if runtime.GOOS != "linux" {
+		return stdcli.Errorf("ca install is not supported on %s", runtime.GOOS)
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	if u.Uid != "0" {
+		return stdcli.Errorf("must run as root")
+	}
+
+	ca, err := routerCA(c, "GET", "/ca")
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	// the router api is reached without verification so the certificate is only trusted once the user confirms its fingerprint
+	pub, fingerprint, err := verifyCA(ca.Certificate, c.String("fingerprint"))
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.Startf("Installing certificate authority <id>%s</id>", fingerprint)
+
+	switch {
+	case commandExists("update-ca-certificates"):
+		if err := ioutil.WriteFile("/usr/local/share/ca-certificates/convox.crt", pub, 0644); err != nil {
+			return stdcli.Error(err)
+		}
+
+		if err := exec.Command("update-ca-certificates").Run(); err != nil {
+			return stdcli.Error(err)
+		}
+	case commandExists("trust"):
+		fd, err := ioutil.TempFile("", "convox-ca")
+		if err != nil {
+			return stdcli.Error(err)
+		}
+
+		defer os.Remove(fd.Name())
+
+		if _, err := fd.Write(pub); err != nil {
+			return stdcli.Error(err)
+		}
+
+		fd.Close()
+
+		if err := exec.Command("trust", "anchor", "--store", fd.Name()).Run(); err != nil {
+			return stdcli.Error(err)
+		}
+	default:
+		return stdcli.Errorf("could not find update-ca-certificates or trust")
+	}
+
+	stdcli.OK()
+
+	return nil
+}
+
+func runRouterCARotate(c *cli.Context) error {
+	u, err := user.Current()
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	// the token that authorizes a rotation is only readable by root on the router host
+	if u.Uid != "0" {
+		return stdcli.Errorf("must run as root on the router host")
+	}
+
+	stdcli.Startf("Rotating certificate authority")
+
+	ca, err := routerCA(c, "POST", "/ca/rotate")
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	fmt.Printf("Fingerprint: %s\n", ca.Fingerprint)
+	fmt.Printf("Run `cx router ca install --fingerprint %s` to trust the new certificate authority\n", ca.Fingerprint)
+
+	return nil
+}
+
+func runRouterLogs(c *cli.Context) error {
+	uv := url.Values{}
+
+	if c.Bool("follow") {
+		uv.Set("follow", "true")
+	}
+
+	if c.Bool("json") {
+		uv.Set("format", "json")
+	}
+
+	res, err := routerRequest(c, "GET", fmt.Sprintf("/logs?%s", uv.Encode()), nil)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	defer res.Body.Close()
+
+	if _, err := io.Copy(os.Stdout, res.Body); err != nil {
+		return stdcli.Error(err)
+	}
+
+	return nil
+}
+
+// verifyCA checks that a pem certificate is a single certificate with the expected fingerprint
+// it returns the certificate to install and its fingerprint
+func verifyCA(certificate, expected string) ([]byte, string, error) {
+	block, rest := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" || len(bytes.TrimSpace(rest)) > 0 {
+		return nil, "", fmt.Errorf("invalid certificate authority")
+	}
+
+	fingerprint := router.Fingerprint(block.Bytes)
+
+	if expected == "" {
+		return nil, "", fmt.Errorf("check that the ca fingerprint in the router log or of /etc/convox/ca.crt on the router host is %s then run: cx router ca install --fingerprint %s", fingerprint, fingerprint)
+	}
+
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.Replace(strings.TrimSpace(s), ":", "", -1))
+	}
+
+	if normalize(expected) != normalize(fingerprint) {
+		return nil, "", fmt.Errorf("certificate authority fingerprint %s does not match %s", fingerprint, expected)
+	}
+
+	return pem.EncodeToMemory(block), fingerprint, nil
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func routerCA(c *cli.Context, method, path string) (*router.CA, error) {
+	res, err := routerRequest(c, method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	var ca router.CA
+
+	if err := json.NewDecoder(res.Body).Decode(&ca); err != nil {
+		return nil, err
+	}
+
+	return &ca, nil
+}
+
+// routerRequest makes a request to the router api
+// the router presents a self-signed certificate so verification is skipped
+func routerRequest(c *cli.Context, method, path string, params url.Values) (*http.Response, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("https://%s%s", c.String("router"), path), strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	// changes to the certificate authority need the router token, which root can read on the router host
+	if data, err := ioutil.ReadFile(router.TokenPath()); err == nil {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", strings.TrimSpace(string(data))))
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("router responded with %s", res.Status)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func init() {
+	stdcli.RegisterCommand(cli.Command{
+		Name:        "tokens",
+		Description: "list api tokens",
+		Action:      runTokens,
+		Flags:       []cli.Flag{rackFlag},
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name:        "create",
+				Description: "create an api token",
+				Action:      runTokensCreate,
+				Usage:       "<name>",
+				Flags: []cli.Flag{
+					rackFlag,
+					cli.StringSliceFlag{
+						Name:  "scope, s",
+						Usage: "admin, deploy, read, or app:<name> (repeatable)",
+					},
+				},
+			},
+			cli.Command{
+				Name:        "revoke",
+				Description: "revoke an api token",
+				Action:      runTokensRevoke,
+				Usage:       "<id>",
+				Flags:       []cli.Flag{rackFlag},
+			},
+		},
+	})
+}
+
+func runTokens(c *cli.Context) error {
+	tokens, err := Rack(c).TokenList()
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	t := stdcli.NewTable("ID", "NAME", "SCOPES", "CREATED")
+
+	for _, tk := range tokens {
+		t.AddRow(tk.Id, tk.Name, strings.Join(tk.Scopes, ","), helpers.HumanizeTime(tk.Created))
+	}
+
+	t.Print()
+
+	return nil
+}
+
+func runTokensCreate(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	name := c.Args()[0]
+
+	stdcli.Startf("creating <name>%s</name>", name)
+
+	token, err := Rack(c).TokenCreate(name, types.TokenCreateOptions{Scopes: c.StringSlice("scope")})
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	fmt.Println(token.Value())
+
+	return nil
+}
+
+func runTokensRevoke(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	id := c.Args()[0]
+
+	stdcli.Startf("revoking <id>%s</id>", id)
+
+	if err := Rack(c).TokenDelete(id); err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	return nil
+}
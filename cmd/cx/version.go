@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
@@ -22,18 +23,36 @@ func init() {
 }
 
 func runVersion(c *cli.Context) error {
-	fmt.Printf("client: %s\n", Version)
+	fmt.Printf("client: %s (api %d)\n", Version, types.ApiVersion)
 
 	rack, err := Rack(c).SystemGet()
 	if err != nil {
-		if os.Getenv("RACK_URL") == "https://localhost:5443" && strings.Contains(err.Error(), "connection refused") {
+		if strings.HasSuffix(os.Getenv("RACK_URL"), "localhost:5443") && strings.Contains(err.Error(), "connection refused") {
 			fmt.Printf("server: error\n")
 			return fmt.Errorf("Could not connect to local Rack. Is it installed and Docker running?")
 		}
 		return err
 	}
 
-	fmt.Printf("server: %s\n", rack.Version)
+	options, err := Rack(c).SystemOptions()
+	if err != nil {
+		return err
+	}
+
+	api, err := types.ParseApiVersion(options["api"])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("server: %s (api %d)\n", rack.Version, api)
+
+	if features := options["features"]; features != "" {
+		fmt.Printf("features: %s\n", strings.Replace(features, ",", " ", -1))
+	}
+
+	if api < types.ApiVersion {
+		fmt.Printf("warning: rack api is older than this client, some commands may be unavailable, update with: cx rack update\n")
+	}
 
 	return nil
 }
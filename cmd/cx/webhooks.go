@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func init() {
+	stdcli.RegisterCommand(cli.Command{
+		Name:        "webhooks",
+		Description: "list webhooks",
+		Action:      runWebhooks,
+		Flags:       []cli.Flag{rackFlag},
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name:        "add",
+				Description: "send rack events to a url",
+				Action:      runWebhooksAdd,
+				Usage:       "<url>",
+				Flags: []cli.Flag{
+					rackFlag,
+					cli.StringSliceFlag{
+						Name:  "action",
+						Usage: "only send these actions such as release:promote or build:* (repeatable)",
+					},
+					cli.StringFlag{
+						Name:  "app, a",
+						Usage: "only send events for an app",
+					},
+				},
+			},
+			cli.Command{
+				Name:        "remove",
+				Aliases:     []string{"rm"},
+				Description: "remove a webhook",
+				Action:      runWebhooksRemove,
+				Usage:       "<id>",
+				Flags:       []cli.Flag{rackFlag},
+			},
+		},
+	})
+}
+
+func runWebhooks(c *cli.Context) error {
+	webhooks, err := Rack(c).WebhookList()
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	t := stdcli.NewTable("ID", "URL", "APP", "ACTIONS", "CREATED")
+
+	for _, w := range webhooks {
+		t.AddRow(w.Id, w.Url, w.App, strings.Join(w.Actions, ","), helpers.HumanizeTime(w.Created))
+	}
+
+	t.Print()
+
+	return nil
+}
+
+func runWebhooksAdd(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	url := c.Args()[0]
+
+	stdcli.Startf("adding <url>%s</url>", url)
+
+	opts := types.WebhookCreateOptions{
+		Actions: c.StringSlice("action"),
+		App:     c.String("app"),
+	}
+
+	webhook, err := Rack(c).WebhookCreate(url, opts)
+	if err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	fmt.Printf("signing secret: %s\n", webhook.Secret)
+
+	return nil
+}
+
+func runWebhooksRemove(c *cli.Context) error {
+	if len(c.Args()) != 1 {
+		return stdcli.Usage(c)
+	}
+
+	id := c.Args()[0]
+
+	stdcli.Startf("removing <id>%s</id>", id)
+
+	if err := Rack(c).WebhookDelete(id); err != nil {
+		return stdcli.Error(err)
+	}
+
+	stdcli.OK()
+
+	return nil
+}
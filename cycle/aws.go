@@ -0,0 +1,63 @@
+package cycle
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// AWSRegion is the region aws clients are configured for when replaying
+const AWSRegion = "us-east-1"
+
+// AWSConfig returns an aws config whose clients send every request to the replay server
+func (s *HTTP) AWSConfig() *aws.Config {
+	if s.Server == nil {
+		s.Listen()
+	}
+
+	return &aws.Config{
+		Credentials: credentials.NewStaticCredentials("test", "test", ""),
+		Endpoint:    aws.String(s.Server.URL),
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+		MaxRetries:       aws.Int(0),
+		Region:           aws.String(AWSRegion),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+}
+
+// AWSConfig returns an aws config whose clients talk to the real aws endpoints of region and record each exchange
+// credentials come from the environment as usual
+func (r *Recorder) AWSConfig(region string) *aws.Config {
+	return &aws.Config{
+		HTTPClient:       &http.Client{Transport: r},
+		MaxRetries:       aws.Int(0),
+		Region:           aws.String(region),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+}
+
+// MatchAWS compares bodies the way each aws protocol encodes them, ignoring fields that change on every run
+// json apis are compared as json, query apis as forms, s3 requests as xml and anything else such as objects byte for byte
+func MatchAWS(fields ...string) BodyMatcher {
+	return func(expected, got []byte) error {
+		if json.Valid(expected) {
+			return IgnoreFields(MatchJSON, fields...)(expected, got)
+		}
+
+		if v, err := url.ParseQuery(string(expected)); err == nil && v.Get("Action") != "" {
+			return IgnoreFields(MatchForm, fields...)(expected, got)
+		}
+
+		if _, err := canonicalXML(expected); err == nil {
+			return MatchXML(expected, got)
+		}
+
+		return MatchExact(expected, got)
+	}
+}
@@ -0,0 +1,125 @@
+package cycle_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/praxis/cycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchJSON(t *testing.T) {
+	assert.NoError(t, cycle.MatchJSON([]byte(`{"a":1,"b":[1,2]}`), []byte(`{ "b": [1, 2], "a": 1 }`)))
+	assert.Error(t, cycle.MatchJSON([]byte(`{"a":1}`), []byte(`{"a":2}`)))
+	assert.Error(t, cycle.MatchJSON([]byte(`{"a":1}`), []byte(`a=1`)))
+}
+
+func TestMatchForm(t *testing.T) {
+	assert.NoError(t, cycle.MatchForm([]byte("a=1&b=2"), []byte("b=2&a=1")))
+	assert.EqualError(t, cycle.MatchForm([]byte("a=1&b=2"), []byte("a=1&b=3")), `expected:"a=1&b=2" got:"a=1&b=3"`)
+}
+
+func TestIgnoreFields(t *testing.T) {
+	m := cycle.IgnoreFields(cycle.MatchForm, "Timestamp")
+	assert.NoError(t, m([]byte("Action=Get&Timestamp=1"), []byte("Timestamp=2&Action=Get")))
+	assert.Error(t, m([]byte("Action=Get&Timestamp=1"), []byte("Action=Put&Timestamp=1")))
+
+	m = cycle.IgnoreFields(cycle.MatchJSON, "id")
+	assert.NoError(t, m([]byte(`{"id":"1","name":"a"}`), []byte(`{"name":"a","id":"2"}`)))
+}
+
+func TestMatchXML(t *testing.T) {
+	assert.NoError(t, cycle.MatchXML([]byte("<a><b>1</b><c>2</c></a>"), []byte("<a>\n  <c>2</c>\n  <b>1</b>\n</a>")))
+	assert.Error(t, cycle.MatchXML([]byte("<a><b>1</b></a>"), []byte("<a><b>2</b></a>")))
+	assert.Error(t, cycle.MatchXML([]byte("<a><b>1</b></a>"), []byte("a=1")))
+}
+
+func TestMatchAWS(t *testing.T) {
+	m := cycle.MatchAWS("TemplateBody")
+
+	assert.NoError(t, m([]byte("Action=CreateStack&StackName=a&TemplateBody=1"), []byte("TemplateBody=2&StackName=a&Action=CreateStack")))
+	assert.Error(t, m([]byte("Action=CreateStack&StackName=a"), []byte("Action=CreateStack&StackName=b")))
+	assert.NoError(t, m([]byte(`{"cluster":"a","tasks":["b"]}`), []byte(`{"tasks":["b"],"cluster":"a"}`)))
+	assert.Error(t, m([]byte(`{"cluster":"a"}`), []byte(`{"cluster":"b"}`)))
+	assert.NoError(t, m([]byte("<Complete><Part>1</Part><ETag>a</ETag></Complete>"), []byte("<Complete><ETag>a</ETag><Part>1</Part></Complete>")))
+	assert.NoError(t, m([]byte("object%data"), []byte("object%data")))
+	assert.Error(t, m([]byte("object%data"), []byte("object%other")))
+}
+
+func TestReplayMismatch(t *testing.T) {
+	c, err := cycle.NewHTTP()
+	require.NoError(t, err)
+
+	c.Add(cycle.HTTPRequest{Method: "POST", Path: "/", Body: []byte("a=1")}, cycle.HTTPResponse{Code: 200, Body: []byte("ok")})
+
+	w := httptest.NewRecorder()
+	c.Cycle(w, httptest.NewRequest("POST", "/", bytes.NewReader([]byte("a=2"))))
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "bad cycle body: expected:\"a=1\" got:\"a=2\"\n", w.Body.String())
+}
+
+func TestRecordReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(201)
+		w.Write(append([]byte("echo:"), data...))
+	}))
+	defer upstream.Close()
+
+	r, err := cycle.NewRecorder()
+	require.NoError(t, err)
+
+	res, err := (&http.Client{Transport: r}).Post(upstream.URL+"/things", "application/json", bytes.NewReader([]byte(`{"name":"a","id":"1"}`)))
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "echo:{\"name\":\"a\",\"id\":\"1\"}", string(data))
+
+	tmp, err := ioutil.TempDir("", "cycle")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	file := filepath.Join(tmp, "fixtures", "things.json")
+
+	require.NoError(t, r.Save(file))
+
+	c, err := cycle.LoadHTTP(file)
+	require.NoError(t, err)
+	require.Len(t, c.Cycles, 1)
+
+	c.Matcher = cycle.IgnoreFields(cycle.MatchJSON, "id")
+
+	w := httptest.NewRecorder()
+	c.Cycle(w, httptest.NewRequest("POST", "/things", bytes.NewReader([]byte(`{"id":"2","name":"a"}`))))
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "echo:{\"name\":\"a\",\"id\":\"1\"}", w.Body.String())
+}
+
+func TestFixtureBinaryBody(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "cycle")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	file := filepath.Join(tmp, "binary.json")
+
+	body := []byte{0xff, 0x00, 0xfe}
+
+	require.NoError(t, cycle.SaveCycles(file, []cycle.HTTPCycle{
+		{Request: cycle.HTTPRequest{Method: "GET", Path: "/"}, Response: cycle.HTTPResponse{Code: 200, Body: body}},
+	}))
+
+	c, err := cycle.LoadHTTP(file)
+	require.NoError(t, err)
+	require.Len(t, c.Cycles, 1)
+	assert.Equal(t, body, c.Cycles[0].Response.Body)
+}
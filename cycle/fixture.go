@@ -0,0 +1,117 @@
+package cycle
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// fixture is the on-disk form of a cycle, bodies are kept as text so recordings can be read and edited
+// bodies that are not valid utf-8 are stored base64 encoded
+type fixture struct {
+	Request struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Body   string `json:"body,omitempty"`
+		Base64 bool   `json:"base64,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Code    int         `json:"code"`
+		Headers http.Header `json:"headers,omitempty"`
+		Body    string      `json:"body,omitempty"`
+		Base64  bool        `json:"base64,omitempty"`
+	} `json:"response"`
+}
+
+// LoadHTTP returns a replay server for the cycles in a fixture file
+func LoadHTTP(file string) (*HTTP, error) {
+	s, err := NewHTTP()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Load(file); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Load appends the cycles in a fixture file
+func (s *HTTP) Load(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var fixtures []fixture
+
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return err
+	}
+
+	for _, f := range fixtures {
+		req := HTTPRequest{Method: f.Request.Method, Path: f.Request.Path}
+		res := HTTPResponse{Code: f.Response.Code, Headers: f.Response.Headers}
+
+		// a fixture without a request body matches any body
+		if f.Request.Body != "" {
+			if req.Body, err = decodeBody(f.Request.Body, f.Request.Base64); err != nil {
+				return err
+			}
+		}
+
+		if res.Body, err = decodeBody(f.Response.Body, f.Response.Base64); err != nil {
+			return err
+		}
+
+		s.Add(req, res)
+	}
+
+	return nil
+}
+
+// SaveCycles writes cycles to a fixture file that LoadHTTP can replay
+func SaveCycles(file string, cycles []HTTPCycle) error {
+	fixtures := make([]fixture, len(cycles))
+
+	for i, c := range cycles {
+		fixtures[i].Request.Method = c.Request.Method
+		fixtures[i].Request.Path = c.Request.Path
+		fixtures[i].Request.Body, fixtures[i].Request.Base64 = encodeBody(c.Request.Body)
+		fixtures[i].Response.Code = c.Response.Code
+		fixtures[i].Response.Headers = c.Response.Headers
+		fixtures[i].Response.Body, fixtures[i].Response.Base64 = encodeBody(c.Response.Body)
+	}
+
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append(data, '\n'), 0644)
+}
+
+func decodeBody(body string, encoded bool) ([]byte, error) {
+	if encoded {
+		return base64.StdEncoding.DecodeString(body)
+	}
+
+	return []byte(body), nil
+}
+
+func encodeBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+
+	return base64.StdEncoding.EncodeToString(body), true
+}
@@ -1,7 +1,6 @@
 package cycle
 
 import (
-	"bytes"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
@@ -10,10 +9,15 @@ import (
 	"sync"
 )
 
+// HTTP is a server that replays a scripted sequence of request/response cycles
+// requests must arrive in order and match the method, path and body of the next cycle
 type HTTP struct {
 	Cycles []HTTPCycle
 	Server *httptest.Server
 
+	// Matcher compares request bodies, defaults to MatchExact
+	Matcher BodyMatcher
+
 	index int
 	lock  sync.Mutex
 }
@@ -30,8 +34,9 @@ type HTTPRequest struct {
 }
 
 type HTTPResponse struct {
-	Code int
-	Body []byte
+	Code    int
+	Headers http.Header
+	Body    []byte
 }
 
 func NewHTTP() (*HTTP, error) {
@@ -58,8 +63,15 @@ func (s *HTTP) Cycle(w http.ResponseWriter, r *http.Request) {
 
 	s.Cycles = s.Cycles[1:]
 
-	if err := cycle.Request.Match(r); err != nil {
+	if err := cycle.Request.MatchWith(r, s.Matcher); err != nil {
 		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	for k, vs := range cycle.Response.Headers {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
 	}
 
 	if cycle.Response.Code > 0 {
@@ -81,7 +93,18 @@ func (s *HTTP) Listen() string {
 	return s.Server.URL
 }
 
+// Match compares a request to the cycle with an exact body match
 func (c *HTTPRequest) Match(r *http.Request) error {
+	return c.MatchWith(r, MatchExact)
+}
+
+// MatchWith compares a request to the cycle using m to compare bodies
+// a cycle with a nil body matches any body
+func (c *HTTPRequest) MatchWith(r *http.Request, m BodyMatcher) error {
+	if m == nil {
+		m = MatchExact
+	}
+
 	if err := compare(c.Method, r.Method, "method"); err != nil {
 		return err
 	}
@@ -95,8 +118,12 @@ func (c *HTTPRequest) Match(r *http.Request) error {
 		return err
 	}
 
-	if err := compareb(c.Body, data, "body"); err != nil {
-		return err
+	if c.Body == nil {
+		return nil
+	}
+
+	if err := m(c.Body, data); err != nil {
+		return fmt.Errorf("bad cycle body: %s", err)
 	}
 
 	return nil
@@ -112,14 +139,3 @@ func compare(expected, got string, name string) error {
 		return fmt.Errorf("bad cycle %s: expected:%s got:%s", name, expected, got)
 	}
 }
-
-func compareb(expected, got []byte, name string) error {
-	switch {
-	case expected == nil:
-		return nil
-	case bytes.Compare(expected, got) == 0:
-		return nil
-	default:
-		return fmt.Errorf("bad cycle %s: expected:%q got:%q", name, string(expected), string(got))
-	}
-}
@@ -0,0 +1,162 @@
+package cycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BodyMatcher returns an error if a request body does not match the expected one
+type BodyMatcher func(expected, got []byte) error
+
+// MatchExact requires the bodies to be byte for byte identical
+func MatchExact(expected, got []byte) error {
+	if !bytes.Equal(expected, got) {
+		return fmt.Errorf("expected:%q got:%q", string(expected), string(got))
+	}
+
+	return nil
+}
+
+// MatchJSON requires the bodies to decode to equivalent json, ignoring formatting and key order
+func MatchJSON(expected, got []byte) error {
+	var ev, gv interface{}
+
+	if err := json.Unmarshal(expected, &ev); err != nil {
+		return fmt.Errorf("expected body is not json: %s", err)
+	}
+
+	if err := json.Unmarshal(got, &gv); err != nil {
+		return fmt.Errorf("body is not json: %s", err)
+	}
+
+	if !reflect.DeepEqual(ev, gv) {
+		return fmt.Errorf("expected:%s got:%s", string(expected), string(got))
+	}
+
+	return nil
+}
+
+// MatchForm requires the bodies to decode to the same form values, ignoring parameter order
+func MatchForm(expected, got []byte) error {
+	ev, err := url.ParseQuery(string(expected))
+	if err != nil {
+		return fmt.Errorf("expected body is not a form: %s", err)
+	}
+
+	gv, err := url.ParseQuery(string(got))
+	if err != nil {
+		return fmt.Errorf("body is not a form: %s", err)
+	}
+
+	if !reflect.DeepEqual(ev, gv) {
+		return fmt.Errorf("expected:%q got:%q", ev.Encode(), gv.Encode())
+	}
+
+	return nil
+}
+
+// MatchXML requires the bodies to decode to the same xml elements, ignoring the order of sibling elements
+func MatchXML(expected, got []byte) error {
+	ex, err := canonicalXML(expected)
+	if err != nil {
+		return fmt.Errorf("expected body is not xml: %s", err)
+	}
+
+	gx, err := canonicalXML(got)
+	if err != nil {
+		return fmt.Errorf("body is not xml: %s", err)
+	}
+
+	if ex != gx {
+		return fmt.Errorf("expected:%q got:%q", string(expected), string(got))
+	}
+
+	return nil
+}
+
+// canonicalXML returns a form of an xml document where sibling elements are sorted
+func canonicalXML(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	// each open element collects the canonical forms of its children
+	stack := [][]string{{}}
+	text := []string{""}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, []string{})
+			text = append(text, "")
+		case xml.CharData:
+			text[len(text)-1] += strings.TrimSpace(string(t))
+		case xml.EndElement:
+			children := stack[len(stack)-1]
+			sort.Strings(children)
+
+			el := fmt.Sprintf("<%s>%s%s</%s>", t.Name.Local, text[len(text)-1], strings.Join(children, ""), t.Name.Local)
+
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+
+			stack[len(stack)-1] = append(stack[len(stack)-1], el)
+		}
+	}
+
+	if len(stack[0]) == 0 {
+		return "", fmt.Errorf("no elements")
+	}
+
+	sort.Strings(stack[0])
+
+	return strings.Join(stack[0], ""), nil
+}
+
+// IgnoreFields removes top-level json keys or form parameters from both bodies before comparing them with m
+// use it for values that change on every run such as timestamps and generated ids
+func IgnoreFields(m BodyMatcher, fields ...string) BodyMatcher {
+	return func(expected, got []byte) error {
+		return m(removeFields(expected, fields), removeFields(got, fields))
+	}
+}
+
+func removeFields(data []byte, fields []string) []byte {
+	var obj map[string]interface{}
+
+	if err := json.Unmarshal(data, &obj); err == nil {
+		for _, f := range fields {
+			delete(obj, f)
+		}
+
+		if d, err := json.Marshal(obj); err == nil {
+			return d
+		}
+
+		return data
+	}
+
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return data
+	}
+
+	for _, f := range fields {
+		form.Del(f)
+	}
+
+	return []byte(form.Encode())
+}
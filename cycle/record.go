@@ -0,0 +1,114 @@
+package cycle
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+)
+
+// headers that describe a single exchange and are not kept in recordings
+var volatileHeaders = []string{"Content-Length", "Date", "Transfer-Encoding"}
+
+// Recorder passes requests through to a real server and keeps each exchange as a cycle
+// use it as the transport of an http.Client, or Listen to run it as a proxy in front of a server
+type Recorder struct {
+	Cycles    []HTTPCycle
+	Server    *httptest.Server
+	Transport http.RoundTripper
+
+	lock sync.Mutex
+}
+
+func NewRecorder() (*Recorder, error) {
+	return &Recorder{Cycles: []HTTPCycle{}, Transport: http.DefaultTransport}, nil
+}
+
+// RoundTrip sends a request with the underlying transport and records the exchange
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+
+		body = data
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	}
+
+	res, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	headers := http.Header{}
+
+	for k, v := range res.Header {
+		headers[k] = v
+	}
+
+	for _, h := range volatileHeaders {
+		headers.Del(h)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.Cycles = append(r.Cycles, HTTPCycle{
+		Request:  HTTPRequest{Method: req.Method, Path: req.URL.Path, Body: body},
+		Response: HTTPResponse{Code: res.StatusCode, Headers: headers, Body: data},
+	})
+
+	return res, nil
+}
+
+// Listen starts a proxy to target that records everything passing through it and returns its url
+func (r *Recorder) Listen(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	proxy.Transport = r
+
+	director := proxy.Director
+
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = u.Host
+	}
+
+	r.Server = httptest.NewUnstartedServer(proxy)
+
+	r.Server.TLS = &tls.Config{
+		NextProtos: []string{"h2"},
+	}
+
+	r.Server.StartTLS()
+
+	return r.Server.URL, nil
+}
+
+// Save writes the recorded cycles to a fixture file
+func (r *Recorder) Save(file string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return SaveCycles(file, r.Cycles)
+}
@@ -0,0 +1,215 @@
+package drain
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+// Timeout bounds each connection and write to a sink
+var Timeout = 10 * time.Second
+
+// ErrClosed is returned by Forward once the forwarder is closed
+var ErrClosed = errors.New("drain closed")
+
+// Sink sends batches of log entries to an external system
+// a batch that fails may have been partly delivered, it is sent again whole so sinks see entries at least once
+type Sink interface {
+	Send(entries []types.LogEntry) error
+	Close() error
+}
+
+// New returns the sink for a drain url, config is used for syslog+tls and https and may be nil for the defaults
+func New(u string, config *tls.Config) (Sink, error) {
+	pu, err := types.ParseDrainUrl(u)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pu.Scheme {
+	case "file":
+		return &fileSink{path: pu.Path}, nil
+	case "http", "https":
+		return newHTTPSink(u, config), nil
+	case "syslog+tcp":
+		return &syslogSink{network: "tcp", addr: pu.Host}, nil
+	case "syslog+tls":
+		return &syslogSink{network: "tls", addr: pu.Host, config: config}, nil
+	default:
+		return &syslogSink{network: "udp", addr: pu.Host}, nil
+	}
+}
+
+type ForwarderOptions struct {
+	// Backoff is the wait after a failed send, it doubles with each failure in a row up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// BatchSize is the most entries sent at once, Interval is how long a partial batch waits for more
+	BatchSize int
+	Interval  time.Duration
+
+	// Buffer is how many entries are held while the sink is slow or down
+	Buffer int
+
+	// Errors is called with each failed send
+	Errors func(err error)
+}
+
+// Forwarder buffers log entries and sends them to a sink in batches
+// a failed batch is retried with backoff until it is accepted, meanwhile the buffer fills and Forward blocks
+// so that a slow or unreachable sink holds back its reader rather than growing without bound
+type Forwarder struct {
+	buffer chan types.LogEntry
+	done   chan struct{}
+	err    error
+	once   sync.Once
+	opts   ForwarderOptions
+	sink   Sink
+	stop   chan struct{}
+}
+
+func NewForwarder(sink Sink, opts ForwarderOptions) *Forwarder {
+	if opts.Backoff <= 0 {
+		opts.Backoff = 1 * time.Second
+	}
+
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = 1 * time.Minute
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = 1 * time.Second
+	}
+
+	if opts.Buffer <= 0 {
+		opts.Buffer = 1000
+	}
+
+	if opts.Errors == nil {
+		opts.Errors = func(err error) {}
+	}
+
+	f := &Forwarder{
+		buffer: make(chan types.LogEntry, opts.Buffer),
+		done:   make(chan struct{}),
+		opts:   opts,
+		sink:   sink,
+		stop:   make(chan struct{}),
+	}
+
+	go f.run()
+
+	return f
+}
+
+// Forward queues an entry, blocking while the buffer is full
+func (f *Forwarder) Forward(e types.LogEntry) error {
+	select {
+	case <-f.stop:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case f.buffer <- e:
+		return nil
+	case <-f.stop:
+		return ErrClosed
+	}
+}
+
+// Close stops the forwarder, the buffered entries get one last attempt before the sink is closed
+func (f *Forwarder) Close() error {
+	f.once.Do(func() {
+		close(f.stop)
+		<-f.done
+		f.err = f.sink.Close()
+	})
+
+	return f.err
+}
+
+func (f *Forwarder) run() {
+	defer close(f.done)
+
+	tick := time.NewTicker(f.opts.Interval)
+	defer tick.Stop()
+
+	batch := []types.LogEntry{}
+
+	for {
+		select {
+		case e := <-f.buffer:
+			if batch = append(batch, e); len(batch) < f.opts.BatchSize {
+				continue
+			}
+		case <-tick.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-f.stop:
+			f.flush(batch)
+			return
+		}
+
+		if !f.send(batch) {
+			f.flush(batch)
+			return
+		}
+
+		batch = []types.LogEntry{}
+	}
+}
+
+// send retries a batch until it is accepted, it returns false if the forwarder is closed first
+func (f *Forwarder) send(batch []types.LogEntry) bool {
+	backoff := f.opts.Backoff
+
+	for {
+		err := f.sink.Send(batch)
+		if err == nil {
+			return true
+		}
+
+		f.opts.Errors(err)
+
+		select {
+		case <-time.After(backoff):
+		case <-f.stop:
+			return false
+		}
+
+		if backoff *= 2; backoff > f.opts.MaxBackoff {
+			backoff = f.opts.MaxBackoff
+		}
+	}
+}
+
+// flush makes a single attempt to send batch and whatever is left in the buffer
+func (f *Forwarder) flush(batch []types.LogEntry) {
+	for len(f.buffer) > 0 {
+		batch = append(batch, <-f.buffer)
+	}
+
+	for len(batch) > 0 {
+		n := f.opts.BatchSize
+		if n > len(batch) {
+			n = len(batch)
+		}
+
+		if err := f.sink.Send(batch[0:n]); err != nil {
+			f.opts.Errors(err)
+			return
+		}
+
+		batch = batch[n:]
+	}
+}
@@ -0,0 +1,301 @@
+package drain_test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/drain"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntries = []types.LogEntry{
+	{Cursor: "1-0", Timestamp: time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC), App: "app", Service: "web", Process: "p1", Stream: "stdout", Message: "hello"},
+	{Cursor: "2-0", Timestamp: time.Date(2017, 6, 1, 12, 0, 1, 0, time.UTC), App: "app", Service: "web", Process: "p1", Stream: "stderr", Message: "oops"},
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, u := range []string{"", "ftp://example.org", "syslog+tls://example.org", "http://", "file://host/path", "file://"} {
+		_, err := drain.New(u, nil)
+		assert.EqualError(t, err, fmt.Sprintf("invalid drain url: %s", u))
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drain")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+
+	s, err := drain.New(fmt.Sprintf("file://%s", path), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(testEntries[0:1]))
+	require.NoError(t, s.Send(testEntries[1:2]))
+	require.NoError(t, s.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2017-06-01T12:00:00Z app/web/p1 hello\n2017-06-01T12:00:01Z app/web/p1 oops\n", string(data))
+}
+
+func TestHTTPSink(t *testing.T) {
+	var got []types.LogEntry
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer ts.Close()
+
+	s, err := drain.New(ts.URL, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(testEntries))
+
+	assert.Equal(t, testEntries, got)
+}
+
+func TestHTTPSinkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+
+	s, err := drain.New(ts.URL, nil)
+	require.NoError(t, err)
+
+	assert.EqualError(t, s.Send(testEntries), "drain response: 503")
+}
+
+func TestSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	messages := syslogServer(t, ln)
+
+	s, err := drain.New(fmt.Sprintf("syslog+tcp://%s", ln.Addr()), nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Send(testEntries))
+
+	assert.Equal(t, "<14>1 2017-06-01T12:00:00.000000Z app web p1 - - hello", <-messages)
+	assert.Equal(t, "<11>1 2017-06-01T12:00:01.000000Z app web p1 - - oops", <-messages)
+}
+
+func TestSyslogTLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	config := ts.TLS
+	ts.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	messages := syslogServer(t, ln)
+
+	s, err := drain.New(fmt.Sprintf("syslog+tls://%s", ln.Addr()), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Send(testEntries[0:1]))
+
+	assert.Equal(t, "<14>1 2017-06-01T12:00:00.000000Z app web p1 - - hello", <-messages)
+}
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := drain.New(fmt.Sprintf("syslog+udp://%s", conn.LocalAddr()), nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Send(testEntries))
+
+	buf := make([]byte, 1024)
+
+	for _, expected := range []string{"<14>1 2017-06-01T12:00:00.000000Z app web p1 - - hello", "<11>1 2017-06-01T12:00:01.000000Z app web p1 - - oops"} {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, string(buf[0:n]))
+	}
+}
+
+func TestForwarderBatches(t *testing.T) {
+	s := &testSink{}
+
+	f := drain.NewForwarder(s, drain.ForwarderOptions{BatchSize: 2, Interval: 10 * time.Millisecond})
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, f.Forward(types.LogEntry{Message: strconv.Itoa(i)}))
+	}
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, s.messages())
+	assert.True(t, s.closed)
+
+	assert.Equal(t, drain.ErrClosed, f.Forward(types.LogEntry{Message: "late"}))
+}
+
+func TestForwarderRetry(t *testing.T) {
+	s := &testSink{failures: 3}
+
+	errs := make(chan error, 10)
+
+	f := drain.NewForwarder(s, drain.ForwarderOptions{Backoff: 1 * time.Millisecond, Interval: 1 * time.Millisecond, Errors: func(err error) { errs <- err }})
+
+	require.NoError(t, f.Forward(types.LogEntry{Message: "retried"}))
+
+	for i := 0; i < 3; i++ {
+		assert.EqualError(t, <-errs, "sink down")
+	}
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"retried"}, s.messages())
+}
+
+func TestForwarderBackpressure(t *testing.T) {
+	s := &testSink{block: make(chan struct{})}
+
+	f := drain.NewForwarder(s, drain.ForwarderOptions{BatchSize: 1, Buffer: 1})
+
+	forwarded := make(chan int, 10)
+
+	go func() {
+		for i := 0; i < 4; i++ {
+			if err := f.Forward(types.LogEntry{Message: strconv.Itoa(i)}); err != nil {
+				return
+			}
+			forwarded <- i
+		}
+	}()
+
+	// one entry is being sent and one is buffered, the third waits for room
+	assert.Equal(t, 0, <-forwarded)
+	assert.Equal(t, 1, <-forwarded)
+
+	select {
+	case i := <-forwarded:
+		t.Fatalf("forward did not block: %d", i)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(s.block)
+
+	assert.Equal(t, 2, <-forwarded)
+	assert.Equal(t, 3, <-forwarded)
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"0", "1", "2", "3"}, s.messages())
+}
+
+// syslogServer reads octet counted messages from the connections to a listener
+func syslogServer(t *testing.T, ln net.Listener) chan string {
+	messages := make(chan string, 10)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(size))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			msg := make([]byte, n)
+
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+
+			messages <- string(msg)
+		}
+	}()
+
+	return messages
+}
+
+type testSink struct {
+	block    chan struct{}
+	closed   bool
+	entries  []types.LogEntry
+	failures int
+	lock     sync.Mutex
+}
+
+func (s *testSink) Send(entries []types.LogEntry) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return fmt.Errorf("sink down")
+	}
+
+	s.entries = append(s.entries, entries...)
+
+	return nil
+}
+
+func (s *testSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *testSink) messages() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	messages := []string{}
+
+	for _, e := range s.entries {
+		messages = append(messages, e.Message)
+	}
+
+	return messages
+}
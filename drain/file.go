@@ -0,0 +1,53 @@
+package drain
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+// fileSink appends entries to a file as text lines prefixed with their time and source
+// the file is opened on the first send so that a drain to a missing directory recovers once it is created
+type fileSink struct {
+	file *os.File
+	path string
+}
+
+func (s *fileSink) Send(entries []types.LogEntry) error {
+	if s.file == nil {
+		fd, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+
+		s.file = fd
+	}
+
+	var buf bytes.Buffer
+
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%s %s %s\n", e.Timestamp.UTC().Format(time.RFC3339Nano), e.Source(), e.Message)
+	}
+
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		s.file.Close()
+		s.file = nil
+		return err
+	}
+
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
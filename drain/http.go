@@ -0,0 +1,60 @@
+package drain
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/convox/praxis/types"
+)
+
+// httpSink posts each batch as a json array of entries
+// the cursor of each entry lets a receiver drop the duplicates of a batch that was sent again
+type httpSink struct {
+	client *http.Client
+	url    string
+}
+
+func newHTTPSink(u string, config *tls.Config) *httpSink {
+	client := &http.Client{Timeout: Timeout}
+
+	if config != nil {
+		client.Transport = &http.Transport{TLSClientConfig: config}
+	}
+
+	return &httpSink{client: client, url: u}
+}
+
+func (s *httpSink) Send(entries []types.LogEntry) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "convox")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("drain response: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	return nil
+}
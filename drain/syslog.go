@@ -0,0 +1,130 @@
+package drain
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+const syslogTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// syslogSink writes RFC 5424 messages, over tcp and tls each one is framed with its length as in RFC 6587
+// the hostname of a message is the app, the app name is the service and the process id is the process
+type syslogSink struct {
+	addr    string
+	config  *tls.Config
+	conn    net.Conn
+	network string
+}
+
+func (s *syslogSink) Send(entries []types.LogEntry) error {
+	if s.conn == nil {
+		conn, err := s.dial()
+		if err != nil {
+			return err
+		}
+
+		s.conn = conn
+	}
+
+	if err := s.write(entries); err != nil {
+		// the connection is in an unknown state, the batch will be sent again on a new one
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+func (s *syslogSink) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: Timeout}
+
+	switch s.network {
+	case "tls":
+		return tls.DialWithDialer(d, "tcp", s.addr, s.config)
+	default:
+		return d.Dial(s.network, s.addr)
+	}
+}
+
+func (s *syslogSink) write(entries []types.LogEntry) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(Timeout)); err != nil {
+		return err
+	}
+
+	// each datagram carries a single message
+	if s.network == "udp" {
+		for _, e := range entries {
+			if _, err := s.conn.Write(syslogMessage(e)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	for _, e := range entries {
+		msg := syslogMessage(e)
+		fmt.Fprintf(&buf, "%d %s", len(msg), msg)
+	}
+
+	_, err := s.conn.Write(buf.Bytes())
+
+	return err
+}
+
+// syslogMessage formats an entry with the user facility, at error severity for stderr and info otherwise
+func syslogMessage(e types.LogEntry) []byte {
+	severity := 6
+
+	if e.Stream == "stderr" {
+		severity = 3
+	}
+
+	ts := "-"
+
+	if !e.Timestamp.IsZero() {
+		ts = e.Timestamp.UTC().Format(syslogTime)
+	}
+
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %s - - %s", 8+severity, ts, syslogField(e.App, 255), syslogField(e.Service, 48), syslogField(e.Process, 128), e.Message))
+}
+
+// syslogField makes a header field out of s, header fields are printable ascii without spaces and - when empty
+func syslogField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+
+	if len(s) > max {
+		s = s[0:max]
+	}
+
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,201 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+// LogCursorExpiry is how long a source can go without logging before its cursor is dropped
+// without it the cursor of a long running stream would name every process that ever logged
+var LogCursorExpiry = 10 * time.Minute
+
+// LogWriter writes the entries of a log stream in the form asked for by LogsOptions
+// each entry is given a cursor and entries up to opts.Cursor are skipped so that a dropped stream can be resumed
+// the cursor holds a position for each source, so entries of merged sources can arrive in any order relative to each other
+type LogWriter struct {
+	after   types.LogCursors
+	cursors types.LogCursors
+	filter  types.LogFilter
+	last    map[string]types.LogCursor
+	lock    sync.Mutex
+	opts    types.LogsOptions
+	output  io.Writer
+}
+
+func NewLogWriter(w io.Writer, opts types.LogsOptions) (*LogWriter, error) {
+	switch opts.Format {
+	case "", types.LogFormatJSON, types.LogFormatText:
+	default:
+		return nil, fmt.Errorf("invalid log format: %s", opts.Format)
+	}
+
+	filter, err := types.ParseLogFilter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	lw := &LogWriter{
+		after:   types.LogCursors{},
+		cursors: types.LogCursors{},
+		filter:  filter,
+		last:    map[string]types.LogCursor{},
+		opts:    opts,
+		output:  w,
+	}
+
+	if opts.Cursor != "" {
+		cs, err := types.ParseLogCursors(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		// sources that are not read again before the stream drops keep their place
+		for s, c := range cs {
+			lw.after[s] = c
+			lw.cursors[s] = c
+		}
+	}
+
+	return lw, nil
+}
+
+// Filter returns the filter entries are checked against
+func (lw *LogWriter) Filter() types.LogFilter {
+	return lw.filter
+}
+
+// Since returns the time from which a provider should read lines
+// a resumed stream is read from its earliest cursor, the lines each source already sent are then skipped
+func (lw *LogWriter) Since() time.Time {
+	since := lw.opts.Since
+
+	earliest := time.Time{}
+
+	for _, c := range lw.after {
+		if c.Time.IsZero() {
+			continue
+		}
+
+		if earliest.IsZero() || c.Time.Before(earliest) {
+			earliest = c.Time
+		}
+	}
+
+	if earliest.After(since) {
+		return earliest
+	}
+
+	return since
+}
+
+// Entry writes an entry if it matches the filter, the source of the entry is shown when opts.Prefix is set
+func (lw *LogWriter) Entry(e types.LogEntry) error {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	source := e.CursorSource()
+
+	c := types.LogCursor{Time: e.Timestamp}
+
+	// the lines of a source are ordered by their position in it when their times are out of order or the same
+	if last, ok := lw.last[source]; ok && !last.Time.Before(c.Time) {
+		c = types.LogCursor{Time: last.Time, Index: last.Index + 1}
+	}
+
+	lw.last[source] = c
+
+	if after, ok := lw.after[source]; ok && !after.Before(c) {
+		return nil
+	}
+
+	lw.cursors[source] = c
+
+	// sources that stopped logging long before this line are dropped, lines without a time are never dropped
+	for s, sc := range lw.cursors {
+		if !sc.Time.IsZero() && sc.Time.Add(LogCursorExpiry).Before(c.Time) {
+			delete(lw.cursors, s)
+			delete(lw.last, s)
+		}
+	}
+
+	if e.Fields == nil && (len(lw.filter) > 0 || lw.opts.Format == types.LogFormatJSON) {
+		e.Fields = types.ParseLogFields(e.Message)
+	}
+
+	if !lw.filter.Match(e) {
+		return nil
+	}
+
+	e.Cursor = lw.cursors.String()
+
+	var buf bytes.Buffer
+
+	if lw.opts.Cursors {
+		fmt.Fprintf(&buf, "%s ", e.Cursor)
+	}
+
+	switch {
+	case lw.opts.Format == types.LogFormatJSON:
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	case lw.opts.Prefix:
+		fmt.Fprintf(&buf, "%s %s %s", e.Timestamp.Format(PrintableTime), e.Source(), e.Message)
+	default:
+		buf.WriteString(e.Message)
+	}
+
+	buf.WriteString("\n")
+
+	if _, err := lw.output.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	if f, ok := lw.output.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return nil
+}
+
+// Copy writes each line read from r as an entry like e, the lines carry no time so they are ordered by position alone
+func (lw *LogWriter) Copy(r io.Reader, e types.LogEntry) error {
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		e.Message = s.Text()
+
+		if err := lw.Entry(e); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// LogStream reads a stored log through a LogWriter so that it honors cursors, filters and formats like a live one
+func LogStream(r io.ReadCloser, e types.LogEntry, opts types.LogsOptions) (io.ReadCloser, error) {
+	rr, rw := io.Pipe()
+
+	lw, err := NewLogWriter(rw, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer r.Close()
+		rw.CloseWithError(lw.Copy(r, e))
+	}()
+
+	return rr, nil
+}
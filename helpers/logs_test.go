@@ -0,0 +1,188 @@
+package helpers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLines(t *testing.T, opts types.LogsOptions) string {
+	var buf bytes.Buffer
+
+	lw, err := NewLogWriter(&buf, opts)
+	require.NoError(t, err)
+
+	t1 := time.Unix(100, 0).UTC()
+	t2 := time.Unix(200, 0).UTC()
+
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t1, App: "app", Service: "web", Process: "P1", Message: "one"}))
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t1, App: "app", Service: "web", Process: "P1", Message: "two status=500"}))
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t2, App: "app", Service: "web", Process: "P1", Message: "three status=200"}))
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t1, App: "app", Service: "worker", Process: "P2", Message: "late"}))
+
+	return buf.String()
+}
+
+func TestLogWriter(t *testing.T) {
+	assert.Equal(t, "one\ntwo status=500\nthree status=200\nlate\n", writeLines(t, types.LogsOptions{}))
+}
+
+func TestLogWriterCursors(t *testing.T) {
+	out := writeLines(t, types.LogsOptions{Cursors: true})
+
+	assert.Equal(t, "P1=100000000000-0 one\nP1=100000000000-1 two status=500\nP1=200000000000-0 three status=200\nP1=200000000000-0,P2=100000000000-0 late\n", out)
+}
+
+func TestLogWriterPrefix(t *testing.T) {
+	out := writeLines(t, types.LogsOptions{Prefix: true})
+
+	assert.Equal(t, "1970-01-01 00:01:40 app/web/P1 one\n", strings.SplitAfter(out, "\n")[0])
+	assert.Equal(t, "1970-01-01 00:01:40 app/worker/P2 late\n", strings.SplitAfter(out, "\n")[3])
+}
+
+func TestLogWriterResume(t *testing.T) {
+	assert.Equal(t, "two status=500\nthree status=200\nlate\n", writeLines(t, types.LogsOptions{Cursor: "P1=100000000000-0"}))
+	assert.Equal(t, "late\n", writeLines(t, types.LogsOptions{Cursor: "P1=200000000000-0"}))
+	assert.Equal(t, "", writeLines(t, types.LogsOptions{Cursor: "P1=200000000000-0,P2=100000000000-0"}))
+}
+
+// TestLogWriterResumeMerged writes the lines of two processes in the order they arrived, then resumes from the cursor of each line
+// the lines are merged differently after resuming, as they are when each source is read again on its own
+func TestLogWriterResumeMerged(t *testing.T) {
+	t1 := time.Unix(100, 0).UTC()
+	t2 := time.Unix(200, 0).UTC()
+	t3 := time.Unix(300, 0).UTC()
+
+	a1 := types.LogEntry{Timestamp: t1, Process: "P1", Message: "a1"}
+	a2 := types.LogEntry{Timestamp: t3, Process: "P1", Message: "a2"}
+	a3 := types.LogEntry{Timestamp: t3, Process: "P1", Message: "a3"}
+	b1 := types.LogEntry{Timestamp: t2, Process: "P2", Message: "b1"}
+	b2 := types.LogEntry{Timestamp: t2, Process: "P2", Message: "b2"}
+
+	write := func(opts types.LogsOptions, entries ...types.LogEntry) []string {
+		var buf bytes.Buffer
+
+		lw, err := NewLogWriter(&buf, opts)
+		require.NoError(t, err)
+
+		for _, e := range entries {
+			require.NoError(t, lw.Entry(e))
+		}
+
+		return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	}
+
+	// P1 runs ahead of P2 and its later lines arrive first
+	lines := write(types.LogsOptions{Cursors: true}, a1, a2, b1, a3, b2)
+
+	require.Len(t, lines, 5)
+	assert.Equal(t, "P1=300000000000-0,P2=200000000000-0 b1", lines[2])
+
+	for i, line := range lines {
+		parts := strings.SplitN(line, " ", 2)
+
+		cursor, err := types.ParseLogCursors(parts[0])
+		require.NoError(t, err)
+
+		require.NotEmpty(t, cursor)
+
+		// the sources are read again from the start and merged in another order
+		resumed := write(types.LogsOptions{Cursor: parts[0]}, b1, b2, a1, a2, a3)
+
+		var expected []string
+
+		for _, rest := range lines[i+1:] {
+			expected = append(expected, strings.SplitN(rest, " ", 2)[1])
+		}
+
+		if len(expected) == 0 {
+			expected = []string{""}
+		}
+
+		assert.Equal(t, sortedLines(expected), sortedLines(resumed), "resume after %s", parts[1])
+	}
+}
+
+func sortedLines(lines []string) []string {
+	sorted := append([]string{}, lines...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func TestLogWriterExpiry(t *testing.T) {
+	var buf bytes.Buffer
+
+	lw, err := NewLogWriter(&buf, types.LogsOptions{Cursors: true})
+	require.NoError(t, err)
+
+	t1 := time.Unix(100, 0).UTC()
+
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t1, Process: "P1", Message: "one"}))
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t1.Add(LogCursorExpiry), Process: "P2", Message: "two"}))
+	require.NoError(t, lw.Entry(types.LogEntry{Timestamp: t1.Add(LogCursorExpiry + time.Second), Process: "P2", Message: "three"}))
+
+	assert.Equal(t, "P1=100000000000-0 one\nP1=100000000000-0,P2=700000000000-0 two\nP2=701000000000-0 three\n", buf.String())
+}
+
+func TestLogWriterFilter(t *testing.T) {
+	assert.Equal(t, "two status=500\n", writeLines(t, types.LogsOptions{Filter: "status>=500"}))
+	assert.Equal(t, "three status=200\n", writeLines(t, types.LogsOptions{Filter: "service=web th"}))
+	assert.Equal(t, "late\n", writeLines(t, types.LogsOptions{Filter: "service!=web"}))
+
+	out := writeLines(t, types.LogsOptions{Cursors: true, Filter: "status<300"})
+	assert.Equal(t, "P1=200000000000-0 three status=200\n", out)
+}
+
+func TestLogWriterJSON(t *testing.T) {
+	out := writeLines(t, types.LogsOptions{Filter: "status=500", Format: types.LogFormatJSON})
+
+	assert.Equal(t, `{"cursor":"P1=100000000000-1","timestamp":"1970-01-01T00:01:40Z","app":"app","service":"web","process":"P1","message":"two status=500","fields":{"status":"500"}}`+"\n", out)
+}
+
+func TestLogWriterInvalid(t *testing.T) {
+	_, err := NewLogWriter(ioutil.Discard, types.LogsOptions{Format: "xml"})
+	assert.EqualError(t, err, "invalid log format: xml")
+
+	_, err = NewLogWriter(ioutil.Discard, types.LogsOptions{Filter: ">=5"})
+	assert.EqualError(t, err, "invalid filter term: >=5")
+}
+
+func TestLogWriterSince(t *testing.T) {
+	since := time.Unix(50, 0)
+
+	lw, err := NewLogWriter(ioutil.Discard, types.LogsOptions{Since: since})
+	require.NoError(t, err)
+	assert.Equal(t, since, lw.Since())
+
+	lw, err = NewLogWriter(ioutil.Discard, types.LogsOptions{Cursor: "P1=100000000000-3,P2=200000000000-0", Since: since})
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(100, 0).UTC(), lw.Since())
+
+	lw, err = NewLogWriter(ioutil.Discard, types.LogsOptions{Cursor: "P1=100000000000-3", Since: time.Unix(150, 0)})
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(150, 0), lw.Since())
+
+	lw, err = NewLogWriter(ioutil.Discard, types.LogsOptions{Cursor: "0-4", Since: since})
+	require.NoError(t, err)
+	assert.Equal(t, since, lw.Since())
+
+	_, err = NewLogWriter(ioutil.Discard, types.LogsOptions{Cursor: "foo"})
+	assert.EqualError(t, err, "invalid cursor: foo")
+}
+
+func TestLogStream(t *testing.T) {
+	r, err := LogStream(ioutil.NopCloser(strings.NewReader("one\ntwo\nthree\n")), types.LogEntry{App: "app"}, types.LogsOptions{Cursor: "0-0", Cursors: true})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0-1 two\n0-2 three\n", string(data))
+}
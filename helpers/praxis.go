@@ -41,7 +41,7 @@ func ReleaseManifest(p types.Provider, app, release string) (*manifest.Manifest,
 	}
 
 	if r.Build == "" {
-		return nil, nil, errors.WithStack(fmt.Errorf("no builds for app: %s", app))
+		return nil, nil, errors.WithStack(fmt.Errorf("no build for release: %s", r.Id))
 	}
 
 	b, err := p.BuildGet(app, r.Build)
@@ -15,6 +15,23 @@ func TestManifestLoad(t *testing.T) {
 	}
 
 	n := &manifest.Manifest{
+		Balancers: manifest.Balancers{
+			manifest.Balancer{
+				Name: "main",
+				Endpoints: manifest.BalancerEndpoints{
+					manifest.BalancerEndpoint{
+						Port:     "80",
+						Protocol: "http",
+						Redirect: "https://:443/*",
+					},
+					manifest.BalancerEndpoint{
+						Port:     "443",
+						Protocol: "https",
+						Target:   "https://api:1000",
+					},
+				},
+			},
+		},
 		Environment: manifest.Environment{
 			"DEVELOPMENT": "false",
 			"SECRET":      "shh",
@@ -34,6 +51,10 @@ func TestManifestLoad(t *testing.T) {
 				Name: "database",
 				Type: "postgres",
 			},
+			manifest.Resource{
+				Name: "sessions",
+				Rack: "shared-redis",
+			},
 		},
 		Services: manifest.Services{
 			manifest.Service{
@@ -53,7 +74,7 @@ func TestManifestLoad(t *testing.T) {
 					Timeout:  9,
 				},
 				Port:      manifest.ServicePort{Port: 1000, Scheme: "http"},
-				Resources: []string{"database"},
+				Resources: []string{"database", "sessions"},
 				Scale: manifest.ServiceScale{
 					Count:  &manifest.ServiceScaleCount{Min: 3, Max: 10},
 					Memory: 256,
@@ -63,6 +84,7 @@ func TestManifestLoad(t *testing.T) {
 			manifest.Service{
 				Name:    "proxy",
 				Command: "bash",
+				Domains: []string{"example.org", "www.example.org"},
 				Health: manifest.ServiceHealth{
 					Path:     "/auth",
 					Interval: 5,
@@ -84,11 +106,13 @@ func TestManifestLoad(t *testing.T) {
 					Path: ".",
 				},
 				Command: "foo",
+				Domains: []string{"example.org"},
 				Health: manifest.ServiceHealth{
 					Interval: 5,
 					Path:     "/",
 					Timeout:  3,
 				},
+				Path: "/foo",
 				Port: manifest.ServicePort{Port: 3000, Scheme: "https"},
 				Scale: manifest.ServiceScale{
 					Count:  &manifest.ServiceScaleCount{Min: 0, Max: 0},
@@ -106,6 +130,7 @@ func TestManifestLoad(t *testing.T) {
 					Path:     "/",
 					Timeout:  4,
 				},
+				Port: manifest.ServicePort{Port: 8125, Scheme: "udp"},
 				Scale: manifest.ServiceScale{
 					Count:  &manifest.ServiceScaleCount{Min: 1, Max: 1},
 					Memory: 256,
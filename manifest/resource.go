@@ -2,6 +2,7 @@ package manifest
 
 type Resource struct {
 	Name string `yaml:"-"`
+	Rack string `yaml:"rack,omitempty"`
 	Type string `yaml:"type"`
 }
 
@@ -10,3 +11,8 @@ type Resources []Resource
 func (r Resource) GetName() string {
 	return r.Name
 }
+
+// Shared returns true if this resource references a rack-level resource
+func (r Resource) Shared() bool {
+	return r.Rack != ""
+}
@@ -3,6 +3,7 @@ package manifest
 import (
 	"crypto/sha1"
 	"fmt"
+	"strings"
 )
 
 type Service struct {
@@ -11,9 +12,11 @@ type Service struct {
 	Build       ServiceBuild       `yaml:"build,omitempty"`
 	Certificate string             `yaml:"certificate,omitempty"`
 	Command     string             `yaml:"command,omitempty"`
+	Domains     []string           `yaml:"domains,omitempty"`
 	Environment ServiceEnvironment `yaml:"environment,omitempty"`
 	Health      ServiceHealth      `yaml:"health,omitempty"`
 	Image       string             `yaml:"image,omitempty"`
+	Path        string             `yaml:"path,omitempty"`
 	Port        ServicePort        `yaml:"port,omitempty"`
 	Resources   []string           `yaml:"resources,omitempty"`
 	Scale       ServiceScale       `yaml:"scale,omitempty"`
@@ -70,5 +73,23 @@ func (s *Service) SetDefaults() error {
 		s.Scale.Count = &ServiceScaleCount{Min: 1, Max: 1}
 	}
 
+	if s.Path != "" && !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("path for service %s must begin with /", s.Name)
+	}
+
+	s.Path = strings.TrimSuffix(s.Path, "/")
+
+	if s.Port.Port > 0 {
+		switch s.Port.Scheme {
+		case "http", "https", "tcp", "udp":
+		default:
+			return fmt.Errorf("unknown port scheme for service %s: %s", s.Name, s.Port.Scheme)
+		}
+	}
+
+	if s.Path != "" && (s.Port.Scheme == "tcp" || s.Port.Scheme == "udp") {
+		return fmt.Errorf("path for service %s requires an http port", s.Name)
+	}
+
 	return nil
 }
@@ -0,0 +1,349 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit request latencies in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Default is the registry used by the package-level functions
+var Default = NewRegistry()
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// Registry holds metrics and the collectors that refresh them before they are written
+type Registry struct {
+	collectors []func()
+	lock       sync.Mutex
+	metrics    map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+// Collect registers fn to be called before metrics are written, used for values that are sampled rather than counted
+func (r *Registry) Collect(fn func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.collectors = append(r.collectors, fn)
+}
+
+// Counter returns the counter registered as name, registering it if needed
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return r.register(name, func() metric { return &Counter{series: newSeries(name, help, "counter", labels)} }).(*Counter)
+}
+
+// Gauge returns the gauge registered as name, registering it if needed
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return r.register(name, func() metric { return &Gauge{series: newSeries(name, help, "gauge", labels)} }).(*Gauge)
+}
+
+// Histogram returns the histogram registered as name, registering it if needed
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return r.register(name, func() metric {
+		return &Histogram{buckets: buckets, series: newSeries(name, help, "histogram", labels), values: map[string]*histogramValue{}}
+	}).(*Histogram)
+}
+
+// Write runs the collectors and writes every metric in the prometheus text format
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.Lock()
+	collectors := make([]func(), len(r.collectors))
+	copy(collectors, r.collectors)
+	r.lock.Unlock()
+
+	for _, fn := range collectors {
+		fn()
+	}
+
+	r.lock.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.lock.Unlock()
+
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+
+	for _, name := range names {
+		r.lock.Lock()
+		m := r.metrics[name]
+		r.lock.Unlock()
+
+		m.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+func (r *Registry) register(name string, fn func() metric) metric {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if m, ok := r.metrics[name]; ok {
+		return m
+	}
+
+	m := fn()
+
+	r.metrics[name] = m
+
+	return m
+}
+
+func Collect(fn func()) {
+	Default.Collect(fn)
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.Counter(name, help, labels...)
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.Gauge(name, help, labels...)
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.Histogram(name, help, buckets, labels...)
+}
+
+func Write(w io.Writer) error {
+	return Default.Write(w)
+}
+
+// series is the common part of a metric, a set of values keyed by label values
+type series struct {
+	help   string
+	kind   string
+	labels []string
+	lock   sync.Mutex
+	name   string
+}
+
+func newSeries(name, help, kind string, labels []string) series {
+	return series{help: help, kind: kind, labels: labels, name: name}
+}
+
+func (s *series) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", s.name, s.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", s.name, s.kind)
+}
+
+// key joins label values into a map key, missing values are empty
+func (s *series) key(values []string) string {
+	vs := make([]string, len(s.labels))
+	copy(vs, values)
+	return strings.Join(vs, "\xff")
+}
+
+// pairs renders the labels for a key along with any extra pairs
+func (s *series) pairs(key string, extra ...string) string {
+	values := strings.Split(key, "\xff")
+
+	pairs := []string{}
+
+	for i, l := range s.labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, l, labelEscaper.Replace(values[i])))
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], labelEscaper.Replace(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(pairs, ","))
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	series
+	values map[string]float64
+}
+
+// Add adds v to the counter for a set of label values
+func (c *Counter) Add(v float64, labels ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.values == nil {
+		c.values = map[string]float64{}
+	}
+
+	c.values[c.key(labels)] += v
+}
+
+// Inc adds one to the counter for a set of label values
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.header(w)
+
+	for _, k := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.pairs(k), formatFloat(c.values[k]))
+	}
+}
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	series
+	values map[string]float64
+}
+
+// Add adds v to the gauge for a set of label values
+func (g *Gauge) Add(v float64, labels ...string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.values == nil {
+		g.values = map[string]float64{}
+	}
+
+	g.values[g.key(labels)] += v
+}
+
+func (g *Gauge) Dec(labels ...string) {
+	g.Add(-1, labels...)
+}
+
+func (g *Gauge) Inc(labels ...string) {
+	g.Add(1, labels...)
+}
+
+// Reset removes every value, used by collectors to drop label values that no longer exist
+func (g *Gauge) Reset() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.values = map[string]float64{}
+}
+
+// Set sets the gauge for a set of label values
+func (g *Gauge) Set(v float64, labels ...string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.values == nil {
+		g.values = map[string]float64{}
+	}
+
+	g.values[g.key(labels)] = v
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.header(w)
+
+	for _, k := range sortedKeys(g.values) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.pairs(k), formatFloat(g.values[k]))
+	}
+}
+
+// Histogram counts observations into buckets
+type Histogram struct {
+	series
+	buckets []float64
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe records v for a set of label values
+func (h *Histogram) Observe(v float64, labels ...string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	k := h.key(labels)
+
+	hv, ok := h.values[k]
+	if !ok {
+		hv = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[k] = hv
+	}
+
+	for i, b := range h.buckets {
+		if v <= b {
+			hv.counts[i]++
+		}
+	}
+
+	hv.count++
+	hv.sum += v
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.header(w)
+
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		hv := h.values[k]
+
+		for i, b := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.pairs(k, "le", formatFloat(b)), hv.counts[i])
+		}
+
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.pairs(k, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.pairs(k), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.pairs(k), hv.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,73 @@
+package metrics_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/convox/praxis/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	c := r.Counter("test_total", "a counter", "app")
+
+	c.Inc("web")
+	c.Add(2, "web")
+	c.Inc("api")
+
+	assert.Equal(t, c, r.Counter("test_total", "a counter", "app"))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.Write(&buf))
+
+	assert.Equal(t, "# HELP test_total a counter\n# TYPE test_total counter\ntest_total{app=\"api\"} 1\ntest_total{app=\"web\"} 3\n", buf.String())
+}
+
+func TestGaugeCollect(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	g := r.Gauge("test_depth", "a gauge", "app", "queue")
+
+	g.Set(5, "web", "old")
+
+	r.Collect(func() {
+		g.Reset()
+		g.Set(2, "web", "jobs")
+		g.Set(0.5, "we\"b", "jobs")
+	})
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.Write(&buf))
+
+	assert.Equal(t, "# HELP test_depth a gauge\n# TYPE test_depth gauge\ntest_depth{app=\"we\\\"b\",queue=\"jobs\"} 0.5\ntest_depth{app=\"web\",queue=\"jobs\"} 2\n", buf.String())
+}
+
+func TestHistogram(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	h := r.Histogram("test_seconds", "a histogram", []float64{0.1, 1})
+
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.Write(&buf))
+
+	expected := `# HELP test_seconds a histogram
+# TYPE test_seconds histogram
+test_seconds_bucket{le="0.1"} 1
+test_seconds_bucket{le="1"} 2
+test_seconds_bucket{le="+Inf"} 3
+test_seconds_sum 5.55
+test_seconds_count 3
+`
+
+	assert.Equal(t, expected, buf.String())
+}
@@ -140,6 +140,29 @@ func (_m *Provider) AppRegistry(app string) (*types.Registry, error) {
 	return r0, r1
 }
 
+// BalancerList provides a mock function with given fields: app
+func (_m *Provider) BalancerList(app string) (types.Balancers, error) {
+	ret := _m.Called(app)
+
+	var r0 types.Balancers
+	if rf, ok := ret.Get(0).(func(string) types.Balancers); ok {
+		r0 = rf(app)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.Balancers)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(app)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BuildCreate provides a mock function with given fields: app, url, opts
 func (_m *Provider) BuildCreate(app string, url string, opts types.BuildCreateOptions) (*types.Build, error) {
 	ret := _m.Called(app, url, opts)
@@ -209,13 +232,13 @@ func (_m *Provider) BuildList(app string) (types.Builds, error) {
 	return r0, r1
 }
 
-// BuildLogs provides a mock function with given fields: app, id
-func (_m *Provider) BuildLogs(app string, id string) (io.ReadCloser, error) {
-	ret := _m.Called(app, id)
+// BuildLogs provides a mock function with given fields: app, id, opts
+func (_m *Provider) BuildLogs(app string, id string, opts types.LogsOptions) (io.ReadCloser, error) {
+	ret := _m.Called(app, id, opts)
 
 	var r0 io.ReadCloser
-	if rf, ok := ret.Get(0).(func(string, string) io.ReadCloser); ok {
-		r0 = rf(app, id)
+	if rf, ok := ret.Get(0).(func(string, string, types.LogsOptions) io.ReadCloser); ok {
+		r0 = rf(app, id, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(io.ReadCloser)
@@ -223,8 +246,8 @@ func (_m *Provider) BuildLogs(app string, id string) (io.ReadCloser, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(app, id)
+	if rf, ok := ret.Get(1).(func(string, string, types.LogsOptions) error); ok {
+		r1 = rf(app, id, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -292,6 +315,103 @@ func (_m *Provider) CacheStore(app string, cache string, key string, attrs map[s
 	return r0
 }
 
+// DrainCreate provides a mock function with given fields: app, url
+func (_m *Provider) DrainCreate(app string, url string) (*types.Drain, error) {
+	ret := _m.Called(app, url)
+
+	var r0 *types.Drain
+	if rf, ok := ret.Get(0).(func(string, string) *types.Drain); ok {
+		r0 = rf(app, url)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Drain)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(app, url)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DrainDelete provides a mock function with given fields: app, id
+func (_m *Provider) DrainDelete(app string, id string) error {
+	ret := _m.Called(app, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(app, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DrainList provides a mock function with given fields: app
+func (_m *Provider) DrainList(app string) (types.Drains, error) {
+	ret := _m.Called(app)
+
+	var r0 types.Drains
+	if rf, ok := ret.Get(0).(func(string) types.Drains); ok {
+		r0 = rf(app)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.Drains)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(app)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EventSend provides a mock function with given fields: action, opts
+func (_m *Provider) EventSend(action string, opts types.EventSendOptions) error {
+	ret := _m.Called(action, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, types.EventSendOptions) error); ok {
+		r0 = rf(action, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EventStream provides a mock function with given fields: opts
+func (_m *Provider) EventStream(opts types.EventStreamOptions) (io.ReadCloser, error) {
+	ret := _m.Called(opts)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(types.EventStreamOptions) io.ReadCloser); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(types.EventStreamOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FilesDelete provides a mock function with given fields: app, pid, files
 func (_m *Provider) FilesDelete(app string, pid string, files []string) error {
 	ret := _m.Called(app, pid, files)
@@ -920,6 +1040,43 @@ func (_m *Provider) ServiceList(app string) (types.Services, error) {
 	return r0, r1
 }
 
+// SystemAudit provides a mock function with given fields: opts
+func (_m *Provider) SystemAudit(opts types.SystemAuditOptions) (types.AuditEvents, error) {
+	ret := _m.Called(opts)
+
+	var r0 types.AuditEvents
+	if rf, ok := ret.Get(0).(func(types.SystemAuditOptions) types.AuditEvents); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.AuditEvents)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(types.SystemAuditOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemAuditRecord provides a mock function with given fields: event
+func (_m *Provider) SystemAuditRecord(event types.AuditEvent) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(types.AuditEvent) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SystemGet provides a mock function with given fields:
 func (_m *Provider) SystemGet() (*types.System, error) {
 	ret := _m.Called()
@@ -1010,6 +1167,112 @@ func (_m *Provider) SystemOptions() (map[string]string, error) {
 	return r0, r1
 }
 
+// SystemResourceCreate provides a mock function with given fields: name, kind
+func (_m *Provider) SystemResourceCreate(name string, kind string) (*types.Resource, error) {
+	ret := _m.Called(name, kind)
+
+	var r0 *types.Resource
+	if rf, ok := ret.Get(0).(func(string, string) *types.Resource); ok {
+		r0 = rf(name, kind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Resource)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(name, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemResourceDelete provides a mock function with given fields: name
+func (_m *Provider) SystemResourceDelete(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SystemResourceGet provides a mock function with given fields: name
+func (_m *Provider) SystemResourceGet(name string) (*types.Resource, error) {
+	ret := _m.Called(name)
+
+	var r0 *types.Resource
+	if rf, ok := ret.Get(0).(func(string) *types.Resource); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Resource)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemResourceList provides a mock function with given fields:
+func (_m *Provider) SystemResourceList() (types.Resources, error) {
+	ret := _m.Called()
+
+	var r0 types.Resources
+	if rf, ok := ret.Get(0).(func() types.Resources); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.Resources)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemResourceProxy provides a mock function with given fields: name, in
+func (_m *Provider) SystemResourceProxy(name string, in io.Reader) (io.ReadCloser, error) {
+	ret := _m.Called(name, in)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, io.Reader) io.ReadCloser); ok {
+		r0 = rf(name, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, io.Reader) error); ok {
+		r1 = rf(name, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SystemUninstall provides a mock function with given fields: name, opts
 func (_m *Provider) SystemUninstall(name string, opts types.SystemInstallOptions) error {
 	ret := _m.Called(name, opts)
@@ -1121,6 +1384,149 @@ func (_m *Provider) TableTruncate(app string, table string) error {
 	return r0
 }
 
+// TokenCreate provides a mock function with given fields: name, opts
+func (_m *Provider) TokenCreate(name string, opts types.TokenCreateOptions) (*types.Token, error) {
+	ret := _m.Called(name, opts)
+
+	var r0 *types.Token
+	if rf, ok := ret.Get(0).(func(string, types.TokenCreateOptions) *types.Token); ok {
+		r0 = rf(name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Token)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, types.TokenCreateOptions) error); ok {
+		r1 = rf(name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TokenDelete provides a mock function with given fields: id
+func (_m *Provider) TokenDelete(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TokenGet provides a mock function with given fields: id
+func (_m *Provider) TokenGet(id string) (*types.Token, error) {
+	ret := _m.Called(id)
+
+	var r0 *types.Token
+	if rf, ok := ret.Get(0).(func(string) *types.Token); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Token)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TokenList provides a mock function with given fields:
+func (_m *Provider) TokenList() (types.Tokens, error) {
+	ret := _m.Called()
+
+	var r0 types.Tokens
+	if rf, ok := ret.Get(0).(func() types.Tokens); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.Tokens)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookCreate provides a mock function with given fields: url, opts
+func (_m *Provider) WebhookCreate(url string, opts types.WebhookCreateOptions) (*types.Webhook, error) {
+	ret := _m.Called(url, opts)
+
+	var r0 *types.Webhook
+	if rf, ok := ret.Get(0).(func(string, types.WebhookCreateOptions) *types.Webhook); ok {
+		r0 = rf(url, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Webhook)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, types.WebhookCreateOptions) error); ok {
+		r1 = rf(url, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WebhookDelete provides a mock function with given fields: id
+func (_m *Provider) WebhookDelete(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WebhookList provides a mock function with given fields:
+func (_m *Provider) WebhookList() (types.Webhooks, error) {
+	ret := _m.Called()
+
+	var r0 types.Webhooks
+	if rf, ok := ret.Get(0).(func() types.Webhooks); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(types.Webhooks)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WithContext provides a mock function with given fields: ctx
 func (_m *Provider) WithContext(ctx context.Context) types.Provider {
 	ret := _m.Called(ctx)
@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/types"
 )
 
@@ -34,7 +35,7 @@ func (p *Provider) AppCreate(name string) (*types.App, error) {
 		TemplateBody: aws.String(string(data)),
 	})
 	if awsError(err) == "AlreadyExistsException" {
-		return nil, fmt.Errorf("app already exists: %s", name)
+		return nil, api.Errorf(409, "app already exists: %s", name)
 	}
 	if err != nil {
 		return nil, err
@@ -44,6 +45,10 @@ func (p *Provider) AppCreate(name string) (*types.App, error) {
 }
 
 func (p *Provider) AppDelete(name string) error {
+	if _, err := p.AppGet(name); err != nil {
+		return err
+	}
+
 	bucket, _ := p.appResource(name, "Bucket")
 
 	_, err := p.CloudFormation().DeleteStack(&cloudformation.DeleteStackInput{
@@ -53,6 +58,8 @@ func (p *Provider) AppDelete(name string) error {
 		return err
 	}
 
+	p.clearDescribeStackCache(fmt.Sprintf("%s-%s", p.Name, name))
+
 	if bucket != "" {
 		if err := p.deleteBucket(bucket); err != nil {
 			fmt.Printf("ns=provider.aws at=app.delete error=%q\n", err)
@@ -65,7 +72,7 @@ func (p *Provider) AppDelete(name string) error {
 func (p *Provider) AppGet(name string) (*types.App, error) {
 	stack, err := p.describeStack(fmt.Sprintf("%s-%s", p.Name, name))
 	if awsError(err) == "ValidationError" {
-		return nil, fmt.Errorf("no such app: %s", name)
+		return nil, api.Errorf(404, "no such app: %s", name)
 	}
 	if err != nil {
 		return nil, err
@@ -74,7 +81,7 @@ func (p *Provider) AppGet(name string) (*types.App, error) {
 	app := p.appFromStack(stack)
 
 	if app == nil {
-		return nil, fmt.Errorf("no such app: %s", name)
+		return nil, api.Errorf(404, "no such app: %s", name)
 	}
 
 	if app.Status == "creating" {
@@ -112,7 +119,7 @@ func (p *Provider) AppLogs(app string, opts types.LogsOptions) (io.ReadCloser, e
 
 	r, w := io.Pipe()
 
-	go p.subscribeLogs(group, "", opts, w)
+	go p.subscribeLogs(app, group, "", opts, w)
 
 	return r, nil
 }
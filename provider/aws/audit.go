@@ -0,0 +1,15 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) SystemAudit(opts types.SystemAuditOptions) (types.AuditEvents, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemAuditRecord(event types.AuditEvent) error {
+	return fmt.Errorf("unimplemented")
+}
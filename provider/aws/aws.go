@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/simpledb"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/cache"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/manifest"
@@ -424,13 +426,20 @@ func (p *Provider) writeLogf(group, stream, format string, args ...interface{})
 	}
 }
 
-func (p *Provider) subscribeLogs(group, stream string, opts types.LogsOptions, w io.WriteCloser) error {
-	return p.subscribeLogsCallback(group, stream, opts, w, nil)
+func (p *Provider) subscribeLogs(app, group, stream string, opts types.LogsOptions, w io.WriteCloser) error {
+	return p.subscribeLogsCallback(app, group, stream, opts, w, nil)
 }
 
-func (p *Provider) subscribeLogsCallback(group, stream string, opts types.LogsOptions, w io.WriteCloser, fn func([]*cloudwatchlogs.FilteredLogEvent) bool) error {
+// subscribeLogsCallback writes the events of a log group as entries of app
+// plain words of the filter are handed to cloudwatch as a filter pattern, field comparisons are applied to the events it returns
+func (p *Provider) subscribeLogsCallback(app, group, stream string, opts types.LogsOptions, w io.WriteCloser, fn func([]*cloudwatchlogs.FilteredLogEvent) bool) error {
 	defer w.Close()
 
+	lw, err := helpers.NewLogWriter(w, opts)
+	if err != nil {
+		return err
+	}
+
 	req := &cloudwatchlogs.FilterLogEventsInput{
 		Interleaved:  aws.Bool(true),
 		LogGroupName: aws.String(group),
@@ -440,12 +449,12 @@ func (p *Provider) subscribeLogsCallback(group, stream string, opts types.LogsOp
 		req.LogStreamNames = []*string{aws.String(stream)}
 	}
 
-	if opts.Filter != "" {
-		req.FilterPattern = aws.String(opts.Filter)
+	if words := lw.Filter().Words(); len(words) > 0 {
+		req.FilterPattern = aws.String(filterPattern(words))
 	}
 
-	if !opts.Since.IsZero() {
-		req.StartTime = aws.Int64(opts.Since.UTC().Unix() * 1000)
+	if since := lw.Since(); !since.IsZero() {
+		req.StartTime = aws.Int64(since.UnixNano() / int64(time.Millisecond))
 	}
 
 	for {
@@ -477,19 +486,8 @@ func (p *Provider) subscribeLogsCallback(group, stream string, opts types.LogsOp
 			parts := strings.SplitN(*e.LogStreamName, "/", 3)
 
 			if len(parts) == 3 {
-				pp := strings.Split(parts[2], "-")
-				ts := time.Unix(*e.Timestamp/1000, *e.Timestamp%1000*1000).UTC()
-
-				var err error
-
-				if opts.Prefix {
-					_, err = fmt.Fprintf(w, "%s %s/%s/%s %s\n", ts.Format(helpers.PrintableTime), parts[0], parts[1], pp[len(pp)-1], *e.Message)
-				} else {
-					_, err = fmt.Fprintf(w, "%s\n", *e.Message)
-				}
-
-				if err != nil {
-					if err == io.EOF {
+				if err := lw.Entry(logEntry(app, parts, e)); err != nil {
+					if err == io.EOF || err == io.ErrClosedPipe {
 						return nil
 					}
 					return err
@@ -517,6 +515,38 @@ func (p *Provider) subscribeLogsCallback(group, stream string, opts types.LogsOp
 	return nil
 }
 
+// filterPattern quotes each word so that cloudwatch matches events containing all of them
+func filterPattern(words []string) string {
+	quoted := make([]string, len(words))
+
+	for i, w := range words {
+		quoted[i] = strconv.Quote(w)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// logEntry describes an event from a log stream named convox/<service>/<task> or convox/release/<id>
+func logEntry(app string, stream []string, e *cloudwatchlogs.FilteredLogEvent) types.LogEntry {
+	entry := types.LogEntry{
+		Timestamp: time.Unix(0, *e.Timestamp*int64(time.Millisecond)).UTC(),
+		App:       app,
+		Message:   *e.Message,
+	}
+
+	if stream[1] == "release" {
+		entry.Release = stream[2]
+		return entry
+	}
+
+	pp := strings.Split(stream[2], "-")
+
+	entry.Service = stream[1]
+	entry.Process = pp[len(pp)-1]
+
+	return entry
+}
+
 func (p *Provider) stackOutput(name string, output string) (string, error) {
 	ck := fmt.Sprintf("%s/%s", name, output)
 
@@ -740,8 +770,10 @@ func (p *Provider) taskDefinition(app string, opts types.ProcessRunOptions) (str
 
 	u.User = url.UserPassword(p.Password, "")
 
+	// the rack endpoint presents a publicly trusted certificate
 	aenv := map[string]string{
 		"APP":      app,
+		"RACK_CA":  "system",
 		"RACK_URL": u.String(),
 	}
 
@@ -826,7 +858,7 @@ func (p *Provider) taskForPid(pid string) (*ecs.Task, error) {
 		return nil, err
 	}
 	if len(res.Tasks) < 1 {
-		return nil, fmt.Errorf("could not find task for pid: %s", pid)
+		return nil, api.Errorf(404, "no such process: %s", pid)
 	}
 
 	return res.Tasks[0], nil
@@ -0,0 +1,68 @@
+package aws_test
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/convox/praxis/cache"
+	"github.com/convox/praxis/cycle"
+	"github.com/convox/praxis/provider/aws"
+)
+
+// recording is true when tests should run against real aws and record fixtures instead of replaying them
+var recording = os.Getenv("CYCLE_RECORD") == "true"
+
+// testProvider returns a provider whose aws clients replay the cycles in a fixture file
+// when recording the clients talk to a rack named convox with credentials from the environment and the fixture is written on cleanup
+func testProvider(fixture string) (*aws.Provider, func(), error) {
+	p := &aws.Provider{
+		Name:    "convox",
+		Region:  cycle.AWSRegion,
+		Version: "test",
+	}
+
+	// stacks and resources are cached across providers
+	cache.ClearAll()
+
+	var cleanup func()
+
+	if recording {
+		r, err := cycle.NewRecorder()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if region := os.Getenv("AWS_REGION"); region != "" {
+			p.Region = region
+		}
+
+		p.Config = r.AWSConfig(p.Region)
+
+		cleanup = func() {
+			if err := r.Save(fixture); err != nil {
+				panic(err)
+			}
+		}
+	} else {
+		c, err := cycle.LoadHTTP(fixture)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// templates change with the code, and signatures and release timestamps with the clock, so they are not compared
+		c.Matcher = cycle.MatchAWS("Attribute.3.Value", "Signature", "TemplateBody", "Timestamp")
+
+		p.Config = c.AWSConfig()
+
+		cleanup = c.Server.Close
+	}
+
+	s, err := session.NewSession(p.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.Session = s
+
+	return p, cleanup, nil
+}
@@ -0,0 +1,11 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) BalancerList(app string) (types.Balancers, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
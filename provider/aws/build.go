@@ -2,6 +2,8 @@ package aws
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/simpledb"
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 )
@@ -36,6 +39,16 @@ func (p *Provider) BuildCreate(app, url string, opts types.BuildCreateOptions) (
 		return nil, err
 	}
 
+	registries, err := p.RegistryList()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := json.Marshal(registries)
+	if err != nil {
+		return nil, err
+	}
+
 	repo, err := p.appResource(app, "Repository")
 	if err != nil {
 		return nil, err
@@ -50,6 +63,7 @@ func (p *Provider) BuildCreate(app, url string, opts types.BuildCreateOptions) (
 		Command: fmt.Sprintf("build -id %s -url %s", id, url),
 		Environment: map[string]string{
 			"BUILD_APP":    app,
+			"BUILD_AUTH":   base64.StdEncoding.EncodeToString(auth),
 			"BUILD_PREFIX": fmt.Sprintf("%s-%s", p.Name, app),
 			"BUILD_PUSH":   fmt.Sprintf("%s/%s", ar.Hostname, repo),
 		},
@@ -94,6 +108,10 @@ func (p *Provider) BuildGet(app, id string) (*types.Build, error) {
 		return nil, err
 	}
 
+	if len(res.Attributes) == 0 {
+		return nil, api.Errorf(404, "no such build: %s", id)
+	}
+
 	return p.buildFromAttributes(id, res.Attributes)
 }
 
@@ -127,7 +145,7 @@ func (p *Provider) BuildList(app string) (types.Builds, error) {
 	return builds, nil
 }
 
-func (p *Provider) BuildLogs(app, id string) (io.ReadCloser, error) {
+func (p *Provider) BuildLogs(app, id string, opts types.LogsOptions) (io.ReadCloser, error) {
 	build, err := p.BuildGet(app, id)
 	if err != nil {
 		return nil, err
@@ -135,9 +153,17 @@ func (p *Provider) BuildLogs(app, id string) (io.ReadCloser, error) {
 
 	switch build.Status {
 	case "running":
-		return p.ProcessLogs(app, build.Process, types.LogsOptions{Follow: true})
+		opts.Follow = true
+		opts.Prefix = false
+
+		return p.ProcessLogs(app, build.Process, opts)
 	default:
-		return p.ObjectFetch(app, fmt.Sprintf("convox/builds/%s/log", id))
+		r, err := p.ObjectFetch(app, fmt.Sprintf("convox/builds/%s/log", id))
+		if err != nil {
+			return nil, err
+		}
+
+		return helpers.LogStream(r, types.LogEntry{App: app, Service: "build", Process: build.Process, Release: build.Release}, opts)
 	}
 }
 
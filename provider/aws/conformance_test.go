@@ -0,0 +1,48 @@
+//go:debug randseednop=0
+
+package aws_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/praxis/provider/conformance"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConformance replays recorded aws api responses from testdata/conformance/<check>.json
+// every check needs a recording, set CYCLE_RECORD=true to record them against a real rack
+func TestConformance(t *testing.T) {
+	fixtures, err := filepath.Abs("testdata/conformance")
+	require.NoError(t, err)
+
+	// templates are read relative to the root of the repo
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir("../.."))
+	defer os.Chdir(wd)
+
+	skip := map[string]string{
+		"AppDelete": "cloudformation deletes stacks in the background so the app is still listed as deleting",
+	}
+
+	conformance.Suite{
+		Provider: func(check string) (types.Provider, func(), error) {
+			fixture := filepath.Join(fixtures, fmt.Sprintf("%s.json", check))
+
+			if _, err := os.Stat(fixture); os.IsNotExist(err) && !recording {
+				return nil, nil, fmt.Errorf("no recorded fixture for %s, record one with CYCLE_RECORD=true", check)
+			}
+
+			// generated ids such as release ids are part of the recorded requests
+			rand.Seed(1)
+
+			return testProvider(fixture)
+		},
+		Skip: skip,
+	}.Run(t)
+}
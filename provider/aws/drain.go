@@ -0,0 +1,18 @@
+package aws
+
+import (
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) DrainCreate(app, url string) (*types.Drain, error) {
+	return nil, api.Errorf(501, "log drains are not supported on aws racks")
+}
+
+func (p *Provider) DrainDelete(app, id string) error {
+	return api.Errorf(501, "log drains are not supported on aws racks")
+}
+
+func (p *Provider) DrainList(app string) (types.Drains, error) {
+	return nil, api.Errorf(501, "log drains are not supported on aws racks")
+}
@@ -0,0 +1,16 @@
+package aws
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) EventSend(action string, opts types.EventSendOptions) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) EventStream(opts types.EventStreamOptions) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
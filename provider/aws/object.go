@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/types"
 )
 
@@ -42,7 +43,7 @@ func (p *Provider) ObjectFetch(app, key string) (io.ReadCloser, error) {
 		Key:    aws.String(key),
 	})
 	if awsError(err) == "NoSuchKey" {
-		return nil, fmt.Errorf("no such key: %s", key)
+		return nil, api.Errorf(404, "no such key: %s", key)
 	}
 	if err != nil {
 		return nil, err
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/types"
 	docker "github.com/fsouza/go-dockerclient"
 	shellquote "github.com/kballard/go-shellquote"
@@ -113,7 +114,7 @@ func (p *Provider) ProcessGet(app, pid string) (*types.Process, error) {
 	}
 
 	if ps.App != app {
-		return nil, fmt.Errorf("process not found: %s\n", pid)
+		return nil, api.Errorf(404, "no such process: %s", pid)
 	}
 
 	return ps, nil
@@ -173,7 +174,7 @@ func (p *Provider) ProcessLogs(app, pid string, opts types.LogsOptions) (io.Read
 
 	r, w := io.Pipe()
 
-	go p.subscribeLogsCallback(group, stream, opts, w, func(events []*cloudwatchlogs.FilteredLogEvent) bool {
+	go p.subscribeLogsCallback(app, group, stream, opts, w, func(events []*cloudwatchlogs.FilteredLogEvent) bool {
 		t, err := p.taskForPid(pid)
 		if err != nil {
 			return false
@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/simpledb"
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 )
@@ -58,7 +59,7 @@ func (p *Provider) ReleaseGet(app, id string) (release *types.Release, err error
 	}
 
 	if len(res.Attributes) == 0 {
-		return nil, fmt.Errorf("release not found")
+		return nil, api.Errorf(404, "no such release: %s", id)
 	}
 
 	r, err := p.releaseFromAttributes(id, res.Attributes)
@@ -124,7 +125,7 @@ func (p *Provider) ReleaseLogs(app, id string, opts types.LogsOptions) (io.ReadC
 
 	r, w := io.Pipe()
 
-	go p.subscribeLogsCallback(group, stream, opts, w, func(events []*cloudwatchlogs.FilteredLogEvent) bool {
+	go p.subscribeLogsCallback(app, group, stream, opts, w, func(events []*cloudwatchlogs.FilteredLogEvent) bool {
 		for _, e := range events {
 			if e.Message == nil {
 				continue
@@ -161,6 +162,12 @@ func (p *Provider) ReleasePromote(app string, id string) error {
 		return fmt.Errorf("no build for release: %s", r.Id)
 	}
 
+	for _, mr := range m.Resources {
+		if mr.Shared() {
+			return fmt.Errorf("rack resources are not supported on this provider: %s", mr.Name)
+		}
+	}
+
 	// group, err := p.appResource(app, "Logs")
 	// if err != nil {
 	//   return err
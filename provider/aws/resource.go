@@ -5,6 +5,7 @@ import (
 	"io"
 	"sort"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/manifest"
 	"github.com/convox/praxis/types"
@@ -22,7 +23,7 @@ func (p *Provider) ResourceGet(app, name string) (*types.Resource, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("resource not found: %s", name)
+	return nil, api.Errorf(404, "no such resource: %s", name)
 }
 
 func (p *Provider) ResourceList(app string) (types.Resources, error) {
@@ -51,6 +52,26 @@ func (p *Provider) ResourceProxy(app, resource string, in io.Reader) (io.ReadClo
 	return nil, fmt.Errorf("unimplemented")
 }
 
+func (p *Provider) SystemResourceCreate(name, kind string) (*types.Resource, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceDelete(name string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceGet(name string) (*types.Resource, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceList() (types.Resources, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceProxy(name string, in io.Reader) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
 func (p *Provider) resourceFromManifest(app string, r manifest.Resource) (*types.Resource, error) {
 	stack, err := p.appResource(app, fmt.Sprintf("Resource%s", upperName(r.Name)))
 	if err != nil {
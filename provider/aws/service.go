@@ -3,6 +3,7 @@ package aws
 import (
 	"fmt"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 )
@@ -19,7 +20,7 @@ func (p *Provider) ServiceGet(app, name string) (*types.Service, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("service not found: %s", name)
+	return nil, api.Errorf(404, "no such service: %s", name)
 }
 
 func (p *Provider) ServiceList(app string) (types.Services, error) {
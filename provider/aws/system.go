@@ -91,7 +91,7 @@ func (p *Provider) SystemLogs(opts types.LogsOptions) (io.ReadCloser, error) {
 
 	r, w := io.Pipe()
 
-	go p.subscribeLogs(group, "", opts, w)
+	go p.subscribeLogs(p.Name, group, "", opts, w)
 
 	return r, nil
 }
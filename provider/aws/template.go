@@ -23,11 +23,20 @@ func formationHelpers() template.FuncMap {
 			}
 			return domain
 		},
+		"domainPriority": func(app, service, domain, path string) uint32 {
+			// path rules take precedence over host rules for the same domain
+			p := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s-%s-%s-%s", app, service, domain, path))) % 15000
+			if path == "" {
+				return 15001 + p
+			}
+			return 1 + p
+		},
 		"lower": func(s string) string {
 			return strings.ToLower(s)
 		},
 		"priority": func(app, service string) uint32 {
-			return crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s-%s", app, service))) % 50000
+			// above the range of domain rules so that they can never collide
+			return 30001 + crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s-%s", app, service)))%20000
 		},
 		"resource": func(s string) string {
 			return upperName(s)
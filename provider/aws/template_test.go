@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainPriority(t *testing.T) {
+	h := formationHelpers()
+
+	domainPriority := h["domainPriority"].(func(app, service, domain, path string) uint32)
+	priority := h["priority"].(func(app, service string) uint32)
+
+	host := domainPriority("app", "web", "example.org", "")
+	api := domainPriority("app", "web", "example.org", "/api")
+	admin := domainPriority("app", "web", "example.org", "/admin")
+
+	assert.NotEqual(t, api, admin)
+	assert.True(t, api < host)
+	assert.True(t, admin < host)
+
+	for _, service := range []string{"web", "api", "worker", "admin", "other"} {
+		p := priority("app", service)
+
+		assert.True(t, p > 30000 && p <= 50000)
+		assert.True(t, domainPriority("app", service, "example.org", "") <= 30000)
+		assert.True(t, domainPriority("app", service, "example.org", "/path") <= 15000)
+	}
+}
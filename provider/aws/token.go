@@ -0,0 +1,23 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) TokenCreate(name string, opts types.TokenCreateOptions) (*types.Token, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) TokenDelete(id string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) TokenGet(id string) (*types.Token, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) TokenList() (types.Tokens, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
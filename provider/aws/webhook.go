@@ -0,0 +1,19 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) WebhookCreate(url string, opts types.WebhookCreateOptions) (*types.Webhook, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) WebhookDelete(id string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) WebhookList() (types.Webhooks, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
@@ -0,0 +1,301 @@
+// Package conformance checks that a types.Provider behaves the way the rack api expects
+//
+// every provider runs the same checks so that lifecycles, error semantics, pagination and
+// streaming do not drift between implementations
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Suite describes how to run the checks against a provider
+type Suite struct {
+	// Provider returns a provider with no apps for the named check and a function to clean it up
+	Provider func(check string) (types.Provider, func(), error)
+
+	// Skip lists checks the provider can not run in this environment, with the reason
+	Skip map[string]string
+}
+
+type check struct {
+	name string
+	fn   func(*testing.T, types.Provider)
+}
+
+var checks = []check{
+	{"AppCreate", checkAppCreate},
+	{"AppCreateConflict", checkAppCreateConflict},
+	{"AppDelete", checkAppDelete},
+	{"AppDeleteNotFound", checkAppDeleteNotFound},
+	{"AppGetNotFound", checkAppGetNotFound},
+	{"AppList", checkAppList},
+	{"BuildGetNotFound", checkBuildGetNotFound},
+	{"ObjectFetchNotFound", checkObjectFetchNotFound},
+	{"ObjectStream", checkObjectStream},
+	{"ProcessGetNotFound", checkProcessGetNotFound},
+	{"QueueRoundtrip", checkQueueRoundtrip},
+	{"ReleaseCreate", checkReleaseCreate},
+	{"ReleaseGetNotFound", checkReleaseGetNotFound},
+	{"ReleaseList", checkReleaseList},
+	{"ReleasePromoteWithoutBuild", checkReleasePromoteWithoutBuild},
+}
+
+// Checks returns the names of every check in the order they run
+func Checks() []string {
+	names := make([]string, len(checks))
+
+	for i, c := range checks {
+		names[i] = c.name
+	}
+
+	return names
+}
+
+// Run runs every check as a subtest of t
+func (s Suite) Run(t *testing.T) {
+	for _, c := range checks {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			if reason, ok := s.Skip[c.name]; ok {
+				t.Skip(reason)
+			}
+
+			p, cleanup, err := s.Provider(c.name)
+			require.NoError(t, err)
+
+			if cleanup != nil {
+				defer cleanup()
+			}
+
+			c.fn(t, p)
+		})
+	}
+}
+
+// statusCode returns the http status an error is rendered with by the rack api
+func statusCode(err error) int {
+	if sc, ok := errors.Cause(err).(interface {
+		StatusCode() int
+	}); ok {
+		return sc.StatusCode()
+	}
+
+	return 0
+}
+
+func assertStatus(t *testing.T, err error, code int, message string) {
+	if assert.Error(t, err) {
+		assert.Equal(t, message, err.Error())
+		assert.Equal(t, code, statusCode(err), "status for %q", err.Error())
+	}
+}
+
+func checkAppCreate(t *testing.T, p types.Provider) {
+	app, err := p.AppCreate("app1")
+	require.NoError(t, err)
+	assert.Equal(t, "app1", app.Name)
+	assert.NotEmpty(t, app.Status)
+
+	app, err = p.AppGet("app1")
+	require.NoError(t, err)
+	assert.Equal(t, "app1", app.Name)
+}
+
+func checkAppCreateConflict(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	_, err = p.AppCreate("app1")
+	assertStatus(t, err, http.StatusConflict, "app already exists: app1")
+}
+
+func checkAppDelete(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	require.NoError(t, p.AppDelete("app1"))
+
+	_, err = p.AppGet("app1")
+	assertStatus(t, err, http.StatusNotFound, "no such app: app1")
+}
+
+func checkAppDeleteNotFound(t *testing.T, p types.Provider) {
+	err := p.AppDelete("app1")
+	assertStatus(t, err, http.StatusNotFound, "no such app: app1")
+}
+
+func checkAppGetNotFound(t *testing.T, p types.Provider) {
+	_, err := p.AppGet("app1")
+	assertStatus(t, err, http.StatusNotFound, "no such app: app1")
+}
+
+func checkAppList(t *testing.T, p types.Provider) {
+	apps, err := p.AppList()
+	require.NoError(t, err)
+	assert.Len(t, apps, 0)
+
+	for _, name := range []string{"app2", "app1"} {
+		_, err := p.AppCreate(name)
+		require.NoError(t, err)
+	}
+
+	apps, err = p.AppList()
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	assert.Equal(t, "app1", apps[0].Name)
+	assert.Equal(t, "app2", apps[1].Name)
+}
+
+func checkBuildGetNotFound(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	_, err = p.BuildGet("app1", "B123456789")
+	assertStatus(t, err, http.StatusNotFound, "no such build: B123456789")
+}
+
+func checkObjectFetchNotFound(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	exists, err := p.ObjectExists("app1", "missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = p.ObjectFetch("app1", "missing")
+	assertStatus(t, err, http.StatusNotFound, "no such key: missing")
+}
+
+// checkObjectStream stores an object larger than any single read buffer and reads it back
+// the data is the same on every run so that recorded providers can replay it
+func checkObjectStream(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	data := make([]byte, 64*1024)
+
+	_, err = rand.New(rand.NewSource(1)).Read(data)
+	require.NoError(t, err)
+
+	o, err := p.ObjectStore("app1", "data/object", bytes.NewReader(data), types.ObjectStoreOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "data/object", o.Key)
+
+	exists, err := p.ObjectExists("app1", "data/object")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	r, err := p.ObjectFetch("app1", "data/object")
+	require.NoError(t, err)
+	defer r.Close()
+
+	fetched, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, fetched), "fetched object does not match")
+}
+
+func checkProcessGetNotFound(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	_, err = p.ProcessGet("app1", "missing")
+	assertStatus(t, err, http.StatusNotFound, "no such process: missing")
+}
+
+func checkQueueRoundtrip(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, p.QueueStore("app1", "conformance", map[string]string{"n": fmt.Sprintf("%d", i)}))
+	}
+
+	for i := 1; i <= 2; i++ {
+		attrs, err := p.QueueFetch("app1", "conformance", types.QueueFetchOptions{Timeout: 1})
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%d", i), attrs["n"])
+	}
+
+	attrs, err := p.QueueFetch("app1", "conformance", types.QueueFetchOptions{Timeout: 1})
+	require.NoError(t, err)
+	assert.Nil(t, attrs)
+}
+
+func checkReleaseCreate(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	r1, err := p.ReleaseCreate("app1", types.ReleaseCreateOptions{Build: "B123456789", Env: map[string]string{"FOO": "bar"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, r1.Id)
+	assert.Equal(t, "app1", r1.App)
+
+	r, err := p.ReleaseGet("app1", r1.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "B123456789", r.Build)
+	assert.Equal(t, "bar", r.Env["FOO"])
+
+	// a new release forks the latest one
+	r2, err := p.ReleaseCreate("app1", types.ReleaseCreateOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, r1.Id, r2.Id)
+	assert.Equal(t, "B123456789", r2.Build)
+	assert.Equal(t, "bar", r2.Env["FOO"])
+}
+
+func checkReleaseGetNotFound(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	_, err = p.ReleaseGet("app1", "R123456789")
+	assertStatus(t, err, http.StatusNotFound, "no such release: R123456789")
+}
+
+// checkReleaseList checks that releases are listed newest first, 10 at a time unless a count is given
+func checkReleaseList(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	ids := []string{}
+
+	for i := 0; i < 12; i++ {
+		r, err := p.ReleaseCreate("app1", types.ReleaseCreateOptions{})
+		require.NoError(t, err)
+		ids = append([]string{r.Id}, ids...)
+	}
+
+	rs, err := p.ReleaseList("app1", types.ReleaseListOptions{})
+	require.NoError(t, err)
+	require.Len(t, rs, 10)
+
+	for i, r := range rs {
+		assert.Equal(t, ids[i], r.Id)
+	}
+
+	rs, err = p.ReleaseList("app1", types.ReleaseListOptions{Count: 3})
+	require.NoError(t, err)
+	require.Len(t, rs, 3)
+	assert.Equal(t, ids[0], rs[0].Id)
+}
+
+func checkReleasePromoteWithoutBuild(t *testing.T, p types.Provider) {
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	r, err := p.ReleaseCreate("app1", types.ReleaseCreateOptions{})
+	require.NoError(t, err)
+
+	err = p.ReleasePromote("app1", r.Id)
+	assert.EqualError(t, err, fmt.Sprintf("no build for release: %s", r.Id))
+}
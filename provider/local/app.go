@@ -16,13 +16,14 @@ import (
 
 const (
 	AppCacheDuration = 5 * time.Minute
+	AppLogsRefresh   = 2 * time.Second
 )
 
 func (p *Provider) AppCreate(name string) (*types.App, error) {
 	log := p.logger("AppCreate").Append("name=%q", name)
 
 	if p.storageExists(fmt.Sprintf("apps/%s/app.json", name)) {
-		return nil, log.Error(fmt.Errorf("app already exists: %s", name))
+		return nil, log.Error(api.Errorf(409, "app already exists: %s", name))
 	}
 
 	app := &types.App{
@@ -34,6 +35,8 @@ func (p *Provider) AppCreate(name string) (*types.App, error) {
 		return nil, errors.WithStack(log.Error(err))
 	}
 
+	p.event("app:create", name, nil, nil)
+
 	return app, log.Success()
 }
 
@@ -55,10 +58,16 @@ func (p *Provider) AppDelete(app string) error {
 		}
 	}
 
+	if err := p.appTokenRevoke(app); err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
 	if err := p.storageDeleteAll(fmt.Sprintf("apps/%s", app)); err != nil {
 		return errors.WithStack(log.Error(err))
 	}
 
+	p.event("app:delete", app, nil, nil)
+
 	return log.Success()
 }
 
@@ -109,31 +118,93 @@ func (p *Provider) AppLogs(app string, opts types.LogsOptions) (io.ReadCloser, e
 		return nil, log.Error(err)
 	}
 
-	pss, err := p.ProcessList(app, types.ProcessListOptions{})
+	opened := time.Now().UTC()
+
+	r, w := io.Pipe()
+
+	lw, err := helpers.NewLogWriter(w, opts)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	filters := []string{
+		fmt.Sprintf("label=convox.app=%s", app),
+		fmt.Sprintf("label=convox.rack=%s", p.Name),
+	}
+
+	// when resuming from a time include processes that have exited since then
+	pss, err := processList(filters, !lw.Since().IsZero())
 	if err != nil {
 		return nil, errors.WithStack(log.Error(err))
 	}
 
-	r, w := io.Pipe()
+	lr := newLogReader(r)
 
 	var wg sync.WaitGroup
 
-	for _, ps := range pss {
+	seen := map[string]bool{}
+
+	follow := func(ps types.Process, since time.Time) {
+		seen[ps.Id] = true
 		wg.Add(1)
-		go func(ps types.Process, wg *sync.WaitGroup) {
+
+		go func() {
 			defer wg.Done()
-			if pr, err := p.ProcessLogs(app, ps.Id, opts); err == nil {
-				helpers.Stream(w, pr)
-			}
-		}(ps, &wg)
+
+			dockerLogs(ps.Id, opts.Follow, since, func(ts time.Time, stream, line string) error {
+				return lw.Entry(processLogEntry(ps, ts, stream, line))
+			})
+		}()
+	}
+
+	for _, ps := range pss {
+		follow(ps, lw.Since())
 	}
 
+	if !opts.Follow {
+		go func() {
+			wg.Wait()
+			w.Close()
+		}()
+
+		return lr, log.Success()
+	}
+
+	// pick up processes started after the stream was opened until the reader goes away
+	// exited containers are included so that short-lived processes are not missed
 	go func() {
-		wg.Wait()
-		w.Close()
+		defer w.Close()
+
+		for {
+			select {
+			case <-lr.closed:
+				return
+			case <-time.After(AppLogsRefresh):
+			}
+
+			pss, err := processList(filters, true)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+
+			for _, ps := range pss {
+				if seen[ps.Id] {
+					continue
+				}
+
+				since := lw.Since()
+
+				if since.Before(opened) {
+					since = opened
+				}
+
+				follow(ps, since)
+			}
+		}
 	}()
 
-	return r, log.Success()
+	return lr, log.Success()
 }
 
 func (p *Provider) AppRegistry(app string) (*types.Registry, error) {
@@ -0,0 +1,115 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	AuditRetention = 30 * 24 * time.Hour
+)
+
+func (p *Provider) SystemAudit(opts types.SystemAuditOptions) (types.AuditEvents, error) {
+	log := p.logger("SystemAudit").Append("actor=%q app=%q limit=%d since=%q", opts.Actor, opts.App, opts.Limit, opts.Since)
+
+	keys, err := p.storageList("system/audit")
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	events := types.AuditEvents{}
+
+	// keys sort oldest first, walk backwards to return the newest events
+	for i := len(keys) - 1; i >= 0; i-- {
+		if opts.Limit > 0 && len(events) >= opts.Limit {
+			break
+		}
+
+		at, err := auditKeyTime(keys[i])
+		if err != nil {
+			continue
+		}
+
+		if !opts.Since.IsZero() && at.Before(opts.Since) {
+			break
+		}
+
+		data, err := p.storageRead(fmt.Sprintf("system/audit/%s", keys[i]))
+		if err != nil {
+			return nil, errors.WithStack(log.Error(err))
+		}
+
+		var e types.AuditEvent
+
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, errors.WithStack(log.Error(err))
+		}
+
+		if opts.App != "" && e.App != opts.App {
+			continue
+		}
+
+		if opts.Actor != "" && e.Actor != opts.Actor && e.Token != opts.Actor {
+			continue
+		}
+
+		events = append(events, e)
+	}
+
+	return events, log.Success()
+}
+
+func (p *Provider) SystemAuditRecord(event types.AuditEvent) error {
+	log := p.logger("SystemAuditRecord").Append("id=%q route=%q", event.Id, event.Route)
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	key := fmt.Sprintf("system/audit/%s-%s", event.Time.UTC().Format(sortableTime), event.Id)
+
+	if err := p.storageStore(key, event); err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	return log.Success()
+}
+
+// auditPrune removes audit events older than the retention period
+func (p *Provider) auditPrune() error {
+	keys, err := p.storageList("system/audit")
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().UTC().Add(-1 * AuditRetention)
+
+	for _, k := range keys {
+		at, err := auditKeyTime(k)
+		if err != nil {
+			continue
+		}
+
+		// keys are sorted so the rest are newer
+		if !at.Before(cutoff) {
+			break
+		}
+
+		if err := p.storageDelete(fmt.Sprintf("system/audit/%s", k)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func auditKeyTime(key string) (time.Time, error) {
+	parts := strings.SplitN(key, "-", 2)
+
+	return time.Parse(sortableTime, parts[0])
+}
@@ -0,0 +1,51 @@
+package local_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemAudit(t *testing.T) {
+	p, err := testProvider()
+	require.NoError(t, err)
+	defer testProviderCleanup(p)
+
+	now := time.Now().UTC()
+
+	events := []types.AuditEvent{
+		{Id: "E1", Time: now.Add(-3 * time.Hour), Actor: "rack", Route: "AppCreate", App: "web"},
+		{Id: "E2", Time: now.Add(-2 * time.Hour), Actor: "ci", Token: "TCI", Route: "BuildCreate", App: "web"},
+		{Id: "E3", Time: now.Add(-1 * time.Hour), Actor: "ci", Token: "TCI", Route: "BuildCreate", App: "api"},
+	}
+
+	for _, e := range events {
+		require.NoError(t, p.SystemAuditRecord(e))
+	}
+
+	es, err := p.SystemAudit(types.SystemAuditOptions{})
+	require.NoError(t, err)
+	require.Len(t, es, 3)
+	assert.Equal(t, []string{"E3", "E2", "E1"}, []string{es[0].Id, es[1].Id, es[2].Id})
+
+	es, err = p.SystemAudit(types.SystemAuditOptions{App: "web"})
+	require.NoError(t, err)
+	require.Len(t, es, 2)
+	assert.Equal(t, "E2", es[0].Id)
+
+	es, err = p.SystemAudit(types.SystemAuditOptions{Actor: "TCI"})
+	require.NoError(t, err)
+	assert.Len(t, es, 2)
+
+	es, err = p.SystemAudit(types.SystemAuditOptions{Since: now.Add(-150 * time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, es, 2)
+
+	es, err = p.SystemAudit(types.SystemAuditOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+	assert.Equal(t, "E3", es[0].Id)
+}
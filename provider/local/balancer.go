@@ -0,0 +1,52 @@
+package local
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+)
+
+func (p *Provider) BalancerList(app string) (types.Balancers, error) {
+	log := p.logger("BalancerList").Append("app=%q", app)
+
+	m, _, err := helpers.AppManifest(p, app)
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	bs := types.Balancers{}
+
+	for _, b := range m.Balancers {
+		es := types.BalancerEndpoints{}
+
+		for _, e := range b.Endpoints {
+			port, err := strconv.Atoi(e.Port)
+			if err != nil {
+				return nil, errors.WithStack(log.Error(err))
+			}
+
+			es = append(es, types.BalancerEndpoint{
+				Port:     port,
+				Protocol: coalesce(e.Protocol, "http"),
+				Redirect: e.Redirect,
+				Target:   e.Target,
+			})
+		}
+
+		sort.Slice(es, func(i, j int) bool { return es[i].Port < es[j].Port })
+
+		bs = append(bs, types.Balancer{
+			Name:      b.Name,
+			Endpoint:  fmt.Sprintf("%s.balancer.%s.%s", b.Name, app, p.Name),
+			Endpoints: es,
+		})
+	}
+
+	sort.Slice(bs, func(i, j int) bool { return bs[i].Name < bs[j].Name })
+
+	return bs, log.Success()
+}
@@ -0,0 +1,51 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalancerContainers(t *testing.T) {
+	p := &Provider{Name: "convox", Version: "test"}
+
+	balancers := manifest.Balancers{
+		manifest.Balancer{
+			Name: "main",
+			Endpoints: manifest.BalancerEndpoints{
+				manifest.BalancerEndpoint{Port: "80", Redirect: "https://:443/*"},
+				manifest.BalancerEndpoint{Port: "443", Protocol: "https", Target: "http://web:3000"},
+			},
+		},
+	}
+
+	cs, err := p.balancerContainers(balancers, "app", "R1234")
+	require.NoError(t, err)
+	require.Len(t, cs, 2)
+
+	assert.Equal(t, "convox.app.balancer.main.80", cs[0].Name)
+	assert.Equal(t, "main.balancer.app.convox", cs[0].Hostname)
+	assert.Equal(t, "convox/praxis:test", cs[0].Image)
+	assert.Equal(t, []string{"balancer", "http", "redirect", "https://:443/*"}, cs[0].Command)
+	assert.Equal(t, []containerTarget{{Scheme: "tcp", Port: 80, Target: "tcp://rack/app/process/convox.app.balancer.main.80:3000"}}, cs[0].Targets)
+	assert.Equal(t, "balancer", cs[0].Labels["convox.type"])
+	assert.Equal(t, "http", cs[0].Labels["convox.protocol"])
+	assert.Equal(t, "R1234", cs[0].Labels["convox.release"])
+
+	assert.Equal(t, "convox.app.balancer.main.443", cs[1].Name)
+	assert.Equal(t, []string{"balancer", "https", "target", "http://web:3000"}, cs[1].Command)
+	assert.Equal(t, []containerTarget{{Scheme: "tcp", Port: 443, Target: "tcp://rack/app/process/convox.app.balancer.main.443:3000"}}, cs[1].Targets)
+	assert.Equal(t, "443", cs[1].Labels["convox.port"])
+}
+
+func TestBalancerContainersInvalid(t *testing.T) {
+	p := &Provider{Name: "convox", Version: "test"}
+
+	_, err := p.balancerContainers(manifest.Balancers{{Name: "main", Endpoints: manifest.BalancerEndpoints{{Port: "80"}}}}, "app", "R1234")
+	assert.EqualError(t, err, "invalid balancer endpoint: main:80")
+
+	_, err = p.balancerContainers(manifest.Balancers{{Name: "main", Endpoints: manifest.BalancerEndpoints{{Port: "http", Target: "http://web:3000"}}}}, "app", "R1234")
+	assert.EqualError(t, err, "invalid balancer port: main:http")
+}
@@ -10,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
 )
@@ -90,6 +92,8 @@ func (p *Provider) BuildCreate(app, url string, opts types.BuildCreateOptions) (
 		return nil, errors.WithStack(log.Error(err))
 	}
 
+	p.event("build:create", app, map[string]string{"id": b.Id}, nil)
+
 	return b, log.Successf("id=%s", b.Id)
 }
 
@@ -100,7 +104,7 @@ func (p *Provider) BuildGet(app, id string) (*types.Build, error) {
 
 	if err := p.storageLoad(fmt.Sprintf("apps/%s/builds/%s", app, id), &b, BuildCacheDuration); err != nil {
 		if strings.HasPrefix(err.Error(), "no such key:") {
-			return nil, log.Error(fmt.Errorf("no such build: %s", id))
+			return nil, log.Error(api.Errorf(404, "no such build: %s", id))
 		} else {
 			return nil, errors.WithStack(log.Error(err))
 		}
@@ -133,7 +137,7 @@ func (p *Provider) BuildList(app string) (types.Builds, error) {
 	return builds, log.Success()
 }
 
-func (p *Provider) BuildLogs(app, id string) (io.ReadCloser, error) {
+func (p *Provider) BuildLogs(app, id string, opts types.LogsOptions) (io.ReadCloser, error) {
 	log := p.logger("BuildLogs").Append("app=%q id=%q", app, id)
 
 	build, err := p.BuildGet(app, id)
@@ -143,11 +147,19 @@ func (p *Provider) BuildLogs(app, id string) (io.ReadCloser, error) {
 
 	switch build.Status {
 	case "running":
+		opts.Follow = true
+		opts.Prefix = false
+
 		log.Success()
-		return p.ProcessLogs(app, build.Process, types.LogsOptions{Follow: true, Prefix: false})
+		return p.ProcessLogs(app, build.Process, opts)
 	default:
+		r, err := p.ObjectFetch(app, fmt.Sprintf("convox/builds/%s/log", id))
+		if err != nil {
+			return nil, log.Error(err)
+		}
+
 		log.Success()
-		return p.ObjectFetch(app, fmt.Sprintf("convox/builds/%s/log", id))
+		return helpers.LogStream(r, types.LogEntry{App: app, Service: "build", Process: build.Process, Release: build.Release}, opts)
 	}
 }
 
@@ -186,5 +198,16 @@ func (p *Provider) BuildUpdate(app, id string, opts types.BuildUpdateOptions) (*
 		return nil, errors.WithStack(log.Error(err))
 	}
 
+	switch opts.Status {
+	case "complete", "failed":
+		if !build.Started.IsZero() && !build.Ended.IsZero() {
+			metricBuildDuration.Observe(build.Ended.Sub(build.Started).Seconds(), app, build.Status)
+		}
+	}
+
+	if opts.Status != "" {
+		p.event("build:update", app, map[string]string{"id": id, "release": build.Release, "status": build.Status}, nil)
+	}
+
 	return build, log.Success()
 }
@@ -0,0 +1,25 @@
+package local_test
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/provider/conformance"
+	"github.com/convox/praxis/types"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Suite{
+		Provider: func(check string) (types.Provider, func(), error) {
+			p, err := testProvider()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return p, func() { testProviderCleanup(p) }, nil
+		},
+		Skip: map[string]string{
+			"AppDelete":          "requires docker",
+			"ProcessGetNotFound": "requires docker",
+		},
+	}.Run(t)
+}
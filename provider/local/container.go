@@ -9,11 +9,13 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 type container struct {
+	Aliases  []string
 	Command  []string
 	Env      map[string]string
 	Hostname string
@@ -31,30 +33,90 @@ type containerPort struct {
 	Host      int
 }
 
+// containerTarget is a proxy on the router for a container
+// targets with a Host are registered on that endpoint instead of the container hostname
+// targets with a Path only receive requests under that path prefix
 type containerTarget struct {
+	Host   string
+	Path   string
 	Port   int
 	Scheme string
 	Target string
 }
 
+type routerEndpoint struct {
+	Aliases []string      `json:"aliases,omitempty"`
+	Host    string        `json:"host"`
+	Proxies []routerProxy `json:"proxies"`
+}
+
+type routerProxy struct {
+	Paths   map[string][]string `json:"paths,omitempty"`
+	Port    int                 `json:"port"`
+	Scheme  string              `json:"scheme"`
+	Targets []string            `json:"targets"`
+}
+
 func (p *Provider) containerRegister(c container) error {
 	if p.Router == "none" || c.Hostname == "" {
 		return nil
 	}
 
-	// TODO: remove
-	dt := http.DefaultTransport.(*http.Transport)
-	dt.TLSClientConfig = &tls.Config{
-		InsecureSkipVerify: true,
+	uv := url.Values{}
+
+	for _, a := range c.Aliases {
+		uv.Add("alias", a)
+	}
+
+	if err := p.routerPost(fmt.Sprintf("/endpoints/%s", c.Hostname), uv); err != nil {
+		return err
+	}
+
+	registered := map[string]bool{c.Hostname: true}
+
+	for _, t := range c.Targets {
+		host := t.host(c)
+
+		if !registered[host] {
+			if err := p.routerPost(fmt.Sprintf("/endpoints/%s", host), url.Values{}); err != nil {
+				return err
+			}
+
+			registered[host] = true
+		}
+
+		target, err := t.target(c)
+		if err != nil {
+			return err
+		}
+
+		uv := url.Values{}
+
+		uv.Add("scheme", t.Scheme)
+		uv.Add("target", target)
+
+		if t.Path != "" {
+			uv.Add("path", t.Path)
+		}
+
+		if err := p.routerPost(fmt.Sprintf("/endpoints/%s/proxies/%d", host, t.Port), uv); err != nil {
+			return err
+		}
 	}
 
-	hc := http.Client{Transport: dt}
+	return nil
+}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("https://%s/endpoints/%s", p.Router, c.Hostname), nil)
+func (p *Provider) routerPost(path string, params url.Values) error {
+	req, err := http.NewRequest("POST", fmt.Sprintf("https://%s%s", p.Router, path), bytes.NewReader([]byte(params.Encode())))
 	if err != nil {
 		return err
 	}
 
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	hc := routerClient()
+
 	res, err := hc.Do(req)
 	if err != nil {
 		return err
@@ -62,25 +124,105 @@ func (p *Provider) containerRegister(c container) error {
 
 	defer res.Body.Close()
 
-	for _, t := range c.Targets {
-		uv := url.Values{}
+	return nil
+}
 
-		uv.Add("scheme", t.Scheme)
-		uv.Add("target", t.Target)
+// routerReconcile replaces the endpoints for this rack on the router with those of the running containers
+func (p *Provider) routerReconcile() error {
+	if p.Router == "none" {
+		return nil
+	}
 
-		req, err := http.NewRequest("POST", fmt.Sprintf("https://%s/endpoints/%s/proxies/%d", p.Router, c.Hostname, t.Port), bytes.NewReader([]byte(uv.Encode())))
-		if err != nil {
-			return err
+	cs, err := containersByLabels(map[string]string{
+		"convox.rack": p.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoints := []routerEndpoint{}
+	aliases := map[string][]string{}
+	proxies := map[string]map[int]*routerProxy{}
+
+	endpoint := func(host string) {
+		if _, ok := proxies[host]; !ok {
+			proxies[host] = map[int]*routerProxy{}
+			endpoints = append(endpoints, routerEndpoint{Host: host})
 		}
+	}
 
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range cs {
+		if c.Hostname == "" {
+			continue
+		}
 
-		res, err := hc.Do(req)
-		if err != nil {
-			return err
+		endpoint(c.Hostname)
+
+		if len(c.Aliases) > 0 {
+			aliases[c.Hostname] = c.Aliases
 		}
 
-		defer res.Body.Close()
+		for _, t := range c.Targets {
+			host := t.host(c)
+
+			endpoint(host)
+
+			rp, ok := proxies[host][t.Port]
+			if !ok {
+				rp = &routerProxy{Port: t.Port, Scheme: t.Scheme, Targets: []string{}}
+				proxies[host][t.Port] = rp
+			}
+
+			target, err := t.target(c)
+			if err != nil {
+				return err
+			}
+
+			if t.Path == "" {
+				rp.Targets = append(rp.Targets, target)
+				continue
+			}
+
+			if rp.Paths == nil {
+				rp.Paths = map[string][]string{}
+			}
+
+			rp.Paths[t.Path] = append(rp.Paths[t.Path], target)
+		}
+	}
+
+	for i, e := range endpoints {
+		endpoints[i].Aliases = aliases[e.Host]
+		endpoints[i].Proxies = []routerProxy{}
+
+		for _, rp := range proxies[e.Host] {
+			endpoints[i].Proxies = append(endpoints[i].Proxies, *rp)
+		}
+	}
+
+	data, err := json.Marshal(endpoints)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("https://%s/endpoints?rack=%s", p.Router, p.Name), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	hc := routerClient()
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return fmt.Errorf("router reconcile failed: %s", res.Status)
 	}
 
 	return nil
@@ -116,8 +258,14 @@ func (p *Provider) containerStart(c container, app, release string) (string, err
 		return "", err
 	}
 
+	rack, err := p.rackURL(app, hostname)
+	if err != nil {
+		return "", err
+	}
+
 	args = append(args, "-e", fmt.Sprintf("APP=%s", app))
-	args = append(args, "-e", fmt.Sprintf("RACK_URL=https://%s:3000", hostname))
+	args = append(args, "-e", fmt.Sprintf("RACK_URL=%s", rack))
+	args = append(args, "-e", fmt.Sprintf("RACK_FINGERPRINT=%s", os.Getenv("RACK_FINGERPRINT")))
 	args = append(args, "-e", fmt.Sprintf("RELEASE=%s", release))
 	args = append(args, "--link", hostname)
 
@@ -149,6 +297,48 @@ func (p *Provider) containerStopAsync(id string, wg *sync.WaitGroup) {
 	p.containerStop(id)
 }
 
+// host returns the router endpoint for a target
+func (t containerTarget) host(c container) string {
+	if t.Host != "" {
+		return t.Host
+	}
+
+	return c.Hostname
+}
+
+// target returns the router target for a container target
+// udp targets point at the address of the container as they can not be tunneled through the rack
+func (t containerTarget) target(c container) (string, error) {
+	if t.Scheme != "udp" {
+		return t.Target, nil
+	}
+
+	data, err := exec.Command("docker", "inspect", c.Name, "--format", "{{.NetworkSettings.IPAddress}}").CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+
+	ip := strings.TrimSpace(string(data))
+
+	if ip == "" {
+		return "", fmt.Errorf("no address for container: %s", c.Name)
+	}
+
+	return fmt.Sprintf("udp://%s:%d", ip, t.Port), nil
+}
+
+// routerTransport is kept apart from http.DefaultTransport so that skipping verification
+// of the router's self-signed api certificate does not leak into other clients
+var routerTransport = &http.Transport{
+	TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+	},
+}
+
+func routerClient() http.Client {
+	return http.Client{Transport: routerTransport}
+}
+
 func containerBinding(id string, bind string) (string, error) {
 	data, err := exec.Command("docker", "inspect", "-f", "{{json .HostConfig.PortBindings}}", id).CombinedOutput()
 	if err != nil {
@@ -174,6 +364,15 @@ func containerBinding(id string, bind string) (string, error) {
 	return b[0].HostPort, nil
 }
 
+// labelList splits a comma separated label value
+func labelList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
 func containersByLabels(labels map[string]string) ([]container, error) {
 	args := []string{}
 
@@ -256,12 +455,34 @@ func containerList(args ...string) ([]container, error) {
 		scheme := c.Config.Labels["convox.scheme"]
 		port := c.Config.Labels["convox.port"]
 
-		if app != "" && service != "" && scheme != "" && port != "" {
-			st := fmt.Sprintf("%s://rack/%s/service/%s:%s", scheme, app, service, port)
+		pi, _ := strconv.Atoi(port)
+
+		if app != "" && service != "" && scheme != "" && pi > 0 {
+			cc.Aliases = labelList(c.Config.Labels["convox.aliases"])
+			cc.Targets = serviceTargets(serviceTarget(scheme, app, cc.Name, pi), labelList(c.Config.Labels["convox.routes"]))
+
+			if scheme == "udp" {
+				cc.Targets = udpTargets(pi)
+			}
+		}
 
+		if app != "" && c.Config.Labels["convox.type"] == "balancer" && pi > 0 {
 			cc.Targets = []containerTarget{
-				containerTarget{Scheme: "http", Port: 80, Target: st},
-				containerTarget{Scheme: "https", Port: 443, Target: st},
+				containerTarget{Scheme: "tcp", Port: pi, Target: balancerTarget(app, cc.Name)},
+			}
+		}
+
+		if c.Config.Labels["convox.type"] == "resource" {
+			if rp, err := resourcePort(c.Config.Labels["convox.resource"]); err == nil {
+				rt := fmt.Sprintf("tcp://rack/%s/resource/%s:%d", app, c.Config.Labels["convox.name"], rp)
+
+				if c.Config.Labels["convox.system"] == "true" {
+					rt = fmt.Sprintf("tcp://rack/resource/%s:%d", c.Config.Labels["convox.name"], rp)
+				}
+
+				cc.Targets = []containerTarget{
+					containerTarget{Scheme: "tcp", Port: rp, Target: rt},
+				}
 			}
 		}
 
@@ -7,18 +7,28 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/manifest"
+	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
 )
 
 var convergeLock sync.Mutex
 
-func (p *Provider) converge(app string) error {
+func (p *Provider) converge(app string) (err error) {
 	convergeLock.Lock()
 	defer convergeLock.Unlock()
 
+	defer func(start time.Time) {
+		metricConvergeDuration.Observe(time.Since(start).Seconds(), app)
+
+		if err != nil {
+			metricConvergeErrors.Inc(app)
+		}
+	}(time.Now())
+
 	log := p.logger("converge").Append("app=%q", app)
 
 	m, r, err := helpers.AppManifest(p, app)
@@ -30,12 +40,12 @@ func (p *Provider) converge(app string) error {
 
 	var c []container
 
-	// c, err = p.balancerContainers(m.Balancers, app, r.Id)
-	// if err != nil {
-	//   return errors.WithStack(log.Error(err))
-	// }
+	c, err = p.balancerContainers(m.Balancers, app, r.Id)
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
 
-	// desired = append(desired, c...)
+	desired = append(desired, c...)
 
 	c, err = p.resourceContainers(m.Resources, app, r.Id)
 	if err != nil {
@@ -59,27 +69,63 @@ func (p *Provider) converge(app string) error {
 		return errors.WithStack(log.Error(err))
 	}
 
-	needed := []container{}
+	needed := containersNeeded(desired, current)
 
-	for _, c := range desired {
-		found := false
+	for _, c := range needed {
+		p.storageLogWrite(fmt.Sprintf("apps/%s/releases/%s/log", app, r.Id), []byte(fmt.Sprintf("starting: %s\n", c.Name)))
 
-		for _, d := range current {
-			if reflect.DeepEqual(c.Labels, d.Labels) {
-				found = true
-				break
-			}
+		id, err := p.containerStart(c, app, r.Id)
+		if err != nil {
+			return errors.WithStack(log.Error(err))
 		}
 
-		if !found {
-			needed = append(needed, c)
+		c.Id = id
+
+		if err := p.containerRegister(c); err != nil {
+			return errors.WithStack(log.Error(err))
 		}
 	}
 
-	for _, c := range needed {
-		p.storageLogWrite(fmt.Sprintf("apps/%s/releases/%s/log", app, r.Id), []byte(fmt.Sprintf("starting: %s\n", c.Name)))
+	for _, c := range current {
+		if err := p.containerRegister(c); err != nil {
+			return errors.WithStack(log.Error(err))
+		}
+	}
 
-		id, err := p.containerStart(c, app, r.Id)
+	if len(needed) > 0 {
+		p.event("app:converge", app, map[string]string{"release": r.Id, "started": strconv.Itoa(len(needed))}, nil)
+	}
+
+	return log.Success()
+}
+
+// convergeSystem starts containers for rack-level resources
+func (p *Provider) convergeSystem() error {
+	convergeLock.Lock()
+	defer convergeLock.Unlock()
+
+	log := p.logger("convergeSystem")
+
+	rs, err := p.SystemResourceList()
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	desired, err := p.systemResourceContainers(rs)
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	current, err := containersByLabels(map[string]string{
+		"convox.rack":   p.Name,
+		"convox.system": "true",
+	})
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	for _, c := range containersNeeded(desired, current) {
+		id, err := p.containerStart(c, "", "")
 		if err != nil {
 			return errors.WithStack(log.Error(err))
 		}
@@ -118,6 +164,11 @@ func (p *Provider) prune() error {
 		return errors.WithStack(log.Error(err))
 	}
 
+	rs, err := p.SystemResourceList()
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
 	for _, c := range all {
 		found := false
 
@@ -128,6 +179,15 @@ func (p *Provider) prune() error {
 			}
 		}
 
+		if c.Labels["convox.system"] == "true" {
+			for _, r := range rs {
+				if r.Name == c.Labels["convox.name"] {
+					found = true
+					break
+				}
+			}
+		}
+
 		if !found {
 			log.Successf("action=kill id=%s", c.Id)
 			exec.Command("docker", "stop", c.Id).Run()
@@ -154,97 +214,114 @@ func resourcePort(kind string) (int, error) {
 	return 0, fmt.Errorf("unknown resource type: %s", kind)
 }
 
-func resourceURL(app, kind, name string) (string, error) {
+func resourceURL(kind, host string) (string, error) {
 	switch kind {
 	case "mysql":
-		return fmt.Sprintf("mysql://mysql:password@%s.resource.%s.convox:3306/app", name, app), nil
+		return fmt.Sprintf("mysql://mysql:password@%s:3306/app", host), nil
 	case "postgres":
-		return fmt.Sprintf("postgres://postgres:password@%s.resource.%s.convox:5432/app?sslmode=disable", name, app), nil
+		return fmt.Sprintf("postgres://postgres:password@%s:5432/app?sslmode=disable", host), nil
 	case "redis":
-		return fmt.Sprintf("redis://%s.resource.%s.convox:6379/0", name, app), nil
+		return fmt.Sprintf("redis://%s:6379/0", host), nil
 	case "rabbitmq":
-		return fmt.Sprintf("amqp://guest:guest@%s.resource.%s.convox:5672", name, app), nil
+		return fmt.Sprintf("amqp://guest:guest@%s:5672", host), nil
 	case "elasticsearch":
-		return fmt.Sprintf("https://%s.resource.%s.convox:9200", name, app), nil
+		return fmt.Sprintf("https://%s:9200", host), nil
 	}
 
 	return "", fmt.Errorf("unknown resource type: %s", kind)
 }
 
-func resourceVolumes(app, kind, name string) ([]string, error) {
+func resourceVolumes(dir, kind string) ([]string, error) {
 	switch kind {
 	case "mysql":
-		return []string{fmt.Sprintf("/var/convox/%s/resource/%s:/var/lib/mysql", app, name)}, nil
+		return []string{fmt.Sprintf("%s:/var/lib/mysql", dir)}, nil
 	case "postgres":
-		return []string{fmt.Sprintf("/var/convox/%s/resource/%s:/var/lib/postgresql/data", app, name)}, nil
+		return []string{fmt.Sprintf("%s:/var/lib/postgresql/data", dir)}, nil
 	case "redis":
 		return []string{}, nil
 	case "rabbitmq":
-		return []string{fmt.Sprintf("/var/convox/%s/resource/%s:/var/lib/rabbitmq/data", app, name)}, nil
+		return []string{fmt.Sprintf("%s:/var/lib/rabbitmq/data", dir)}, nil
 	case "elasticsearch":
-		return []string{fmt.Sprintf("/var/convox/%s/resource/%s:/usr/share/elasticsearch/data", app, name)}, nil
+		return []string{fmt.Sprintf("%s:/usr/share/elasticsearch/data", dir)}, nil
 	}
 
 	return []string{}, fmt.Errorf("unknown resource type: %s", kind)
 }
 
-// func (p *Provider) balancerContainers(balancers manifest.Balancers, app, release string) ([]container, error) {
-//   cs := []container{}
-
-//   sys, err := p.SystemGet()
-//   if err != nil {
-//     return nil, err
-//   }
-
-//   for _, b := range balancers {
-//     for _, e := range b.Endpoints {
-//       command := []string{}
-
-//       switch {
-//       case e.Redirect != "":
-//         command = []string{"balancer", e.Protocol, "redirect", e.Redirect}
-//       case e.Target != "":
-//         command = []string{"balancer", e.Protocol, "target", e.Target}
-//       default:
-//         return nil, fmt.Errorf("invalid balancer endpoint: %s:%s", b.Name, e.Port)
-//       }
-
-//       cs = append(cs, container{
-//         Name:     fmt.Sprintf("%s.%s.balancer.%s", p.Name, app, b.Name),
-//         Hostname: fmt.Sprintf("%s.balancer.%s.%s", b.Name, app, p.Name),
-//         Port: containerPort{
-//           Host:      443,
-//           Container: 3000,
-//         },
-//         Memory:  64,
-//         Image:   sys.Image,
-//         Command: command,
-//         Labels: map[string]string{
-//           "convox.rack":    p.Name,
-//           "convox.version": p.Version,
-//           "convox.app":     app,
-//           "convox.release": release,
-//           "convox.type":    "balancer",
-//           "convox.name":    b.Name,
-//           "convox.port":    e.Port,
-//         },
-//       })
-//     }
-//   }
-
-//   return cs, nil
-// }
+func (p *Provider) balancerContainers(balancers manifest.Balancers, app, release string) ([]container, error) {
+	cs := []container{}
+
+	sys, err := p.SystemGet()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range balancers {
+		hostname := fmt.Sprintf("%s.balancer.%s.%s", b.Name, app, p.Name)
+
+		for _, e := range b.Endpoints {
+			command := []string{}
+
+			protocol := coalesce(e.Protocol, "http")
+
+			switch {
+			case e.Redirect != "":
+				command = []string{"balancer", protocol, "redirect", e.Redirect}
+			case e.Target != "":
+				command = []string{"balancer", protocol, "target", e.Target}
+			default:
+				return nil, fmt.Errorf("invalid balancer endpoint: %s:%s", b.Name, e.Port)
+			}
+
+			port, err := strconv.Atoi(e.Port)
+			if err != nil {
+				return nil, fmt.Errorf("invalid balancer port: %s:%s", b.Name, e.Port)
+			}
+
+			name := fmt.Sprintf("%s.%s.balancer.%s.%d", p.Name, app, b.Name, port)
+
+			cs = append(cs, container{
+				Name:     name,
+				Hostname: hostname,
+				Targets: []containerTarget{
+					containerTarget{Scheme: "tcp", Port: port, Target: balancerTarget(app, name)},
+				},
+				Memory:  64,
+				Image:   sys.Image,
+				Command: command,
+				Labels: map[string]string{
+					"convox.rack":     p.Name,
+					"convox.version":  p.Version,
+					"convox.app":      app,
+					"convox.release":  release,
+					"convox.type":     "balancer",
+					"convox.name":     b.Name,
+					"convox.hostname": hostname,
+					"convox.port":     e.Port,
+					"convox.protocol": protocol,
+				},
+			})
+		}
+	}
+
+	return cs, nil
+}
 
 func (p *Provider) resourceContainers(resources manifest.Resources, app, release string) ([]container, error) {
 	cs := []container{}
 
 	for _, r := range resources {
+		// shared resources run at the rack level
+		if r.Shared() {
+			continue
+		}
+
 		rp, err := resourcePort(r.Type)
 		if err != nil {
 			return nil, err
 		}
 
-		vs, err := resourceVolumes(app, r.Type, r.Name)
+		vs, err := resourceVolumes(fmt.Sprintf("/var/convox/%s/resource/%s", app, r.Name), r.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -275,6 +352,45 @@ func (p *Provider) resourceContainers(resources manifest.Resources, app, release
 	return cs, nil
 }
 
+func (p *Provider) systemResourceContainers(resources types.Resources) ([]container, error) {
+	cs := []container{}
+
+	for _, r := range resources {
+		rp, err := resourcePort(r.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		vs, err := resourceVolumes(fmt.Sprintf("/var/convox/system/resources/%s", r.Name), r.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		hostname := fmt.Sprintf("%s.resource.%s", r.Name, p.Name)
+
+		cs = append(cs, container{
+			Name:     fmt.Sprintf("%s.resource.%s", p.Name, r.Name),
+			Hostname: hostname,
+			Targets: []containerTarget{
+				containerTarget{Scheme: "tcp", Port: rp, Target: fmt.Sprintf("tcp://rack/resource/%s:%d", r.Name, rp)},
+			},
+			Image:   fmt.Sprintf("convox/%s", r.Type),
+			Volumes: vs,
+			Labels: map[string]string{
+				"convox.rack":     p.Name,
+				"convox.version":  p.Version,
+				"convox.system":   "true",
+				"convox.type":     "resource",
+				"convox.name":     r.Name,
+				"convox.hostname": hostname,
+				"convox.resource": r.Type,
+			},
+		})
+	}
+
+	return cs, nil
+}
+
 func (p *Provider) serviceContainers(services manifest.Services, app, release string) ([]container, error) {
 	cs := []container{}
 
@@ -306,33 +422,42 @@ func (p *Provider) serviceContainers(services manifest.Services, app, release st
 		for _, sr := range s.Resources {
 			for _, r := range m.Resources {
 				if r.Name == sr {
-					u, err := resourceURL(app, r.Type, r.Name)
+					rr, err := p.resourceFromManifest(app, r)
 					if err != nil {
 						return nil, err
 					}
 
-					e[fmt.Sprintf("%s_URL", strings.ToUpper(sr))] = u
+					e[fmt.Sprintf("%s_URL", strings.ToUpper(sr))] = rr.Endpoint
 				}
 			}
 		}
 
-		st := fmt.Sprintf("%s://rack/%s/service/%s:%d", s.Port.Scheme, app, s.Name, s.Port.Port)
-
 		hostname := fmt.Sprintf("%s.%s.%s", s.Name, app, p.Name)
 
+		aliases, routes := serviceRoutes(services, s, app, p.Name)
+
 		for i := 1; i <= s.Scale.Count.Min; i++ {
+			name := fmt.Sprintf("%s.%s.service.%s.%d", p.Name, app, s.Name, i)
+
+			// register each replica so the router balances across all of them
+			st := serviceTarget(s.Port.Scheme, app, name, s.Port.Port)
+
+			targets := serviceTargets(st, routes)
+
+			if s.Port.Scheme == "udp" {
+				targets = udpTargets(s.Port.Port)
+			}
+
 			cs = append(cs, container{
+				Aliases:  aliases,
 				Hostname: hostname,
-				Targets: []containerTarget{
-					containerTarget{Scheme: "http", Port: 80, Target: st},
-					containerTarget{Scheme: "https", Port: 443, Target: st},
-				},
-				Name:    fmt.Sprintf("%s.%s.service.%s.%d", p.Name, app, s.Name, i),
-				Image:   fmt.Sprintf("%s/%s/%s:%s", p.Name, app, s.Name, r.Build),
-				Command: cmd,
-				Env:     e,
-				Memory:  s.Scale.Memory,
-				Volumes: s.Volumes,
+				Targets:  targets,
+				Name:     name,
+				Image:    fmt.Sprintf("%s/%s/%s:%s", p.Name, app, s.Name, r.Build),
+				Command:  cmd,
+				Env:      e,
+				Memory:   s.Scale.Memory,
+				Volumes:  s.Volumes,
 				Labels: map[string]string{
 					"convox.rack":     p.Name,
 					"convox.version":  p.Version,
@@ -345,6 +470,8 @@ func (p *Provider) serviceContainers(services manifest.Services, app, release st
 					"convox.index":    fmt.Sprintf("%d", i),
 					"convox.port":     strconv.Itoa(s.Port.Port),
 					"convox.scheme":   s.Port.Scheme,
+					"convox.aliases":  strings.Join(aliases, ","),
+					"convox.routes":   strings.Join(routes, ","),
 				},
 			})
 		}
@@ -352,3 +479,98 @@ func (p *Provider) serviceContainers(services manifest.Services, app, release st
 
 	return cs, nil
 }
+
+// balancerTarget routes traffic through the rack to port 3000 of a balancer container
+func balancerTarget(app, container string) string {
+	return fmt.Sprintf("tcp://rack/%s/process/%s:3000", app, container)
+}
+
+// serviceTarget routes traffic through the rack to a single service container
+func serviceTarget(scheme, app, container string, port int) string {
+	return fmt.Sprintf("%s://rack/%s/process/%s:%d", scheme, app, container, port)
+}
+
+// udpTargets routes a udp port straight to a service container as datagrams can not be tunneled through the rack
+// the container address is filled in when the target is registered
+func udpTargets(port int) []containerTarget {
+	return []containerTarget{
+		containerTarget{Scheme: "udp", Port: port},
+	}
+}
+
+// serviceRoutes returns the domains that a service answers on directly and the host/path
+// routes for its path prefix, mirroring the listener rules used in production
+// a path prefix is routed on the endpoint of the service that owns the domain without a path
+func serviceRoutes(services manifest.Services, s manifest.Service, app, rack string) ([]string, []string) {
+	aliases := []string{}
+	routes := []string{}
+
+	for _, d := range s.Domains {
+		owner := ""
+
+		for _, o := range services {
+			if o.Path == "" && containsString(o.Domains, d) {
+				owner = o.Name
+				break
+			}
+		}
+
+		switch {
+		case s.Path == "" && owner == s.Name:
+			aliases = append(aliases, d)
+		case s.Path != "" && owner != "":
+			routes = append(routes, fmt.Sprintf("%s.%s.%s%s", owner, app, rack, s.Path))
+		case s.Path != "":
+			routes = append(routes, fmt.Sprintf("%s%s", d, s.Path))
+		}
+	}
+
+	return aliases, routes
+}
+
+// serviceTargets returns the router targets for a service container and its routes
+func serviceTargets(target string, routes []string) []containerTarget {
+	ts := []containerTarget{
+		containerTarget{Scheme: "http", Port: 80, Target: target},
+		containerTarget{Scheme: "https", Port: 443, Target: target},
+	}
+
+	for _, r := range routes {
+		parts := strings.SplitN(r, "/", 2)
+
+		if len(parts) != 2 {
+			continue
+		}
+
+		path := fmt.Sprintf("/%s", parts[1])
+
+		ts = append(ts,
+			containerTarget{Host: parts[0], Path: path, Scheme: "http", Port: 80, Target: target},
+			containerTarget{Host: parts[0], Path: path, Scheme: "https", Port: 443, Target: target},
+		)
+	}
+
+	return ts
+}
+
+// containersNeeded returns the desired containers that have no running counterpart
+func containersNeeded(desired, current []container) []container {
+	needed := []container{}
+
+	for _, c := range desired {
+		found := false
+
+		for _, d := range current {
+			if reflect.DeepEqual(c.Labels, d.Labels) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			needed = append(needed, c)
+		}
+	}
+
+	return needed
+}
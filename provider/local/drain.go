@@ -0,0 +1,225 @@
+package local
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/drain"
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DrainCacheDuration = 1 * time.Minute
+	DrainReconnect     = 5 * time.Second
+)
+
+// drainWorkers tracks the running drains, closing the channel of a drain stops it
+type drainWorkers struct {
+	lock  sync.Mutex
+	stops map[string]chan struct{}
+}
+
+func (p *Provider) DrainCreate(app, u string) (*types.Drain, error) {
+	log := p.logger("DrainCreate").Append("app=%q url=%q", app, u)
+
+	if _, err := p.AppGet(app); err != nil {
+		return nil, log.Error(err)
+	}
+
+	if _, err := types.ParseDrainUrl(u); err != nil {
+		return nil, log.Error(err)
+	}
+
+	d := &types.Drain{
+		Id:      types.Id("D", 10),
+		App:     app,
+		Url:     u,
+		Created: time.Now().UTC(),
+	}
+
+	if err := p.storageStore(fmt.Sprintf("apps/%s/drains/%s", app, d.Id), d); err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	return d, log.Successf("id=%s", d.Id)
+}
+
+func (p *Provider) DrainDelete(app, id string) error {
+	log := p.logger("DrainDelete").Append("app=%q id=%q", app, id)
+
+	key := fmt.Sprintf("apps/%s/drains/%s", app, id)
+
+	if !p.storageExists(key) {
+		return log.Error(api.Errorf(404, "no such drain: %s", id))
+	}
+
+	if err := p.storageDelete(key); err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	return log.Success()
+}
+
+func (p *Provider) DrainList(app string) (types.Drains, error) {
+	log := p.logger("DrainList").Append("app=%q", app)
+
+	if _, err := p.AppGet(app); err != nil {
+		return nil, log.Error(err)
+	}
+
+	ids, err := p.storageList(fmt.Sprintf("apps/%s/drains", app))
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	drains := make(types.Drains, len(ids))
+
+	for i, id := range ids {
+		var d types.Drain
+
+		if err := p.storageLoad(fmt.Sprintf("apps/%s/drains/%s", app, id), &d, DrainCacheDuration); err != nil {
+			return nil, errors.WithStack(log.Error(err))
+		}
+
+		drains[i] = d
+	}
+
+	sort.Slice(drains, func(i, j int) bool { return drains[i].Created.Before(drains[j].Created) })
+
+	return drains, log.Success()
+}
+
+// convergeDrains starts a forwarder for each configured drain and stops those of drains that were removed
+func (p *Provider) convergeDrains() error {
+	apps, err := p.AppList()
+	if err != nil {
+		return err
+	}
+
+	drains := map[string]types.Drain{}
+
+	for _, a := range apps {
+		ds, err := p.DrainList(a.Name)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range ds {
+			drains[d.Id] = d
+		}
+	}
+
+	p.drains.lock.Lock()
+	defer p.drains.lock.Unlock()
+
+	for id, stop := range p.drains.stops {
+		if _, ok := drains[id]; !ok {
+			close(stop)
+			delete(p.drains.stops, id)
+		}
+	}
+
+	for id, d := range drains {
+		if _, ok := p.drains.stops[id]; !ok {
+			stop := make(chan struct{})
+			p.drains.stops[id] = stop
+			go p.drainRun(d, stop)
+		}
+	}
+
+	return nil
+}
+
+// drainRun follows the logs of an app into a drain until stopped
+// the stream is reopened from the cursor of the last entry whenever it ends so that nothing is sent twice
+func (p *Provider) drainRun(d types.Drain, stop chan struct{}) {
+	log := p.logger("drainRun").Append("app=%q id=%q", d.App, d.Id)
+
+	sink, err := drain.New(d.Url, nil)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	f := drain.NewForwarder(sink, drain.ForwarderOptions{
+		Errors: func(err error) { log.Error(err) },
+	})
+
+	go func() {
+		<-stop
+		f.Close()
+	}()
+
+	opts := types.LogsOptions{
+		Cursors: true,
+		Follow:  true,
+		Format:  types.LogFormatJSON,
+		Since:   time.Now().UTC(),
+	}
+
+	for {
+		if err := p.drainCopy(d, f, &opts, stop); err == drain.ErrClosed {
+			log.Success()
+			return
+		} else if err != nil {
+			log.Error(err)
+		}
+
+		select {
+		case <-stop:
+			log.Success()
+			return
+		case <-time.After(DrainReconnect):
+		}
+	}
+}
+
+// drainCopy forwards entries from one log stream, advancing opts.Cursor past each
+func (p *Provider) drainCopy(d types.Drain, f *drain.Forwarder, opts *types.LogsOptions, stop chan struct{}) error {
+	r, err := p.AppLogs(d.App, *opts)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+		r.Close()
+	}()
+
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		parts := strings.SplitN(s.Text(), " ", 2)
+
+		if len(parts) != 2 {
+			continue
+		}
+
+		var e types.LogEntry
+
+		if err := json.Unmarshal([]byte(parts[1]), &e); err != nil {
+			return err
+		}
+
+		if err := f.Forward(e); err != nil {
+			return err
+		}
+
+		opts.Cursor = parts[0]
+	}
+
+	return s.Err()
+}
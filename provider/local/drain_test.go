@@ -0,0 +1,50 @@
+package local_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainCreateListDelete(t *testing.T) {
+	p, err := testProvider()
+	require.NoError(t, err)
+	defer testProviderCleanup(p)
+
+	_, err = p.AppCreate("test")
+	require.NoError(t, err)
+
+	d, err := p.DrainCreate("test", "syslog+tls://logs.example.org:6514")
+	require.NoError(t, err)
+	assert.Equal(t, "test", d.App)
+	assert.Equal(t, "syslog+tls://logs.example.org:6514", d.Url)
+
+	ds, err := p.DrainList("test")
+	require.NoError(t, err)
+	require.Len(t, ds, 1)
+	assert.Equal(t, d.Id, ds[0].Id)
+
+	require.NoError(t, p.DrainDelete("test", d.Id))
+
+	ds, err = p.DrainList("test")
+	require.NoError(t, err)
+	assert.Len(t, ds, 0)
+
+	assert.EqualError(t, p.DrainDelete("test", d.Id), "no such drain: "+d.Id)
+}
+
+func TestDrainCreateInvalid(t *testing.T) {
+	p, err := testProvider()
+	require.NoError(t, err)
+	defer testProviderCleanup(p)
+
+	_, err = p.DrainCreate("missing", "https://logs.example.org")
+	assert.EqualError(t, err, "no such app: missing")
+
+	_, err = p.AppCreate("test")
+	require.NoError(t, err)
+
+	_, err = p.DrainCreate("test", "syslog://logs.example.org:514")
+	assert.EqualError(t, err, "invalid drain url: syslog://logs.example.org:514")
+}
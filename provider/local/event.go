@@ -0,0 +1,135 @@
+package local
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	eventBuffer = 100
+)
+
+// eventBus fans events out to the open event streams
+type eventBus struct {
+	lock        sync.Mutex
+	subscribers map[chan types.Event]bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: map[chan types.Event]bool{}}
+}
+
+func (b *eventBus) publish(e types.Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for ch := range b.subscribers {
+		// drop events for subscribers that are not keeping up rather than block the rack
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (b *eventBus) subscribe() chan types.Event {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	ch := make(chan types.Event, eventBuffer)
+
+	b.subscribers[ch] = true
+
+	return ch
+}
+
+func (b *eventBus) unsubscribe(ch chan types.Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.subscribers, ch)
+}
+
+func (p *Provider) EventSend(action string, opts types.EventSendOptions) error {
+	log := p.logger("EventSend").Append("action=%q app=%q", action, opts.App)
+
+	e := types.Event{
+		Id:        types.Id("E", 10),
+		Action:    action,
+		App:       opts.App,
+		Data:      opts.Data,
+		Error:     opts.Error,
+		Status:    "success",
+		Timestamp: time.Now().UTC(),
+	}
+
+	if e.Error != "" {
+		e.Status = "error"
+	}
+
+	p.events.publish(e)
+
+	hooks, err := p.WebhookList()
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	for _, h := range hooks {
+		if e.Match(h.App, h.Actions) {
+			go p.webhookDeliver(h, e)
+		}
+	}
+
+	return log.Successf("id=%s", e.Id)
+}
+
+func (p *Provider) EventStream(opts types.EventStreamOptions) (io.ReadCloser, error) {
+	log := p.logger("EventStream").Append("actions=%q app=%q", opts.Actions, opts.App)
+
+	ch := p.events.subscribe()
+
+	r, w := io.Pipe()
+
+	go func() {
+		defer w.Close()
+		defer p.events.unsubscribe(ch)
+
+		enc := json.NewEncoder(w)
+
+		for {
+			select {
+			case <-p.Context().Done():
+				return
+			case e := <-ch:
+				if !e.Match(opts.App, opts.Actions) {
+					continue
+				}
+
+				if err := enc.Encode(e); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return r, log.Success()
+}
+
+// event sends an event about an action the rack has taken, failures to send are only logged
+func (p *Provider) event(action, app string, data map[string]string, err error) {
+	opts := types.EventSendOptions{
+		App:  app,
+		Data: data,
+	}
+
+	if err != nil {
+		opts.Error = err.Error()
+	}
+
+	p.EventSend(action, opts)
+}
@@ -0,0 +1,101 @@
+package local_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	p, err := testProvider()
+	require.NoError(t, err)
+	defer testProviderCleanup(p)
+
+	r, err := p.EventStream(types.EventStreamOptions{App: "web", Actions: []string{"release:*"}})
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.NoError(t, p.EventSend("release:create", types.EventSendOptions{App: "api"}))
+	require.NoError(t, p.EventSend("build:create", types.EventSendOptions{App: "web"}))
+	require.NoError(t, p.EventSend("release:promote", types.EventSendOptions{App: "web", Data: map[string]string{"id": "R1"}, Error: "failed"}))
+
+	var e types.Event
+
+	require.NoError(t, json.NewDecoder(r).Decode(&e))
+
+	assert.Equal(t, "release:promote", e.Action)
+	assert.Equal(t, "web", e.App)
+	assert.Equal(t, map[string]string{"id": "R1"}, e.Data)
+	assert.Equal(t, "error", e.Status)
+	assert.Equal(t, "failed", e.Error)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	p, err := testProvider()
+	require.NoError(t, err)
+	defer testProviderCleanup(p)
+
+	type delivery struct {
+		body   []byte
+		header http.Header
+	}
+
+	deliveries := make(chan delivery, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		deliveries <- delivery{body: data, header: r.Header}
+	}))
+	defer ts.Close()
+
+	h, err := p.WebhookCreate(ts.URL, types.WebhookCreateOptions{Actions: []string{"release:promote"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, h.Secret)
+
+	require.NoError(t, p.EventSend("build:create", types.EventSendOptions{App: "web"}))
+	require.NoError(t, p.EventSend("release:promote", types.EventSendOptions{App: "web"}))
+
+	select {
+	case d := <-deliveries:
+		var e types.Event
+		require.NoError(t, json.Unmarshal(d.body, &e))
+		assert.Equal(t, "release:promote", e.Action)
+		assert.Equal(t, "release:promote", d.header.Get("X-Convox-Event"))
+		assert.Equal(t, e.Id, d.header.Get("X-Convox-Delivery"))
+		assert.True(t, h.Verify(d.body, d.header.Get("X-Convox-Signature")))
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+
+	select {
+	case <-deliveries:
+		t.Fatal("unexpected delivery")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	hs, err := p.WebhookList()
+	require.NoError(t, err)
+	require.Len(t, hs, 1)
+
+	require.NoError(t, p.WebhookDelete(h.Id))
+
+	hs, err = p.WebhookList()
+	require.NoError(t, err)
+	assert.Len(t, hs, 0)
+}
+
+func TestWebhookCreateInvalid(t *testing.T) {
+	p, err := testProvider()
+	require.NoError(t, err)
+	defer testProviderCleanup(p)
+
+	_, err = p.WebhookCreate("ftp://example.org", types.WebhookCreateOptions{})
+	assert.EqualError(t, err, "invalid webhook url: ftp://example.org")
+}
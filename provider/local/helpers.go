@@ -40,3 +40,13 @@ func diff(all []string, remove []string) []string {
 
 	return f
 }
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
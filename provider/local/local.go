@@ -38,8 +38,10 @@ type Provider struct {
 	Test    bool
 	Version string
 
-	ctx context.Context
-	db  *bolt.DB
+	ctx    context.Context
+	db     *bolt.DB
+	drains *drainWorkers
+	events *eventBus
 }
 
 func FromEnv() (*Provider, error) {
@@ -82,6 +84,9 @@ func (p *Provider) Init() error {
 
 	p.db = db
 
+	p.drains = &drainWorkers{stops: map[string]chan struct{}{}}
+	p.events = newEventBus()
+
 	if _, err := p.createRootBucket("rack"); err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+package local
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+// dockerLogs calls fn with each line a container has logged since a time, when it was logged and whether to stdout or stderr
+// the command is stopped as soon as fn returns an error, usually because the reader went away
+func dockerLogs(container string, follow bool, since time.Time, fn func(ts time.Time, stream, line string) error) error {
+	args := []string{"logs", "--timestamps"}
+
+	if follow {
+		args = append(args, "-f")
+	}
+
+	if !since.IsZero() {
+		args = append(args, "--since", fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()))
+	}
+
+	args = append(args, container)
+
+	cmd := exec.Command("docker", args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	errs := make(chan error, 2)
+
+	scan := func(r io.Reader, stream string) {
+		s := bufio.NewScanner(r)
+
+		for s.Scan() {
+			ts, line := dockerLogLine(s.Text())
+
+			if err := fn(ts, stream, line); err != nil {
+				errs <- err
+				return
+			}
+		}
+
+		errs <- nil
+	}
+
+	go scan(stdout, "stdout")
+	go scan(stderr, "stderr")
+
+	var ferr error
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil && ferr == nil {
+			ferr = err
+			cmd.Process.Kill()
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && ferr == nil {
+		return err
+	}
+
+	return ferr
+}
+
+// dockerLogLine splits the timestamp that --timestamps puts in front of a line
+func dockerLogLine(line string) (time.Time, string) {
+	parts := strings.SplitN(line, " ", 2)
+
+	if len(parts) == 2 {
+		if ts, err := time.Parse(time.RFC3339Nano, parts[0]); err == nil {
+			return ts.UTC(), parts[1]
+		}
+	}
+
+	return time.Now().UTC(), line
+}
+
+// processLogEntry describes a line logged by a process
+func processLogEntry(ps types.Process, ts time.Time, stream, line string) types.LogEntry {
+	return types.LogEntry{
+		Timestamp: ts,
+		App:       ps.App,
+		Service:   ps.Service,
+		Process:   ps.Id,
+		Release:   ps.Release,
+		Stream:    stream,
+		Message:   line,
+	}
+}
+
+// logReader is the reading end of a log stream that signals on closed once it has been closed
+type logReader struct {
+	io.ReadCloser
+
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newLogReader(r io.ReadCloser) *logReader {
+	return &logReader{ReadCloser: r, closed: make(chan struct{})}
+}
+
+func (r *logReader) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return r.ReadCloser.Close()
+}
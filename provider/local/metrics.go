@@ -0,0 +1,41 @@
+package local
+
+import (
+	"github.com/convox/praxis/metrics"
+)
+
+var (
+	metricBuildDuration    = metrics.NewHistogram("convox_build_duration_seconds", "build durations by app and final status", []float64{10, 30, 60, 120, 300, 600, 1200, 1800}, "app", "status")
+	metricContainers       = metrics.NewGauge("convox_containers", "running containers by app", "app")
+	metricConvergeDuration = metrics.NewHistogram("convox_converge_duration_seconds", "converge durations by app", metrics.DefaultBuckets, "app")
+	metricConvergeErrors   = metrics.NewCounter("convox_converge_errors_total", "failed converges by app", "app")
+	metricQueueDepth       = metrics.NewGauge("convox_queue_depth", "messages waiting by app and queue", "app", "queue")
+)
+
+// collectMetrics samples the values that are not counted as they change
+func (p *Provider) collectMetrics() {
+	log := p.logger("collectMetrics")
+
+	cs, err := containersByLabels(map[string]string{"convox.rack": p.Name})
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	metricContainers.Reset()
+
+	for _, c := range cs {
+		if app := c.Labels["convox.app"]; app != "" {
+			metricContainers.Inc(app)
+		}
+	}
+
+	metricQueueDepth.Reset()
+
+	queuesLock.Lock()
+	defer queuesLock.Unlock()
+
+	for k, q := range queues {
+		metricQueueDepth.Set(float64(len(q)), k.app, k.queue)
+	}
+}
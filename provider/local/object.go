@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
 )
@@ -43,7 +44,7 @@ func (p *Provider) ObjectFetch(app, key string) (io.ReadCloser, error) {
 		return nil, log.Error(err)
 	}
 	if !ex {
-		return nil, log.Error(fmt.Errorf("no such key: %s", key))
+		return nil, log.Error(api.Errorf(404, "no such key: %s", key))
 	}
 
 	fn := filepath.Join(p.Root, "apps", app, "objects", key)
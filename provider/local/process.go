@@ -1,7 +1,6 @@
 package local
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -13,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/manifest"
 	"github.com/convox/praxis/types"
@@ -74,7 +74,7 @@ func (p *Provider) ProcessGet(app, pid string) (*types.Process, error) {
 	}
 
 	if len(pss) != 1 {
-		return nil, log.Error(fmt.Errorf("no such process: %s", pid))
+		return nil, log.Error(api.Errorf(404, "no such process: %s", pid))
 	}
 
 	return &pss[0], log.Success()
@@ -118,46 +118,22 @@ func (p *Provider) ProcessLogs(app, pid string, opts types.LogsOptions) (io.Read
 		return nil, log.Error(err)
 	}
 
-	cr, cw := io.Pipe()
+	r, w := io.Pipe()
 
-	args := []string{"logs"}
-
-	if opts.Follow {
-		args = append(args, "-f")
-	}
-
-	args = append(args, pid)
-
-	cmd := exec.Command("docker", args...)
-
-	cmd.Stdout = cw
-	cmd.Stderr = cw
-
-	if err := cmd.Start(); err != nil {
-		return nil, errors.WithStack(log.Error(err))
+	lw, err := helpers.NewLogWriter(w, opts)
+	if err != nil {
+		return nil, log.Error(err)
 	}
 
-	s := bufio.NewScanner(cr)
-
-	rr, rw := io.Pipe()
-
 	go func() {
-		defer rw.Close()
-		for s.Scan() {
-			if opts.Prefix {
-				fmt.Fprintf(rw, "%s %s/%s/%s %s\n", time.Now().Format(helpers.PrintableTime), ps.App, ps.Service, ps.Id, s.Text())
-			} else {
-				fmt.Fprintf(rw, "%s\n", s.Text())
-			}
-		}
-	}()
+		defer w.Close()
 
-	go func() {
-		defer cw.Close()
-		cmd.Wait()
+		dockerLogs(pid, opts.Follow, lw.Since(), func(ts time.Time, stream, line string) error {
+			return lw.Entry(processLogEntry(*ps, ts, stream, line))
+		})
 	}()
 
-	return rr, log.Success()
+	return r, log.Success()
 }
 
 func (p *Provider) ProcessProxy(app, pid string, port int, in io.Reader) (io.ReadCloser, error) {
@@ -255,7 +231,11 @@ func (p *Provider) ProcessStart(app string, opts types.ProcessRunOptions) (strin
 		return "", errors.WithStack(log.Error(err))
 	}
 
-	return strings.TrimSpace(string(data)), log.Success()
+	pid := strings.TrimSpace(string(data))
+
+	p.event("process:start", app, map[string]string{"id": pid, "service": opts.Service}, nil)
+
+	return pid, log.Success()
 }
 
 func (p *Provider) ProcessStop(app, pid string) error {
@@ -265,6 +245,8 @@ func (p *Provider) ProcessStop(app, pid string) error {
 		return errors.WithStack(log.Error(err))
 	}
 
+	p.event("process:stop", app, map[string]string{"id": pid}, nil)
+
 	return log.Success()
 }
 
@@ -384,8 +366,14 @@ func (p *Provider) argsFromOpts(app string, opts types.ProcessRunOptions) ([]str
 		return nil, errors.WithStack(err)
 	}
 
+	rack, err := p.rackURL(app, hostname)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	args = append(args, "-e", fmt.Sprintf("APP=%s", app))
-	args = append(args, "-e", fmt.Sprintf("RACK_URL=https://%s:3000", hostname))
+	args = append(args, "-e", fmt.Sprintf("RACK_URL=%s", rack))
+	args = append(args, "-e", fmt.Sprintf("RACK_FINGERPRINT=%s", os.Getenv("RACK_FINGERPRINT")))
 	args = append(args, "-e", fmt.Sprintf("RELEASE=%s", opts.Release))
 
 	args = append(args, "--link", hostname)
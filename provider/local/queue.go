@@ -1,7 +1,7 @@
 package local
 
 import (
-	"fmt"
+	"sync"
 	"time"
 
 	"github.com/convox/praxis/types"
@@ -9,8 +9,14 @@ import (
 
 type queueChannel chan map[string]string
 
+type queueKey struct {
+	app   string
+	queue string
+}
+
 var (
-	queues = map[string]queueChannel{}
+	queues     = map[queueKey]queueChannel{}
+	queuesLock sync.Mutex
 )
 
 func (p *Provider) QueueFetch(app, queue string, opts types.QueueFetchOptions) (map[string]string, error) {
@@ -43,7 +49,10 @@ func (p *Provider) QueueStore(app, queue string, attrs map[string]string) error
 }
 
 func appQueue(app, queue string) queueChannel {
-	key := fmt.Sprintf("%s-%s", app, queue)
+	queuesLock.Lock()
+	defer queuesLock.Unlock()
+
+	key := queueKey{app: app, queue: queue}
 
 	if q, ok := queues[key]; ok {
 		return q
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
 )
@@ -24,7 +25,7 @@ func (p *Provider) RegistryAdd(hostname, username, password string) (*types.Regi
 	key := fmt.Sprintf("registries/%s", hostname)
 
 	if p.storageExists(key) {
-		return nil, log.Error(fmt.Errorf("registry already exists: %s", hostname))
+		return nil, log.Error(api.Errorf(409, "registry already exists: %s", hostname))
 	}
 
 	if err := p.storageStore(fmt.Sprintf("registries/%s", hostname), r); err != nil {
@@ -63,7 +64,7 @@ func (p *Provider) RegistryRemove(hostname string) error {
 	key := fmt.Sprintf("registries/%s", hostname)
 
 	if !p.storageExists(key) {
-		return log.Error(fmt.Errorf("no such registry: %s", hostname))
+		return log.Error(api.Errorf(404, "no such registry: %s", hostname))
 	}
 
 	if err := p.storageDelete(key); err != nil {
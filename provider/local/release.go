@@ -8,7 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/cache"
+	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
 )
@@ -41,6 +43,8 @@ func (p *Provider) ReleaseCreate(app string, opts types.ReleaseCreateOptions) (*
 		return nil, errors.WithStack(log.Error(err))
 	}
 
+	p.event("release:create", app, map[string]string{"id": r.Id, "build": r.Build}, nil)
+
 	return r, log.Success()
 }
 
@@ -56,7 +60,7 @@ func (p *Provider) ReleaseGet(app, id string) (*types.Release, error) {
 
 	if err := p.storageLoad(fmt.Sprintf("apps/%s/releases/%s/release.json", app, id), &r, ReleaseCacheDuration); err != nil {
 		if strings.Contains(err.Error(), "no such key") {
-			return nil, fmt.Errorf("release not found")
+			return nil, log.Error(api.Errorf(404, "no such release: %s", id))
 		}
 		return nil, errors.WithStack(log.Error(err))
 	}
@@ -132,19 +136,27 @@ func (p *Provider) ReleaseLogs(app, id string, opts types.LogsOptions) (io.ReadC
 		time.Sleep(1 * time.Second)
 	}
 
-	lr, lw := io.Pipe()
+	lr, pw := io.Pipe()
+
+	lw, err := helpers.NewLogWriter(pw, opts)
+	if err != nil {
+		return nil, log.Error(err)
+	}
 
 	go func() {
-		defer lw.Close()
+		defer pw.Close()
 
-		since := opts.Since
+		since := lw.Since()
 
 		for {
 			time.Sleep(200 * time.Millisecond)
 
 			p.storageLogRead(key, since, func(at time.Time, entry []byte) {
 				since = at
-				lw.Write(entry)
+
+				for _, line := range strings.Split(strings.TrimSuffix(string(entry), "\n"), "\n") {
+					lw.Entry(types.LogEntry{Timestamp: at, App: app, Release: id, Message: line})
+				}
 			})
 
 			if !opts.Follow {
@@ -195,6 +207,7 @@ func (p *Provider) ReleasePromote(app, id string) error {
 	}
 
 	if err := p.converge(app); err != nil {
+		p.event("release:promote", app, map[string]string{"id": id}, err)
 		return errors.WithStack(log.Error(err))
 	}
 
@@ -204,6 +217,8 @@ func (p *Provider) ReleasePromote(app, id string) error {
 		return errors.WithStack(log.Error(err))
 	}
 
+	p.event("release:promote", app, map[string]string{"id": id}, nil)
+
 	return log.Success()
 }
 
@@ -5,14 +5,25 @@ import (
 	"io"
 	"net"
 	"os/exec"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/manifest"
 	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
 )
 
+const (
+	ResourceCacheDuration = 1 * time.Hour
+)
+
+// regexpResourceName matches names that can be used as a storage key, a container name and a hostname
+var regexpResourceName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
 func (p *Provider) ResourceGet(app, name string) (*types.Resource, error) {
 	rs, err := p.ResourceList(app)
 	if err != nil {
@@ -25,7 +36,7 @@ func (p *Provider) ResourceGet(app, name string) (*types.Resource, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("resource not found: %s", name)
+	return nil, api.Errorf(404, "no such resource: %s", name)
 }
 
 func (p *Provider) ResourceList(app string) (types.Resources, error) {
@@ -39,16 +50,12 @@ func (p *Provider) ResourceList(app string) (types.Resources, error) {
 	rs := make(types.Resources, len(m.Resources))
 
 	for i, r := range m.Resources {
-		e, err := resourceURL(app, r.Type, r.Name)
+		rr, err := p.resourceFromManifest(app, r)
 		if err != nil {
 			return nil, errors.WithStack(log.Error(err))
 		}
 
-		rs[i] = types.Resource{
-			Name:     r.Name,
-			Endpoint: e,
-			Type:     r.Type,
-		}
+		rs[i] = *rr
 	}
 
 	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
@@ -62,19 +69,231 @@ func (p *Provider) ResourceProxy(app, resource string, in io.Reader) (io.ReadClo
 		return nil, err
 	}
 
+	m, _, err := helpers.AppManifest(p, app)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range m.Resources {
+		if r.Name == resource && r.Shared() {
+			return p.SystemResourceProxy(r.Rack, in)
+		}
+	}
+
 	r, err := p.ResourceGet(app, resource)
 	if err != nil {
 		return nil, err
 	}
 
-	port, err := resourcePort(r.Type)
+	return resourceProxy(fmt.Sprintf("%s.%s.resource.%s", p.Name, app, resource), r.Type, in)
+}
+
+func (p *Provider) SystemResourceCreate(name, kind string) (*types.Resource, error) {
+	log := p.logger("SystemResourceCreate").Append("name=%q type=%q", name, kind)
+
+	if strings.TrimSpace(name) == "" {
+		return nil, log.Error(api.Errorf(400, "name required"))
+	}
+
+	if !regexpResourceName.MatchString(name) {
+		return nil, log.Error(api.Errorf(400, "invalid resource name: %s, use lowercase letters, numbers and dashes starting with a letter", name))
+	}
+
+	if _, err := resourcePort(kind); err != nil {
+		return nil, log.Error(err)
+	}
+
+	key := fmt.Sprintf("resources/%s", name)
+
+	if p.storageExists(key) {
+		return nil, log.Error(api.Errorf(409, "resource already exists: %s", name))
+	}
+
+	e, err := resourceURL(kind, systemResourceHost(name))
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	r := &types.Resource{
+		Name:     name,
+		Endpoint: e,
+		Type:     kind,
+	}
+
+	if err := p.storageStore(key, r); err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	if !p.Test {
+		go p.convergeSystem()
+	}
+
+	return r, log.Success()
+}
+
+func (p *Provider) SystemResourceDelete(name string) error {
+	log := p.logger("SystemResourceDelete").Append("name=%q", name)
+
+	if _, err := p.SystemResourceGet(name); err != nil {
+		return log.Error(err)
+	}
+
+	apps, err := p.systemResourceApps(name)
+	if err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	if len(apps) > 0 {
+		return log.Error(fmt.Errorf("resource is attached to apps: %s", strings.Join(apps, ", ")))
+	}
+
+	if err := p.storageDelete(fmt.Sprintf("resources/%s", name)); err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	if !p.Test {
+		cs, err := containersByLabels(map[string]string{
+			"convox.rack":   p.Name,
+			"convox.system": "true",
+			"convox.type":   "resource",
+			"convox.name":   name,
+		})
+		if err != nil {
+			return errors.WithStack(log.Error(err))
+		}
+
+		for _, c := range cs {
+			if err := p.containerStop(c.Id); err != nil {
+				return errors.WithStack(log.Error(err))
+			}
+		}
+	}
+
+	return log.Success()
+}
+
+func (p *Provider) SystemResourceGet(name string) (*types.Resource, error) {
+	log := p.logger("SystemResourceGet").Append("name=%q", name)
+
+	var r types.Resource
+
+	if err := p.storageLoad(fmt.Sprintf("resources/%s", name), &r, ResourceCacheDuration); err != nil {
+		if strings.HasPrefix(err.Error(), "no such key:") {
+			return nil, log.Error(api.Errorf(404, "no such resource: %s", name))
+		}
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	apps, err := p.systemResourceApps(name)
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	r.Apps = apps
+
+	return &r, log.Success()
+}
+
+func (p *Provider) SystemResourceList() (types.Resources, error) {
+	log := p.logger("SystemResourceList")
+
+	names, err := p.storageList("resources")
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	rs := make(types.Resources, len(names))
+
+	for i, name := range names {
+		r, err := p.SystemResourceGet(name)
+		if err != nil {
+			return nil, errors.WithStack(log.Error(err))
+		}
+
+		rs[i] = *r
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+
+	return rs, log.Successf("count=%d", len(rs))
+}
+
+func (p *Provider) SystemResourceProxy(name string, in io.Reader) (io.ReadCloser, error) {
+	r, err := p.SystemResourceGet(name)
 	if err != nil {
 		return nil, err
 	}
 
-	name := fmt.Sprintf("%s.%s.resource.%s", p.Name, app, resource)
+	return resourceProxy(fmt.Sprintf("%s.resource.%s", p.Name, name), r.Type, in)
+}
 
-	data, err := exec.Command("docker", "inspect", name, "--format", "{{.NetworkSettings.IPAddress}}").CombinedOutput()
+func (p *Provider) resourceFromManifest(app string, r manifest.Resource) (*types.Resource, error) {
+	if r.Shared() {
+		sr, err := p.SystemResourceGet(r.Rack)
+		if err != nil {
+			return nil, err
+		}
+
+		rr := &types.Resource{
+			Name:     r.Name,
+			Endpoint: sr.Endpoint,
+			Type:     sr.Type,
+		}
+
+		return rr, nil
+	}
+
+	e, err := resourceURL(r.Type, fmt.Sprintf("%s.resource.%s.convox", r.Name, app))
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &types.Resource{
+		Name:     r.Name,
+		Endpoint: e,
+		Type:     r.Type,
+	}
+
+	return rr, nil
+}
+
+// systemResourceApps returns the names of the apps whose current release attaches the rack resource
+func (p *Provider) systemResourceApps(name string) ([]string, error) {
+	as, err := p.AppList()
+	if err != nil {
+		return nil, err
+	}
+
+	apps := []string{}
+
+	for _, a := range as {
+		if a.Release == "" {
+			continue
+		}
+
+		m, _, err := helpers.AppManifest(p, a.Name)
+		if err != nil {
+			continue
+		}
+
+		for _, r := range m.Resources {
+			if r.Rack == name {
+				apps = append(apps, a.Name)
+				break
+			}
+		}
+	}
+
+	return apps, nil
+}
+
+func resourceProxy(container, kind string, in io.Reader) (io.ReadCloser, error) {
+	port, err := resourcePort(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := exec.Command("docker", "inspect", container, "--format", "{{.NetworkSettings.IPAddress}}").CombinedOutput()
 	if err != nil {
 		return nil, err
 	}
@@ -90,3 +309,7 @@ func (p *Provider) ResourceProxy(app, resource string, in io.Reader) (io.ReadClo
 
 	return cn, nil
 }
+
+func systemResourceHost(name string) string {
+	return fmt.Sprintf("%s.resource.convox", name)
+}
@@ -3,6 +3,7 @@ package local
 import (
 	"fmt"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 	"github.com/pkg/errors"
@@ -20,7 +21,7 @@ func (p *Provider) ServiceGet(app, name string) (*types.Service, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("service not found: %s", name)
+	return nil, api.Errorf(404, "no such service: %s", name)
 }
 
 func (p *Provider) ServiceList(app string) (types.Services, error) {
@@ -36,7 +37,10 @@ func (p *Provider) ServiceList(app string) (types.Services, error) {
 	for _, s := range m.Services {
 		endpoint := ""
 
-		if s.Port.Port > 0 {
+		switch {
+		case s.Port.Scheme == "udp":
+			endpoint = fmt.Sprintf("udp://%s.%s.%s:%d", s.Name, app, p.Name, s.Port.Port)
+		case s.Port.Port > 0:
 			endpoint = fmt.Sprintf("https://%s.%s.%s", s.Name, app, p.Name)
 		}
 
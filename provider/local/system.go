@@ -13,6 +13,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/types"
 	"github.com/kr/text"
 	"github.com/pkg/errors"
@@ -90,37 +91,31 @@ func (p *Provider) SystemInstall(name string, opts types.SystemInstallOptions) (
 func (p *Provider) SystemLogs(opts types.LogsOptions) (io.ReadCloser, error) {
 	log := p.logger("SystemLogs")
 
-	r, w := io.Pipe()
-
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, errors.WithStack(log.Error(err))
 	}
 
-	args := []string{"logs"}
-
-	if opts.Follow {
-		args = append(args, "-f")
-	}
-
-	if !opts.Since.IsZero() {
-		args = append(args, "--since", opts.Since.Format(time.RFC3339))
-	}
-
-	args = append(args, hostname)
-
-	cmd := exec.Command("docker", args...)
-
-	cmd.Stdout = w
-	cmd.Stderr = w
+	r, w := io.Pipe()
 
-	if err := cmd.Start(); err != nil {
-		return nil, errors.WithStack(log.Error(err))
+	lw, err := helpers.NewLogWriter(w, opts)
+	if err != nil {
+		return nil, log.Error(err)
 	}
 
 	go func() {
 		defer w.Close()
-		cmd.Wait()
+
+		dockerLogs(hostname, opts.Follow, lw.Since(), func(ts time.Time, stream, line string) error {
+			return lw.Entry(types.LogEntry{
+				Timestamp: ts,
+				App:       p.Name,
+				Service:   "rack",
+				Process:   hostname,
+				Stream:    stream,
+				Message:   line,
+			})
+		})
 	}()
 
 	return r, log.Success()
@@ -130,6 +125,7 @@ func (p *Provider) SystemOptions() (map[string]string, error) {
 	log := p.logger("SystemOptions")
 
 	options := map[string]string{
+		"features":  "logs.drains",
 		"streaming": "websocket",
 	}
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/types"
 )
 
@@ -18,7 +19,7 @@ func (p *Provider) TableGet(app, table string) (*types.Table, error) {
 
 	if err := p.storageLoad(fmt.Sprintf("apps/%s/tables/%s/table.json", app, table), &t, TableCacheDuration); err != nil {
 		if strings.HasPrefix(err.Error(), "no such key:") {
-			return nil, fmt.Errorf("no such table: %s", table)
+			return nil, api.Errorf(404, "no such table: %s", table)
 		}
 		return nil, err
 	}
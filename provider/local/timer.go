@@ -18,9 +18,15 @@ func (p *Provider) TimerCreate(app, name string, opts types.TimerCreateOptions)
 		return err
 	}
 
+	rack, err := p.rackURL(app, hostname)
+	if err != nil {
+		return err
+	}
+
 	args := []string{"run", "-i"}
 
-	args = append(args, "--link", hostname, "-e", fmt.Sprintf("RACK_URL=https://%s:3000", hostname))
+	args = append(args, "--link", hostname, "-e", fmt.Sprintf("RACK_URL=%s", rack))
+	args = append(args, "-e", fmt.Sprintf("RACK_FINGERPRINT=%s", os.Getenv("RACK_FINGERPRINT")))
 	args = append(args, "--name", cname)
 	args = append(args, "convox/praxis", "timer")
 	args = append(args, "-app", app)
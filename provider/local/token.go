@@ -0,0 +1,154 @@
+package local
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	TokenCacheDuration = 1 * time.Minute
+)
+
+var appTokenLock sync.Mutex
+
+func (p *Provider) TokenCreate(name string, opts types.TokenCreateOptions) (*types.Token, error) {
+	log := p.logger("TokenCreate").Append("name=%q scopes=%q", name, opts.Scopes)
+
+	t, err := types.NewToken(name, opts.Scopes)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	secret := t.Secret
+
+	// never persist the secret
+	t.Secret = ""
+
+	if err := p.storageStore(fmt.Sprintf("tokens/%s", t.Id), t); err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	t.Secret = secret
+
+	return t, log.Success()
+}
+
+func (p *Provider) TokenDelete(id string) error {
+	log := p.logger("TokenDelete").Append("id=%q", id)
+
+	key := fmt.Sprintf("tokens/%s", id)
+
+	if !p.storageExists(key) {
+		return log.Error(api.Errorf(404, "no such token: %s", id))
+	}
+
+	if err := p.storageDelete(key); err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	return log.Success()
+}
+
+func (p *Provider) TokenGet(id string) (*types.Token, error) {
+	log := p.logger("TokenGet").Append("id=%q", id)
+
+	var t types.Token
+
+	if err := p.storageLoad(fmt.Sprintf("tokens/%s", id), &t, TokenCacheDuration); err != nil {
+		return nil, log.Error(api.Errorf(404, "no such token: %s", id))
+	}
+
+	return &t, log.Success()
+}
+
+func (p *Provider) TokenList() (types.Tokens, error) {
+	log := p.logger("TokenList")
+
+	ids, err := p.storageList("tokens")
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	tokens := make(types.Tokens, len(ids))
+
+	for i, id := range ids {
+		var t types.Token
+
+		if err := p.storageLoad(fmt.Sprintf("tokens/%s", id), &t, TokenCacheDuration); err != nil {
+			return nil, errors.WithStack(log.Error(err))
+		}
+
+		tokens[i] = t
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Created.Before(tokens[j].Created) })
+
+	return tokens, log.Success()
+}
+
+// appToken returns the credential given to containers of an app, creating an app-scoped token if needed
+func (p *Provider) appToken(app string) (string, error) {
+	appTokenLock.Lock()
+	defer appTokenLock.Unlock()
+
+	key := fmt.Sprintf("apps/%s/token", app)
+
+	var value string
+
+	if err := p.storageLoad(key, &value, TokenCacheDuration); err == nil {
+		if id, _, err := types.ParseToken(value); err == nil && p.storageExists(fmt.Sprintf("tokens/%s", id)) {
+			return value, nil
+		}
+	}
+
+	t, err := p.TokenCreate(fmt.Sprintf("app-%s", app), types.TokenCreateOptions{Scopes: []string{fmt.Sprintf("app:%s", app)}})
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.storageStore(key, t.Value()); err != nil {
+		return "", err
+	}
+
+	return t.Value(), nil
+}
+
+// appTokenRevoke revokes the token given to containers of an app
+func (p *Provider) appTokenRevoke(app string) error {
+	var value string
+
+	if err := p.storageLoad(fmt.Sprintf("apps/%s/token", app), &value, 0); err != nil {
+		return nil
+	}
+
+	id, _, err := types.ParseToken(value)
+	if err != nil {
+		return nil
+	}
+
+	if !p.storageExists(fmt.Sprintf("tokens/%s", id)) {
+		return nil
+	}
+
+	return p.storageDelete(fmt.Sprintf("tokens/%s", id))
+}
+
+// rackURL returns the rack endpoint for a container, authenticated as the app when one is given
+func (p *Provider) rackURL(app, hostname string) (string, error) {
+	if app == "" {
+		return fmt.Sprintf("https://%s:3000", hostname), nil
+	}
+
+	token, err := p.appToken(app)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://%s@%s:3000", token, hostname), nil
+}
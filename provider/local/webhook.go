@@ -0,0 +1,149 @@
+package local
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	WebhookAttempts      = 5
+	WebhookBackoff       = 1 * time.Second
+	WebhookCacheDuration = 1 * time.Minute
+	WebhookTimeout       = 10 * time.Second
+)
+
+func (p *Provider) WebhookCreate(u string, opts types.WebhookCreateOptions) (*types.Webhook, error) {
+	log := p.logger("WebhookCreate").Append("url=%q", u)
+
+	pu, err := url.Parse(u)
+	if err != nil || (pu.Scheme != "http" && pu.Scheme != "https") || pu.Host == "" {
+		return nil, log.Error(fmt.Errorf("invalid webhook url: %s", u))
+	}
+
+	data := make([]byte, 32)
+
+	if _, err := rand.Read(data); err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	h := &types.Webhook{
+		Id:      types.Id("W", 10),
+		Url:     u,
+		Actions: opts.Actions,
+		App:     opts.App,
+		Created: time.Now().UTC(),
+		Secret:  fmt.Sprintf("%x", data),
+	}
+
+	if err := p.storageStore(fmt.Sprintf("webhooks/%s", h.Id), h); err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	return h, log.Successf("id=%s", h.Id)
+}
+
+func (p *Provider) WebhookDelete(id string) error {
+	log := p.logger("WebhookDelete").Append("id=%q", id)
+
+	key := fmt.Sprintf("webhooks/%s", id)
+
+	if !p.storageExists(key) {
+		return log.Error(api.Errorf(404, "no such webhook: %s", id))
+	}
+
+	if err := p.storageDelete(key); err != nil {
+		return errors.WithStack(log.Error(err))
+	}
+
+	return log.Success()
+}
+
+func (p *Provider) WebhookList() (types.Webhooks, error) {
+	log := p.logger("WebhookList")
+
+	ids, err := p.storageList("webhooks")
+	if err != nil {
+		return nil, errors.WithStack(log.Error(err))
+	}
+
+	hooks := make(types.Webhooks, len(ids))
+
+	for i, id := range ids {
+		var h types.Webhook
+
+		if err := p.storageLoad(fmt.Sprintf("webhooks/%s", id), &h, WebhookCacheDuration); err != nil {
+			return nil, errors.WithStack(log.Error(err))
+		}
+
+		hooks[i] = h
+	}
+
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].Created.Before(hooks[j].Created) })
+
+	return hooks, log.Success()
+}
+
+// webhookDeliver posts an event to a webhook, retrying with backoff until it is accepted
+func (p *Provider) webhookDeliver(h types.Webhook, e types.Event) {
+	log := p.logger("webhookDeliver").Append("id=%q event=%q", h.Id, e.Id)
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	client := &http.Client{Timeout: WebhookTimeout}
+
+	backoff := WebhookBackoff
+
+	for attempt := 1; attempt <= WebhookAttempts; attempt++ {
+		if err = webhookPost(client, h, e, body); err == nil {
+			log.Successf("attempts=%d", attempt)
+			return
+		}
+
+		if attempt < WebhookAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	log.Error(err)
+}
+
+func webhookPost(client *http.Client, h types.Webhook, e types.Event, body []byte) error {
+	req, err := http.NewRequest("POST", h.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "convox")
+	req.Header.Set("X-Convox-Delivery", e.Id)
+	req.Header.Set("X-Convox-Event", e.Action)
+	req.Header.Set("X-Convox-Signature", h.Sign(body))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook response: %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/convox/praxis/metrics"
 	"github.com/pkg/errors"
 )
 
@@ -23,6 +24,8 @@ func (p *Provider) Workers() {
 	}()
 
 	if !p.Test {
+		metrics.Collect(p.collectMetrics)
+
 		go func() {
 			for {
 				time.Sleep(10 * time.Second)
@@ -32,10 +35,34 @@ func (p *Provider) Workers() {
 				}
 			}
 		}()
+
+		go func() {
+			for {
+				if err := p.convergeDrains(); err != nil {
+					log.Error(errors.WithStack(err))
+				}
+
+				time.Sleep(10 * time.Second)
+			}
+		}()
+
+		go func() {
+			for {
+				if err := p.auditPrune(); err != nil {
+					log.Error(errors.WithStack(err))
+				}
+
+				time.Sleep(1 * time.Hour)
+			}
+		}()
 	}
 }
 
 func (p *Provider) workerConverge() error {
+	if err := p.convergeSystem(); err != nil {
+		return err
+	}
+
 	apps, err := p.AppList()
 	if err != nil {
 		return err
@@ -51,5 +78,9 @@ func (p *Provider) workerConverge() error {
 		return err
 	}
 
+	if err := p.routerReconcile(); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) AppCreate(name string) (*types.App, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.apps[name]; ok {
+		return nil, api.Errorf(409, "app already exists: %s", name)
+	}
+
+	a := &app{
+		app: types.App{
+			Name:   name,
+			Status: "running",
+		},
+		builds:   map[string]*types.Build{},
+		caches:   map[string]cacheItem{},
+		objects:  map[string][]byte{},
+		queues:   map[string]chan map[string]string{},
+		releases: map[string]*types.Release{},
+		tables:   map[string]*table{},
+	}
+
+	p.apps[name] = a
+
+	app := a.app
+
+	return &app, nil
+}
+
+func (p *Provider) AppDelete(name string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, err := p.app(name); err != nil {
+		return err
+	}
+
+	for id, ps := range p.processes {
+		if ps.App == name {
+			delete(p.processes, id)
+		}
+	}
+
+	delete(p.apps, name)
+
+	return nil
+}
+
+func (p *Provider) AppGet(name string) (*types.App, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(name)
+	if err != nil {
+		return nil, err
+	}
+
+	app := a.app
+
+	return &app, nil
+}
+
+func (p *Provider) AppList() (types.Apps, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	apps := types.Apps{}
+
+	for _, a := range p.apps {
+		apps = append(apps, a.app)
+	}
+
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+
+	return apps, nil
+}
+
+func (p *Provider) AppLogs(app string, opts types.LogsOptions) (io.ReadCloser, error) {
+	if _, err := p.AppGet(app); err != nil {
+		return nil, err
+	}
+
+	return empty(), nil
+}
+
+func (p *Provider) AppRegistry(app string) (*types.Registry, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
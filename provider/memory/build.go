@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/types"
+)
+
+// BuildCreate records a build without running it, use BuildUpdate to move it through its statuses
+func (p *Provider) BuildCreate(app, url string, opts types.BuildCreateOptions) (*types.Build, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &types.Build{
+		Id:       p.id("B"),
+		App:      app,
+		Manifest: opts.Manifest,
+		Status:   "created",
+		Created:  now(),
+	}
+
+	a.builds[b.Id] = b
+
+	build := *b
+
+	return &build, nil
+}
+
+func (p *Provider) BuildGet(app, id string) (*types.Build, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := a.builds[id]
+	if !ok {
+		return nil, api.Errorf(404, "no such build: %s", id)
+	}
+
+	build := *b
+
+	return &build, nil
+}
+
+// BuildLogs returns the log stored for a build at convox/builds/<id>/log, if any
+func (p *Provider) BuildLogs(app, id string, opts types.LogsOptions) (io.ReadCloser, error) {
+	b, err := p.BuildGet(app, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("convox/builds/%s/log", id)
+
+	exists, err := p.ObjectExists(app, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return empty(), nil
+	}
+
+	r, err := p.ObjectFetch(app, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.LogStream(r, types.LogEntry{App: app, Service: "build", Process: b.Process, Release: b.Release}, opts)
+}
+
+func (p *Provider) BuildList(app string) (types.Builds, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	builds := types.Builds{}
+
+	for _, b := range a.builds {
+		builds = append(builds, *b)
+	}
+
+	sort.Slice(builds, func(i, j int) bool { return builds[i].Id < builds[j].Id })
+
+	return builds, nil
+}
+
+func (p *Provider) BuildUpdate(app, id string, opts types.BuildUpdateOptions) (*types.Build, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := a.builds[id]
+	if !ok {
+		return nil, api.Errorf(404, "no such build: %s", id)
+	}
+
+	if !opts.Ended.IsZero() {
+		b.Ended = opts.Ended
+	}
+
+	if opts.Manifest != "" {
+		b.Manifest = opts.Manifest
+	}
+
+	if opts.Release != "" {
+		b.Release = opts.Release
+	}
+
+	if !opts.Started.IsZero() {
+		b.Started = opts.Started
+	}
+
+	if opts.Status != "" {
+		b.Status = opts.Status
+	}
+
+	build := *b
+
+	return &build, nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+type cacheItem struct {
+	attrs   map[string]string
+	expires time.Time
+}
+
+// CacheFetch returns nil for keys that were never stored or have expired
+func (p *Provider) CacheFetch(app, cache, key string) (map[string]string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	ck := fmt.Sprintf("%s/%s", cache, key)
+
+	item, ok := a.caches[ck]
+	if !ok {
+		return nil, nil
+	}
+
+	if now().After(item.expires) {
+		delete(a.caches, ck)
+		return nil, nil
+	}
+
+	return item.attrs, nil
+}
+
+func (p *Provider) CacheStore(app, cache, key string, attrs map[string]string, opts types.CacheStoreOptions) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return err
+	}
+
+	a.caches[fmt.Sprintf("%s/%s", cache, key)] = cacheItem{
+		attrs:   attrs,
+		expires: now().Add(time.Duration(coalescei(opts.Expires, 60)) * time.Second),
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/provider/conformance"
+	"github.com/convox/praxis/provider/memory"
+	"github.com/convox/praxis/types"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Suite{
+		Provider: func(check string) (types.Provider, func(), error) {
+			return memory.New(), nil, nil
+		},
+	}.Run(t)
+}
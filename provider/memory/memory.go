@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+// Provider is a types.Provider that keeps all of its state in memory
+// it needs no docker or cloud account so it is suited to unit tests and embedding
+// ids are assigned from a counter per prefix so they are the same on every run
+type Provider struct {
+	Name    string
+	Version string
+
+	// Run is called by ProcessRun and returns its exit code, by default processes exit with 0
+	Run func(app string, opts types.ProcessRunOptions) (int, error)
+
+	ctx context.Context
+	*state
+}
+
+type state struct {
+	lock sync.Mutex
+
+	apps      map[string]*app
+	ids       map[string]int
+	processes map[string]*types.Process
+}
+
+type app struct {
+	app      types.App
+	builds   map[string]*types.Build
+	caches   map[string]cacheItem
+	objects  map[string][]byte
+	queues   map[string]chan map[string]string
+	releases map[string]*types.Release
+	tables   map[string]*table
+}
+
+// New returns an empty memory provider
+func New() *Provider {
+	return &Provider{
+		Name:    "convox",
+		Version: "test",
+		state: &state{
+			apps:      map[string]*app{},
+			ids:       map[string]int{},
+			processes: map[string]*types.Process{},
+		},
+	}
+}
+
+func (p *Provider) Context() context.Context {
+	if p.ctx != nil {
+		return p.ctx
+	}
+
+	return context.Background()
+}
+
+func (p *Provider) WithContext(ctx context.Context) types.Provider {
+	var q Provider
+	q = *p
+	q.ctx = ctx
+	return &q
+}
+
+func (p *Provider) Workers() {
+}
+
+// id returns the next id for prefix, padded to the length of ids from the other providers
+// must be called with the lock held
+func (p *Provider) id(prefix string) string {
+	p.ids[prefix]++
+	return fmt.Sprintf("%s%0*d", prefix, 10-len(prefix), p.ids[prefix])
+}
+
+// app returns the state of an app
+// must be called with the lock held
+func (p *Provider) app(name string) (*app, error) {
+	a, ok := p.apps[name]
+	if !ok {
+		return nil, api.Errorf(404, "no such app: %s", name)
+	}
+
+	return a, nil
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func empty() io.ReadCloser {
+	return ioutil.NopCloser(strings.NewReader(""))
+}
@@ -0,0 +1,184 @@
+package memory_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/convox/praxis/provider/memory"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApps(t *testing.T) {
+	p := memory.New()
+
+	_, err := p.AppCreate("app2")
+	require.NoError(t, err)
+
+	app, err := p.AppCreate("app1")
+	require.NoError(t, err)
+	assert.Equal(t, "running", app.Status)
+
+	_, err = p.AppCreate("app1")
+	assert.EqualError(t, err, "app already exists: app1")
+
+	apps, err := p.AppList()
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	assert.Equal(t, "app1", apps[0].Name)
+	assert.Equal(t, "app2", apps[1].Name)
+
+	require.NoError(t, p.AppDelete("app1"))
+
+	_, err = p.AppGet("app1")
+	assert.EqualError(t, err, "no such app: app1")
+}
+
+func TestBuildsAndReleases(t *testing.T) {
+	p := memory.New()
+
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	b, err := p.BuildCreate("app1", "https://example.org/app.tgz", types.BuildCreateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "B000000001", b.Id)
+	assert.Equal(t, "created", b.Status)
+
+	b, err = p.BuildUpdate("app1", b.Id, types.BuildUpdateOptions{Status: "complete"})
+	require.NoError(t, err)
+	assert.Equal(t, "complete", b.Status)
+
+	_, err = p.BuildGet("app1", "B000000002")
+	assert.EqualError(t, err, "no such build: B000000002")
+
+	r1, err := p.ReleaseCreate("app1", types.ReleaseCreateOptions{Build: b.Id, Env: map[string]string{"FOO": "bar"}})
+	require.NoError(t, err)
+	assert.Equal(t, "R000000001", r1.Id)
+
+	r2, err := p.ReleaseCreate("app1", types.ReleaseCreateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "R000000002", r2.Id)
+	assert.Equal(t, b.Id, r2.Build)
+	assert.Equal(t, "bar", r2.Env["FOO"])
+
+	require.NoError(t, p.ReleasePromote("app1", r1.Id))
+
+	rs, err := p.ReleaseList("app1", types.ReleaseListOptions{})
+	require.NoError(t, err)
+	require.Len(t, rs, 2)
+	assert.Equal(t, r2.Id, rs[0].Id)
+	assert.Equal(t, "active", rs[1].Status)
+
+	app, err := p.AppGet("app1")
+	require.NoError(t, err)
+	assert.Equal(t, r1.Id, app.Release)
+}
+
+func TestProcesses(t *testing.T) {
+	p := memory.New()
+
+	p.Run = func(app string, opts types.ProcessRunOptions) (int, error) {
+		return 3, nil
+	}
+
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	pid, err := p.ProcessStart("app1", types.ProcessRunOptions{Command: "sleep 10", Service: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, "P000000001", pid)
+
+	pss, err := p.ProcessList("app1", types.ProcessListOptions{Service: "web"})
+	require.NoError(t, err)
+	require.Len(t, pss, 1)
+	assert.Equal(t, "sleep 10", pss[0].Command)
+
+	code, err := p.ProcessRun("app1", types.ProcessRunOptions{Command: "false"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+
+	require.NoError(t, p.ProcessStop("app1", pid))
+
+	_, err = p.ProcessGet("app1", pid)
+	assert.EqualError(t, err, "no such process: P000000001")
+}
+
+func TestObjectsQueuesCaches(t *testing.T) {
+	p := memory.New()
+
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	_, err = p.ObjectStore("app1", "foo/bar", bytes.NewReader([]byte("data")), types.ObjectStoreOptions{})
+	require.NoError(t, err)
+
+	r, err := p.ObjectFetch("app1", "foo/bar")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	_, err = p.ObjectFetch("app1", "missing")
+	assert.EqualError(t, err, "no such key: missing")
+
+	require.NoError(t, p.QueueStore("app1", "jobs", map[string]string{"id": "1"}))
+
+	attrs, err := p.QueueFetch("app1", "jobs", types.QueueFetchOptions{Timeout: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "1", attrs["id"])
+
+	require.NoError(t, p.CacheStore("app1", "sessions", "a", map[string]string{"user": "me"}, types.CacheStoreOptions{}))
+
+	attrs, err = p.CacheFetch("app1", "sessions", "a")
+	require.NoError(t, err)
+	assert.Equal(t, "me", attrs["user"])
+
+	attrs, err = p.CacheFetch("app1", "sessions", "b")
+	require.NoError(t, err)
+	assert.Nil(t, attrs)
+}
+
+func TestQueueFetchCancel(t *testing.T) {
+	p := memory.New()
+
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = p.WithContext(ctx).QueueFetch("app1", "jobs", types.QueueFetchOptions{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestTables(t *testing.T) {
+	p := memory.New()
+
+	_, err := p.AppCreate("app1")
+	require.NoError(t, err)
+
+	require.NoError(t, p.TableCreate("app1", "users", types.TableCreateOptions{Indexes: []string{"email"}}))
+	require.NoError(t, p.TableRowStore("app1", "users", types.TableRow{"id": "1", "email": "a@example.org"}))
+	require.NoError(t, p.TableRowStore("app1", "users", types.TableRow{"id": "2", "email": "b@example.org"}))
+
+	tables, err := p.TableList("app1")
+	require.NoError(t, err)
+	assert.Equal(t, types.Tables{{Name: "users", Indexes: []string{"email"}}}, tables)
+
+	rows, err := p.TableQuery("app1", "users", "email=b@example.org")
+	require.NoError(t, err)
+	assert.Equal(t, types.TableRows{{"id": "2", "email": "b@example.org"}}, rows)
+
+	require.NoError(t, p.TableTruncate("app1", "users"))
+
+	rows, err = p.TableQuery("app1", "users", "")
+	require.NoError(t, err)
+	assert.Len(t, rows, 0)
+
+	_, err = p.TableGet("app1", "missing")
+	assert.EqualError(t, err, "no such table: missing")
+}
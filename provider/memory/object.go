@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) ObjectExists(app, key string) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := a.objects[key]
+
+	return ok, nil
+}
+
+func (p *Provider) ObjectFetch(app, key string) (io.ReadCloser, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := a.objects[key]
+	if !ok {
+		return nil, api.Errorf(404, "no such key: %s", key)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (p *Provider) ObjectStore(app, key string, r io.Reader, opts types.ObjectStoreOptions) (*types.Object, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be blank")
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	a.objects[key] = data
+
+	return &types.Object{Key: key}, nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) ProcessExec(app, pid, command string, opts types.ProcessExecOptions) (int, error) {
+	if _, err := p.ProcessGet(app, pid); err != nil {
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+func (p *Provider) ProcessGet(app, pid string) (*types.Process, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, err := p.app(app); err != nil {
+		return nil, err
+	}
+
+	ps, ok := p.processes[pid]
+	if !ok || ps.App != app {
+		return nil, api.Errorf(404, "no such process: %s", pid)
+	}
+
+	process := *ps
+
+	return &process, nil
+}
+
+func (p *Provider) ProcessList(app string, opts types.ProcessListOptions) (types.Processes, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, err := p.app(app); err != nil {
+		return nil, err
+	}
+
+	pss := types.Processes{}
+
+	for _, ps := range p.processes {
+		if ps.App != app {
+			continue
+		}
+
+		if opts.Service != "" && ps.Service != opts.Service {
+			continue
+		}
+
+		pss = append(pss, *ps)
+	}
+
+	sort.Slice(pss, func(i, j int) bool { return pss[i].Id < pss[j].Id })
+
+	return pss, nil
+}
+
+func (p *Provider) ProcessLogs(app, pid string, opts types.LogsOptions) (io.ReadCloser, error) {
+	if _, err := p.ProcessGet(app, pid); err != nil {
+		return nil, err
+	}
+
+	return empty(), nil
+}
+
+func (p *Provider) ProcessProxy(app, pid string, port int, in io.Reader) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// ProcessRun hands the process to Run and returns its exit code
+func (p *Provider) ProcessRun(app string, opts types.ProcessRunOptions) (int, error) {
+	if _, err := p.AppGet(app); err != nil {
+		return 0, err
+	}
+
+	if p.Run == nil {
+		return 0, nil
+	}
+
+	return p.Run(app, opts)
+}
+
+// ProcessStart records a running process, it stays running until stopped
+func (p *Provider) ProcessStart(app string, opts types.ProcessRunOptions) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return "", err
+	}
+
+	ps := &types.Process{
+		Id:      p.id("P"),
+		App:     app,
+		Command: opts.Command,
+		Release: coalesce(opts.Release, a.app.Release),
+		Service: opts.Service,
+		Started: now(),
+		Status:  "running",
+		Type:    "process",
+	}
+
+	p.processes[ps.Id] = ps
+
+	return ps.Id, nil
+}
+
+func (p *Provider) ProcessStop(app, pid string) error {
+	if _, err := p.ProcessGet(app, pid); err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.processes, pid)
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+// QueueFetch waits for a message until the timeout or the provider context is done
+func (p *Provider) QueueFetch(app, queue string, opts types.QueueFetchOptions) (map[string]string, error) {
+	q, err := p.queue(app, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.After(time.Duration(coalescei(opts.Timeout, 10)) * time.Second)
+
+	select {
+	case attrs := <-q:
+		return attrs, nil
+	case <-timeout:
+		return nil, nil
+	case <-p.Context().Done():
+		return nil, p.Context().Err()
+	}
+}
+
+func (p *Provider) QueueStore(app, queue string, attrs map[string]string) error {
+	q, err := p.queue(app, queue)
+	if err != nil {
+		return err
+	}
+
+	q <- attrs
+
+	return nil
+}
+
+func (p *Provider) queue(app, queue string) (chan map[string]string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if q, ok := a.queues[queue]; ok {
+		return q, nil
+	}
+
+	a.queues[queue] = make(chan map[string]string, 10*1024)
+
+	return a.queues[queue], nil
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) ReleaseCreate(app string, opts types.ReleaseCreateOptions) (*types.Release, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &types.Release{
+		Id:      p.id("R"),
+		App:     app,
+		Env:     types.Environment{},
+		Status:  "created",
+		Created: now(),
+	}
+
+	// fork the latest release
+	if rs := a.releaseList(); len(rs) > 0 {
+		r.Build = rs[0].Build
+
+		for k, v := range rs[0].Env {
+			r.Env[k] = v
+		}
+	}
+
+	if opts.Build != "" {
+		r.Build = opts.Build
+	}
+
+	if opts.Env != nil {
+		r.Env = opts.Env
+	}
+
+	a.releases[r.Id] = r
+
+	return a.release(r), nil
+}
+
+func (p *Provider) ReleaseGet(app, id string) (*types.Release, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := a.releases[id]
+	if !ok {
+		return nil, api.Errorf(404, "no such release: %s", id)
+	}
+
+	return a.release(r), nil
+}
+
+func (p *Provider) ReleaseList(app string, opts types.ReleaseListOptions) (types.Releases, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := a.releaseList()
+
+	limit := coalescei(opts.Count, 10)
+
+	if len(releases) > limit {
+		releases = releases[0:limit]
+	}
+
+	return releases, nil
+}
+
+func (p *Provider) ReleaseLogs(app, id string, opts types.LogsOptions) (io.ReadCloser, error) {
+	if _, err := p.ReleaseGet(app, id); err != nil {
+		return nil, err
+	}
+
+	return empty(), nil
+}
+
+func (p *Provider) ReleasePromote(app, id string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return err
+	}
+
+	r, ok := a.releases[id]
+	if !ok {
+		return api.Errorf(404, "no such release: %s", id)
+	}
+
+	if r.Build == "" {
+		return fmt.Errorf("no build for release: %s", id)
+	}
+
+	r.Status = "promoted"
+
+	a.app.Release = r.Id
+
+	return nil
+}
+
+// release returns a copy of r with its status as the local provider reports it
+func (a *app) release(r *types.Release) *types.Release {
+	release := *r
+
+	if a.app.Release == r.Id {
+		release.Status = "active"
+	}
+
+	return &release
+}
+
+// releaseList returns the releases of an app, newest first
+func (a *app) releaseList() types.Releases {
+	releases := types.Releases{}
+
+	for _, r := range a.releases {
+		releases = append(releases, *a.release(r))
+	}
+
+	sort.Slice(releases, func(i, j int) bool { return releases[j].Id < releases[i].Id })
+
+	return releases
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/convox/praxis/types"
+)
+
+func (p *Provider) SystemAudit(opts types.SystemAuditOptions) (types.AuditEvents, error) {
+	return types.AuditEvents{}, nil
+}
+
+// SystemAuditRecord discards the event, the memory provider keeps no audit log
+func (p *Provider) SystemAuditRecord(event types.AuditEvent) error {
+	return nil
+}
+
+func (p *Provider) SystemGet() (*types.System, error) {
+	system := &types.System{
+		Image:   fmt.Sprintf("convox/praxis:%s", p.Version),
+		Name:    p.Name,
+		Status:  "running",
+		Version: p.Version,
+	}
+
+	return system, nil
+}
+
+func (p *Provider) SystemInstall(name string, opts types.SystemInstallOptions) (string, error) {
+	return "", fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemLogs(opts types.LogsOptions) (io.ReadCloser, error) {
+	return empty(), nil
+}
+
+func (p *Provider) SystemOptions() (map[string]string, error) {
+	options := map[string]string{
+		"streaming": "websocket",
+	}
+
+	return options, nil
+}
+
+func (p *Provider) SystemResourceCreate(name, kind string) (*types.Resource, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceDelete(name string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceGet(name string) (*types.Resource, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemResourceList() (types.Resources, error) {
+	return types.Resources{}, nil
+}
+
+func (p *Provider) SystemResourceProxy(name string, in io.Reader) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemUninstall(name string, opts types.SystemInstallOptions) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) SystemUpdate(opts types.SystemUpdateOptions) error {
+	return fmt.Errorf("unimplemented")
+}
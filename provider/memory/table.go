@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+type table struct {
+	indexes []string
+	rows    types.TableRows
+}
+
+// TableCreate adds a table to an app, tables are not created through types.Provider so tests seed them here
+func (p *Provider) TableCreate(app, name string, opts types.TableCreateOptions) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := a.tables[name]; ok {
+		return api.Errorf(409, "table already exists: %s", name)
+	}
+
+	a.tables[name] = &table{indexes: opts.Indexes, rows: types.TableRows{}}
+
+	return nil
+}
+
+// TableRowStore appends a row to a table
+func (p *Provider) TableRowStore(app, name string, row types.TableRow) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	t, err := p.table(app, name)
+	if err != nil {
+		return err
+	}
+
+	t.rows = append(t.rows, row)
+
+	return nil
+}
+
+func (p *Provider) TableGet(app, name string) (*types.Table, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	t, err := p.table(app, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Table{Name: name, Indexes: t.indexes}, nil
+}
+
+func (p *Provider) TableList(app string) (types.Tables, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := types.Tables{}
+
+	for name, t := range a.tables {
+		tables = append(tables, types.Table{Name: name, Indexes: t.indexes})
+	}
+
+	sort.Slice(tables, tables.Less)
+
+	return tables, nil
+}
+
+// TableQuery returns the rows matching a query of the form <column>=<value>, or every row for an empty query
+func (p *Provider) TableQuery(app, name, query string) (types.TableRows, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	t, err := p.table(app, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := types.TableRows{}
+
+	parts := strings.SplitN(query, "=", 2)
+
+	for _, row := range t.rows {
+		if query == "" || (len(parts) == 2 && row[parts[0]] == parts[1]) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+func (p *Provider) TableTruncate(app, name string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	t, err := p.table(app, name)
+	if err != nil {
+		return err
+	}
+
+	t.rows = types.TableRows{}
+
+	return nil
+}
+
+// table returns the state of a table
+// must be called with the lock held
+func (p *Provider) table(app, name string) (*table, error) {
+	a, err := p.app(app)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := a.tables[name]
+	if !ok {
+		return nil, api.Errorf(404, "no such table: %s", name)
+	}
+
+	return t, nil
+}
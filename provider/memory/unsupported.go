@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/convox/praxis/types"
+)
+
+// the memory provider does not model the calls below
+// listings of things it can not create are empty, the rest are unimplemented
+
+func (p *Provider) BalancerList(app string) (types.Balancers, error) {
+	if _, err := p.AppGet(app); err != nil {
+		return nil, err
+	}
+
+	return types.Balancers{}, nil
+}
+
+func (p *Provider) DrainCreate(app, url string) (*types.Drain, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) DrainDelete(app, id string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) DrainList(app string) (types.Drains, error) {
+	if _, err := p.AppGet(app); err != nil {
+		return nil, err
+	}
+
+	return types.Drains{}, nil
+}
+
+func (p *Provider) EventSend(action string, opts types.EventSendOptions) error {
+	return nil
+}
+
+func (p *Provider) EventStream(opts types.EventStreamOptions) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) FilesDelete(app, pid string, files []string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) FilesUpload(app, pid string, r io.Reader) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) KeyDecrypt(app, key string, data []byte) ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) KeyEncrypt(app, key string, data []byte) ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) RegistryAdd(server, username, password string) (*types.Registry, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) RegistryList() (types.Registries, error) {
+	return types.Registries{}, nil
+}
+
+func (p *Provider) RegistryRemove(server string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) ResourceGet(app, name string) (*types.Resource, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) ResourceList(app string) (types.Resources, error) {
+	if _, err := p.AppGet(app); err != nil {
+		return nil, err
+	}
+
+	return types.Resources{}, nil
+}
+
+func (p *Provider) ResourceProxy(app, resource string, in io.Reader) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) ServiceGet(app, name string) (*types.Service, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) ServiceList(app string) (types.Services, error) {
+	if _, err := p.AppGet(app); err != nil {
+		return nil, err
+	}
+
+	return types.Services{}, nil
+}
+
+func (p *Provider) TokenCreate(name string, opts types.TokenCreateOptions) (*types.Token, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) TokenDelete(id string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) TokenGet(id string) (*types.Token, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) TokenList() (types.Tokens, error) {
+	return types.Tokens{}, nil
+}
+
+func (p *Provider) WebhookCreate(url string, opts types.WebhookCreateOptions) (*types.Webhook, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) WebhookDelete(id string) error {
+	return fmt.Errorf("unimplemented")
+}
+
+func (p *Provider) WebhookList() (types.Webhooks, error) {
+	return types.Webhooks{}, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/convox/praxis/provider/aws"
 	"github.com/convox/praxis/provider/local"
+	"github.com/convox/praxis/provider/memory"
 	"github.com/convox/praxis/types"
 )
 
@@ -19,6 +20,8 @@ func FromType(t string) (types.Provider, error) {
 		return aws.FromEnv()
 	case "local", "":
 		return local.FromEnv()
+	case "memory":
+		return memory.New(), nil
 	default:
 		return nil, fmt.Errorf("invalid provider type: %s", t)
 	}
@@ -11,24 +11,55 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"path/filepath"
 	"time"
+
+	"github.com/convox/praxis/types"
 )
 
-func caCertificate() (tls.Certificate, error) {
-	cert, err := tls.LoadX509KeyPair("/Users/Shared/convox/ca.crt", "/Users/Shared/convox/ca.key")
-	if err != nil {
-		return generateCACertificate()
-	}
+const (
+	caCheckInterval = 1 * time.Hour
+	caValidity      = 365 * 24 * time.Hour
+	certRenewBefore = 30 * 24 * time.Hour
+	certValidity    = 365 * 24 * time.Hour
+)
 
-	cert, err = tls.LoadX509KeyPair("/etc/convox/ca.crt", "/etc/convox/ca.key")
-	if err != nil {
-		return generateCACertificate()
+// caDir holds the certificate authority that the router signs with
+var caDir = "/etc/convox"
+
+// CA describes the certificate authority that signs endpoint certificates
+type CA struct {
+	Certificate string    `json:"certificate"`
+	Expires     time.Time `json:"expires"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+func caCertificate(domain string) (tls.Certificate, error) {
+	for _, dir := range []string{caDir, "/Users/Shared/convox"} {
+		cert, err := tls.LoadX509KeyPair(fmt.Sprintf("%s/ca.crt", dir), fmt.Sprintf("%s/ca.key", dir))
+		if err != nil {
+			continue
+		}
+
+		if certificateExpiring(cert) {
+			fmt.Printf("ns=convox.router at=ca state=expiring dir=%q\n", dir)
+			break
+		}
+
+		if !caConstrained(cert, domain) {
+			fmt.Printf("ns=convox.router at=ca state=unconstrained dir=%q\n", dir)
+			break
+		}
+
+		return cert, nil
 	}
 
-	return cert, nil
+	return generateCACertificate(domain)
 }
 
-func generateCACertificate() (tls.Certificate, error) {
+// generateCACertificate creates a certificate authority that can only sign certificates for hosts under domain
+// it is installed in the system trust store so a leaked key must not be able to impersonate other sites
+func generateCACertificate(domain string) (tls.Certificate, error) {
 	rkey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		return tls.Certificate{}, err
@@ -40,14 +71,16 @@ func generateCACertificate() (tls.Certificate, error) {
 	}
 
 	template := x509.Certificate{
-		BasicConstraintsValid: true,
-		IsCA:         true,
-		DNSNames:     []string{"ca.convox"},
-		SerialNumber: serial,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
-		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid:       true,
+		IsCA:                        true,
+		DNSNames:                    []string{"ca.convox"},
+		SerialNumber:                serial,
+		NotBefore:                   time.Now(),
+		NotAfter:                    time.Now().Add(caValidity),
+		KeyUsage:                    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:                 []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		PermittedDNSDomainsCritical: true,
+		PermittedDNSDomains:         []string{domain},
 		Subject: pkix.Name{
 			CommonName:   "ca.convox",
 			Organization: []string{"convox"},
@@ -67,21 +100,115 @@ func generateCACertificate() (tls.Certificate, error) {
 		return tls.Certificate{}, err
 	}
 
-	if err := os.MkdirAll("/etc/convox", 0755); err != nil {
+	if err := os.MkdirAll(caDir, 0755); err != nil {
 		return tls.Certificate{}, err
 	}
 
-	if err := ioutil.WriteFile("/etc/convox/ca.crt", pub, 0644); err != nil {
+	if err := ioutil.WriteFile(filepath.Join(caDir, "ca.crt"), pub, 0644); err != nil {
 		return tls.Certificate{}, err
 	}
 
-	if err := ioutil.WriteFile("/etc/convox/ca.key", key, 0600); err != nil {
+	if err := ioutil.WriteFile(filepath.Join(caDir, "ca.key"), key, 0600); err != nil {
 		return tls.Certificate{}, err
 	}
 
 	return cert, nil
 }
 
+// caConstrained returns true if a certificate authority can only sign certificates for hosts under domain
+func caConstrained(cert tls.Certificate, domain string) bool {
+	if len(cert.Certificate) == 0 {
+		return false
+	}
+
+	cpub, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false
+	}
+
+	return cpub.PermittedDNSDomainsCritical && len(cpub.PermittedDNSDomains) == 1 && cpub.PermittedDNSDomains[0] == domain
+}
+
+// CA returns the current certificate authority
+func (r *Router) CA() (*CA, error) {
+	r.certLock.Lock()
+	defer r.certLock.Unlock()
+
+	cpub, err := x509.ParseCertificate(r.ca.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	ca := &CA{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cpub.Raw})),
+		Expires:     cpub.NotAfter,
+		Fingerprint: Fingerprint(cpub.Raw),
+	}
+
+	return ca, nil
+}
+
+// Fingerprint returns the sha256 fingerprint of a der encoded certificate
+func Fingerprint(der []byte) string {
+	return types.Fingerprint(der)
+}
+
+// rotateCA replaces the certificate authority and discards all certificates it signed
+// hosts that trust the old authority have to install the new one, the log says how
+func (r *Router) rotateCA() error {
+	ca, err := generateCACertificate(r.Domain)
+	if err != nil {
+		return err
+	}
+
+	r.certLock.Lock()
+	defer r.certLock.Unlock()
+
+	r.ca = ca
+	r.certs = map[string]tls.Certificate{}
+
+	fp := Fingerprint(ca.Certificate[0])
+
+	fmt.Printf("ns=convox.router at=ca state=rotated ca=%q install=%q\n", fp, fmt.Sprintf("cx router ca install --fingerprint %s", fp))
+
+	return nil
+}
+
+// caTick rotates the certificate authority before it expires
+func (r *Router) caTick() {
+	tick := time.Tick(caCheckInterval)
+
+	for range tick {
+		r.certLock.Lock()
+		expiring := certificateExpiring(r.ca)
+		r.certLock.Unlock()
+
+		if expiring {
+			fmt.Printf("ns=convox.router at=ca state=expiring\n")
+			logError(r.rotateCA())
+		}
+	}
+}
+
+// certificate returns a cached certificate for host, generating it if needed or close to expiry
+func (r *Router) certificate(host string) (tls.Certificate, error) {
+	r.certLock.Lock()
+	defer r.certLock.Unlock()
+
+	if cert, ok := r.certs[host]; ok && !certificateExpiring(cert) {
+		return cert, nil
+	}
+
+	cert, err := r.generateCertificate(host)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	r.certs[host] = cert
+
+	return cert, nil
+}
+
 func (r *Router) generateCertificate(host string) (tls.Certificate, error) {
 	rkey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
@@ -98,6 +225,13 @@ func (r *Router) generateCertificate(host string) (tls.Certificate, error) {
 		return tls.Certificate{}, err
 	}
 
+	// certificates can not outlive the authority that signs them
+	expires := time.Now().Add(certValidity)
+
+	if cpub.NotAfter.Before(expires) {
+		expires = cpub.NotAfter
+	}
+
 	template := x509.Certificate{
 		SerialNumber: serial,
 		Subject: pkix.Name{
@@ -106,7 +240,7 @@ func (r *Router) generateCertificate(host string) (tls.Certificate, error) {
 		},
 		Issuer:                cpub.Subject,
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		NotAfter:              expires,
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
@@ -123,3 +257,17 @@ func (r *Router) generateCertificate(host string) (tls.Certificate, error) {
 
 	return tls.X509KeyPair(pub, key)
 }
+
+// certificateExpiring returns true if a certificate is invalid or expires soon
+func certificateExpiring(cert tls.Certificate) bool {
+	if len(cert.Certificate) == 0 {
+		return true
+	}
+
+	cpub, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return true
+	}
+
+	return time.Now().Add(certRenewBefore).After(cpub.NotAfter)
+}
@@ -0,0 +1,213 @@
+package router_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, "BA:78:16:BF:8F:01:CF:EA:41:41:40:DE:5D:AE:22:23:B0:03:61:A3:96:17:7A:9C:B4:10:FF:61:F2:00:15:AD", router.Fingerprint([]byte("abc")))
+}
+
+func TestCertificateExpiring(t *testing.T) {
+	assert.True(t, router.CertificateExpiring(tls.Certificate{}))
+	assert.True(t, router.CertificateExpiring(testCA(t, time.Now().Add(10*24*time.Hour))))
+	assert.False(t, router.CertificateExpiring(testCA(t, time.Now().Add(365*24*time.Hour))))
+}
+
+func TestCertificateCappedAtCA(t *testing.T) {
+	r := router.NewTestRouter("")
+
+	ca := testCA(t, time.Now().Add(90*24*time.Hour))
+
+	r.SetCA(ca)
+
+	cert, err := r.Certificate("web.app.convox")
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	cpub, err := x509.ParseCertificate(ca.Certificate[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, cpub.NotAfter, leaf.NotAfter)
+	assert.NoError(t, leaf.CheckSignatureFrom(cpub))
+}
+
+func TestRotateCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "router")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	router.SetCADir(dir)
+	defer router.SetCADir("/etc/convox")
+
+	r := router.NewTestRouter("")
+
+	require.NoError(t, r.RotateCA())
+
+	old, err := r.CA()
+	require.NoError(t, err)
+
+	c1, err := r.Certificate("web.app.convox")
+	require.NoError(t, err)
+
+	c2, err := r.Certificate("web.app.convox")
+	require.NoError(t, err)
+	assert.Equal(t, c1.Certificate, c2.Certificate)
+
+	require.NoError(t, r.RotateCA())
+
+	ca, err := r.CA()
+	require.NoError(t, err)
+	assert.NotEqual(t, old.Fingerprint, ca.Fingerprint)
+
+	// the cache is cleared so the next certificate is signed by the new authority
+	c3, err := r.Certificate("web.app.convox")
+	require.NoError(t, err)
+	assert.NotEqual(t, c1.Certificate, c3.Certificate)
+
+	leaf, err := x509.ParseCertificate(c3.Certificate[0])
+	require.NoError(t, err)
+
+	assert.NoError(t, leaf.CheckSignatureFrom(testParseCA(t, ca)))
+	assert.Error(t, leaf.CheckSignatureFrom(testParseCA(t, old)))
+
+	data, err := ioutil.ReadFile(dir + "/ca.crt")
+	require.NoError(t, err)
+	assert.Equal(t, ca.Certificate, string(data))
+}
+
+func TestCAConstrained(t *testing.T) {
+	dir, err := ioutil.TempDir("", "router")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	router.SetCADir(dir)
+	defer router.SetCADir("/etc/convox")
+
+	ca, err := router.CACertificate("convox")
+	require.NoError(t, err)
+
+	cpub, err := x509.ParseCertificate(ca.Certificate[0])
+	require.NoError(t, err)
+
+	assert.True(t, cpub.PermittedDNSDomainsCritical)
+	assert.Equal(t, []string{"convox"}, cpub.PermittedDNSDomains)
+
+	r := router.NewTestRouter("")
+	r.SetCA(ca)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cpub)
+
+	for host, valid := range map[string]bool{"web.app.convox": true, "example.org": false} {
+		cert, err := r.Certificate(host)
+		require.NoError(t, err)
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+
+		_, err = leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.Equal(t, valid, err == nil, host)
+	}
+
+	// an authority for another domain, or one without constraints, is replaced
+	same, err := router.CACertificate("convox")
+	require.NoError(t, err)
+	assert.Equal(t, ca.Certificate, same.Certificate)
+
+	other, err := router.CACertificate("test")
+	require.NoError(t, err)
+	assert.NotEqual(t, ca.Certificate, other.Certificate)
+}
+
+func TestCARotateUnauthorized(t *testing.T) {
+	dir, err := ioutil.TempDir("", "router")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	router.SetCADir(dir)
+	defer router.SetCADir("/etc/convox")
+
+	r := router.NewTestRouter("")
+	require.NoError(t, r.RotateCA())
+
+	old, err := r.CA()
+	require.NoError(t, err)
+
+	s := r.Server("secret")
+
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		req := httptest.NewRequest("POST", "/ca/rotate", nil)
+
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		assert.Equal(t, 401, w.Code, auth)
+	}
+
+	ca, err := r.CA()
+	require.NoError(t, err)
+	assert.Equal(t, old.Fingerprint, ca.Fingerprint)
+
+	req := httptest.NewRequest("POST", "/ca/rotate", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	ca, err = r.CA()
+	require.NoError(t, err)
+	assert.NotEqual(t, old.Fingerprint, ca.Fingerprint)
+}
+
+// testCA returns a certificate authority that expires at a given time
+func testCA(t *testing.T, expires time.Time) tls.Certificate {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now(),
+		NotAfter:              expires.Truncate(time.Second),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Subject:               pkix.Name{CommonName: "ca.test"},
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{data}, PrivateKey: key}
+}
+
+func testParseCA(t *testing.T, ca *router.CA) *x509.Certificate {
+	block, _ := pem.Decode([]byte(ca.Certificate))
+	require.NotNil(t, block)
+
+	cpub, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	return cpub
+}
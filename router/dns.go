@@ -2,23 +2,41 @@ package router
 
 import (
 	"fmt"
+	"net"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/miekg/dns"
 )
 
 type DNS struct {
-	mux    *dns.ServeMux
-	router *Router
-	server *dns.Server
+	domains  map[string]bool
+	lock     sync.Mutex
+	mux      *dns.ServeMux
+	resolver *Resolver
+	router   *Router
+	server   *dns.Server
+	stats    DNSStats
+}
+
+// DNSStats counts queries answered by the router
+type DNSStats struct {
+	Queries  int64         `json:"queries"`
+	Rack     int64         `json:"rack"`
+	Resolver ResolverStats `json:"resolver"`
+	Servers  []string      `json:"servers"`
 }
 
 func (r *Router) NewDNS() (*DNS, error) {
 	mux := dns.NewServeMux()
 
 	d := &DNS{
-		mux:    mux,
-		router: r,
+		domains:  map[string]bool{},
+		mux:      mux,
+		resolver: NewResolver(nil),
+		router:   r,
 		server: &dns.Server{
 			Addr:    fmt.Sprintf("%s:53", r.ip),
 			Handler: mux,
@@ -26,7 +44,7 @@ func (r *Router) NewDNS() (*DNS, error) {
 		},
 	}
 
-	mux.HandleFunc(".", resolvePassthrough)
+	mux.HandleFunc(".", d.resolvePassthrough)
 
 	if err := d.registerDomain(r.Domain); err != nil {
 		return nil, err
@@ -39,6 +57,50 @@ func (d *DNS) Serve() error {
 	return d.server.ListenAndServe()
 }
 
+// SetResolvers replaces the upstream servers, ignoring the router itself to avoid loops
+func (d *DNS) SetResolvers(servers []string) {
+	r := NewResolver(servers)
+
+	ss := []string{}
+
+	for _, s := range r.Servers {
+		if host, _, err := net.SplitHostPort(s); err == nil && net.ParseIP(host).Equal(d.router.ip) {
+			continue
+		}
+
+		ss = append(ss, s)
+	}
+
+	r.Servers = ss
+
+	d.resolver = r
+}
+
+func (d *DNS) Stats() DNSStats {
+	return DNSStats{
+		Queries:  atomic.LoadInt64(&d.stats.Queries),
+		Rack:     atomic.LoadInt64(&d.stats.Rack),
+		Resolver: d.resolver.Stats(),
+		Servers:  d.resolver.Servers,
+	}
+}
+
+// registerHost answers for a host outside the router domain, such as a custom service domain
+func (d *DNS) registerHost(host string) error {
+	d.lock.Lock()
+	registered := d.domains[host]
+	d.domains[host] = true
+	d.lock.Unlock()
+
+	if registered {
+		return nil
+	}
+
+	fmt.Printf("ns=convox.router at=register host=%q\n", host)
+
+	return d.registerDomain(host)
+}
+
 func (d *DNS) registerDomain(domain string) error {
 	d.mux.HandleFunc(fmt.Sprintf("%s.", domain), d.resolveConvox)
 
@@ -50,6 +112,9 @@ func (d *DNS) registerDomain(domain string) error {
 }
 
 func (d *DNS) resolveConvox(w dns.ResponseWriter, r *dns.Msg) {
+	atomic.AddInt64(&d.stats.Queries, 1)
+	atomic.AddInt64(&d.stats.Rack, 1)
+
 	m := &dns.Msg{}
 	m.SetReply(r)
 	m.Compress = false
@@ -59,26 +124,55 @@ func (d *DNS) resolveConvox(w dns.ResponseWriter, r *dns.Msg) {
 	switch r.Opcode {
 	case dns.OpcodeQuery:
 		for _, q := range m.Question {
+			service, host := splitServiceName(strings.TrimSuffix(q.Name, "."))
+
+			ep, err := d.router.matchEndpoint(host)
+			if err != nil {
+				continue
+			}
+
+			var rrs []string
+
 			switch q.Qtype {
 			case dns.TypeA:
-				if ep, _ := d.router.matchEndpoint(strings.TrimSuffix(q.Name, ".")); ep != nil {
-					if rr, err := dns.NewRR(fmt.Sprintf("%s A %s", q.Name, ep.IP)); err == nil {
-						rr.Header().Ttl = 5
-						m.Answer = append(m.Answer, rr)
-						fmt.Printf("ns=convox.router at=resolve type=rack host=%q ip=%q\n", q.Name, ep.IP)
+				if ip := ep.IP.To4(); ip != nil {
+					rrs = append(rrs, fmt.Sprintf("%s A %s", q.Name, ip))
+				}
+			case dns.TypeAAAA:
+				// endpoints without an ipv6 address get no answer so that clients fall back to ipv4
+				if ep.IP.To4() == nil && ep.IP.To16() != nil {
+					rrs = append(rrs, fmt.Sprintf("%s AAAA %s", q.Name, ep.IP))
+				}
+			case dns.TypeSRV:
+				for _, p := range endpointProxies(ep) {
+					if service == "" || service == p.Listen.Scheme {
+						rrs = append(rrs, fmt.Sprintf("%s SRV 0 0 %d %s.", q.Name, p.Port, host))
 					}
 				}
+			case dns.TypeTXT:
+				for _, p := range endpointProxies(ep) {
+					rrs = append(rrs, fmt.Sprintf("%s TXT \"scheme=%s port=%d targets=%d\"", q.Name, p.Listen.Scheme, p.Port, p.Targets.Len()))
+				}
 			}
+
+			for _, s := range rrs {
+				if rr, err := dns.NewRR(s); err == nil {
+					rr.Header().Ttl = 5
+					m.Answer = append(m.Answer, rr)
+				}
+			}
+
+			fmt.Printf("ns=convox.router at=resolve type=rack host=%q qtype=%s answers=%d\n", q.Name, dns.TypeToString[q.Qtype], len(rrs))
 		}
 	}
 
 	w.WriteMsg(m)
 }
 
-func resolvePassthrough(w dns.ResponseWriter, r *dns.Msg) {
-	c := dns.Client{Net: "tcp"}
+func (d *DNS) resolvePassthrough(w dns.ResponseWriter, r *dns.Msg) {
+	atomic.AddInt64(&d.stats.Queries, 1)
 
-	rs, _, err := c.Exchange(r, "8.8.8.8:53")
+	rs, err := d.resolver.Exchange(r)
 	if err != nil {
 		m := &dns.Msg{}
 		m.SetRcode(r, dns.RcodeServerFailure)
@@ -89,6 +183,35 @@ func resolvePassthrough(w dns.ResponseWriter, r *dns.Msg) {
 	w.WriteMsg(rs)
 
 	for _, q := range r.Question {
-		fmt.Printf("ns=convox.router at=resolve type=forward host=%q\n", q.Name)
+		fmt.Printf("ns=convox.router at=resolve type=forward host=%q qtype=%s\n", q.Name, dns.TypeToString[q.Qtype])
+	}
+}
+
+// endpointProxies returns the proxies of an endpoint sorted by port
+func endpointProxies(ep *Endpoint) []Proxy {
+	ps := []Proxy{}
+
+	for _, p := range ep.Proxies {
+		ps = append(ps, p)
 	}
+
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Port < ps[j].Port })
+
+	return ps
+}
+
+// splitServiceName splits an srv style name like _http._tcp.web.app.convox into its service and host
+func splitServiceName(name string) (string, string) {
+	service := ""
+
+	parts := strings.Split(name, ".")
+
+	for len(parts) > 0 && strings.HasPrefix(parts[0], "_") {
+		if service == "" {
+			service = strings.TrimPrefix(parts[0], "_")
+		}
+		parts = parts[1:]
+	}
+
+	return service, strings.Join(parts, ".")
 }
@@ -0,0 +1,29 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveIPv4Endpoint(t *testing.T) {
+	r := router.NewTestRouter("")
+
+	require.NoError(t, r.Reconcile(".app.convox", []router.EndpointState{{Host: "web.app.convox"}}))
+
+	q := &dns.Msg{}
+	q.SetQuestion("web.app.convox.", dns.TypeA)
+
+	m := r.Resolve(q)
+	require.Len(t, m.Answer, 1)
+	assert.Equal(t, "127.0.0.1", m.Answer[0].(*dns.A).A.String())
+
+	q.SetQuestion("web.app.convox.", dns.TypeAAAA)
+
+	m = r.Resolve(q)
+	assert.Equal(t, dns.RcodeSuccess, m.Rcode)
+	assert.Len(t, m.Answer, 0)
+}
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/miekg/dns"
+)
+
+// the methods below expose internals to the router_test package
+
+func (r *Router) Reconcile(suffix string, states []EndpointState) error {
+	return r.reconcile(suffix, states)
+}
+
+func (r *Router) Restore() error {
+	return r.restore()
+}
+
+func (r *Router) State() []EndpointState {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.state()
+}
+
+// NewTestRouter returns a router serving from the loopback range without an interface, dns or system resolver
+func NewTestRouter(storage string) *Router {
+	ip, net, _ := net.ParseCIDR("127.0.0.1/8")
+
+	return &Router{
+		Domain:    "convox",
+		Storage:   storage,
+		access:    NewAccessLog(accessLogSize),
+		certs:     map[string]tls.Certificate{},
+		endpoints: map[string]Endpoint{},
+		ip:        ip,
+		net:       net,
+	}
+}
+
+// Resolve answers a query for an endpoint of the router
+func (r *Router) Resolve(q *dns.Msg) *dns.Msg {
+	w := &testDNSWriter{}
+
+	(&DNS{router: r}).resolveConvox(w, q)
+
+	return w.msg
+}
+
+type testDNSWriter struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (w *testDNSWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+// SetCADir keeps the certificate authorities generated by tests out of the system directory
+func SetCADir(dir string) {
+	caDir = dir
+}
+
+func (r *Router) SetCA(ca tls.Certificate) {
+	r.certLock.Lock()
+	defer r.certLock.Unlock()
+
+	r.ca = ca
+	r.certs = map[string]tls.Certificate{}
+}
+
+func (r *Router) RotateCA() error {
+	return r.rotateCA()
+}
+
+func (r *Router) Certificate(host string) (tls.Certificate, error) {
+	return r.certificate(host)
+}
+
+func CertificateExpiring(cert tls.Certificate) bool {
+	return certificateExpiring(cert)
+}
+
+// Server returns the router api authorizing requests with token
+func (r *Router) Server(token string) http.Handler {
+	r.token = token
+
+	return r.server()
+}
+
+func CACertificate(domain string) (tls.Certificate, error) {
+	return caCertificate(domain)
+}
+
+func RackClient() (rack.Rack, error) {
+	return rackClient()
+}
@@ -0,0 +1,206 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+// HTTPProxy proxies http requests to the targets of a proxy
+// upgrade requests such as websockets are passed through once the target switches protocols
+// targets with an h2c scheme are reached over cleartext http/2 which allows grpc to pass through
+// requests are answered from Pages when the endpoint is not serving or its targets fail
+type HTTPProxy struct {
+	Host   string
+	Pages  *Pages
+	Port   string
+	Scheme string
+	State  func() string
+
+	proxy *httputil.ReverseProxy
+}
+
+func NewHTTPProxy(host, scheme, port string, pool func(path string) *Pool) *HTTPProxy {
+	p := &HTTPProxy{
+		Host:   host,
+		Port:   port,
+		Scheme: scheme,
+	}
+
+	p.proxy = &httputil.ReverseProxy{
+		Director:     p.director,
+		ErrorHandler: p.error,
+		Transport:    &poolTransport{pool: pool},
+	}
+
+	return p
+}
+
+func (p *HTTPProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.State != nil {
+		switch state := p.State(); state {
+		case StateMaintenance, StateUnavailable:
+			if err := p.Pages.Render(w, r, state); err != nil {
+				logError(err)
+			}
+			return
+		}
+	}
+
+	p.proxy.ServeHTTP(w, r)
+}
+
+func (p *HTTPProxy) director(r *http.Request) {
+	r.URL.Host = p.Host
+
+	r.Header.Add("X-Forwarded-For", r.RemoteAddr)
+	r.Header.Add("X-Forwarded-Port", p.Port)
+	r.Header.Add("X-Forwarded-Proto", p.Scheme)
+}
+
+// error renders the page for a request that could not be proxied
+func (p *HTTPProxy) error(w http.ResponseWriter, r *http.Request, err error) {
+	// the client has gone away
+	if r.Context().Err() == context.Canceled {
+		return
+	}
+
+	logError(err)
+
+	page := PageBadGateway
+
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		page = PageGatewayTimeout
+	}
+
+	if err == errNoTargets {
+		page = PageUnavailable
+	}
+
+	if err := p.Pages.Render(w, r, page); err != nil {
+		logError(err)
+	}
+}
+
+// poolTransport sends each request to the next target in the pool for its path
+type poolTransport struct {
+	pool       func(path string) *Pool
+	lock       sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := t.pool(req.URL.Path).Next()
+	if err != nil {
+		return nil, err
+	}
+
+	setUpstream(req.Context(), target.URL.String())
+
+	switch target.URL.Scheme {
+	case "h2c":
+		req.URL.Scheme = "http"
+	default:
+		req.URL.Scheme = target.URL.Scheme
+	}
+
+	if target.URL.Hostname() != "rack" {
+		req.URL.Host = target.URL.Host
+	}
+
+	target.Acquire()
+
+	res, err := t.transport(target.URL).RoundTrip(req)
+	if err != nil {
+		target.Release()
+		target.Failure()
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		target.Failure()
+	default:
+		target.Success()
+	}
+
+	// the reverse proxy needs a writable body to pass through upgraded connections
+	if rwc, ok := res.Body.(io.ReadWriteCloser); ok && res.StatusCode == http.StatusSwitchingProtocols {
+		res.Body = &targetUpgradeBody{ReadWriteCloser: rwc, target: target}
+		return res, nil
+	}
+
+	res.Body = &targetBody{ReadCloser: res.Body, target: target}
+
+	return res, nil
+}
+
+func (t *poolTransport) transport(target *url.URL) http.RoundTripper {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.transports == nil {
+		t.transports = map[string]http.RoundTripper{}
+	}
+
+	if tr, ok := t.transports[target.String()]; ok {
+		return tr
+	}
+
+	var rt http.RoundTripper
+
+	switch target.Scheme {
+	case "h2c":
+		rt = &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, address string, cfg *tls.Config) (net.Conn, error) {
+				return dialTarget(target)
+			},
+		}
+	default:
+		tr := defaultTransport()
+
+		tr.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialTarget(target)
+		}
+
+		tr.ForceAttemptHTTP2 = true
+
+		rt = tr
+	}
+
+	t.transports[target.String()] = logTransport{RoundTripper: rt}
+
+	return t.transports[target.String()]
+}
+
+// targetBody releases its target when the response body is closed
+type targetBody struct {
+	io.ReadCloser
+	once   sync.Once
+	target *Target
+}
+
+func (b *targetBody) Close() error {
+	b.once.Do(b.target.Release)
+	return b.ReadCloser.Close()
+}
+
+// targetUpgradeBody releases its target when an upgraded connection is closed
+type targetUpgradeBody struct {
+	io.ReadWriteCloser
+	once   sync.Once
+	target *Target
+}
+
+func (b *targetUpgradeBody) Close() error {
+	b.once.Do(b.target.Release)
+	return b.ReadWriteCloser.Close()
+}
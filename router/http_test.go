@@ -0,0 +1,274 @@
+package router_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func TestHTTPProxyHTTP1(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", r.Proto, r.Header.Get("X-Forwarded-Proto"), r.Header.Get("X-Forwarded-Port"))
+	}))
+	defer backend.Close()
+
+	front := testHTTPProxy(t, "http://"+backend.Listener.Addr().String())
+	front.Start()
+	defer front.Close()
+
+	res, err := http.Get(front.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "HTTP/1.1 https 443", string(data))
+}
+
+func TestHTTPProxyALPN(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer backend.Close()
+
+	front := testHTTPProxy(t, "http://"+backend.Listener.Addr().String())
+	front.EnableHTTP2 = true
+	front.StartTLS()
+	defer front.Close()
+
+	tests := map[string]*http.Transport{
+		"HTTP/1.1": &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"http/1.1"}},
+		},
+		"HTTP/2.0": &http.Transport{
+			ForceAttemptHTTP2: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	for proto, tr := range tests {
+		res, err := (&http.Client{Transport: tr}).Get(front.URL)
+		require.NoError(t, err, proto)
+
+		data, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, proto, res.Proto)
+		assert.Equal(t, "ok", string(data))
+	}
+}
+
+func TestHTTPProxyWebsocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		for {
+			mt, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			ws.WriteMessage(mt, append([]byte("echo: "), data...))
+		}
+	}))
+	defer backend.Close()
+
+	front := testHTTPProxy(t, "http://"+backend.Listener.Addr().String())
+	front.Start()
+	defer front.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://"+front.Listener.Addr().String()+"/socket", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte("hello")))
+
+	_, data, err := ws.ReadMessage()
+	require.NoError(t, err)
+
+	assert.Equal(t, "echo: hello", string(data))
+}
+
+func TestHTTPProxyH2C(t *testing.T) {
+	addr := testH2CServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+
+	front := testHTTPProxy(t, "h2c://"+addr)
+	front.Start()
+	defer front.Close()
+
+	res, err := http.Get(front.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "HTTP/2.0", string(data))
+}
+
+func TestHTTPProxyGRPC(t *testing.T) {
+	addr := testH2CServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+		w.Header().Set("Grpc-Status", "0")
+	}))
+
+	front := testHTTPProxy(t, "h2c://"+addr)
+	front.EnableHTTP2 = true
+	front.StartTLS()
+	defer front.Close()
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	req, err := http.NewRequest("POST", front.URL+"/service/Method", bytes.NewReader([]byte("message")))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Te", "trailers")
+
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "HTTP/2.0", res.Proto)
+	assert.Equal(t, "application/grpc", res.Header.Get("Content-Type"))
+	assert.Equal(t, "message", string(data))
+	assert.Equal(t, "0", res.Trailer.Get("Grpc-Status"))
+}
+
+func TestHTTPProxyStates(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer backend.Close()
+
+	pool, err := router.NewPool("")
+	require.NoError(t, err)
+
+	pool.Add(testURL(t, "http://"+backend.Listener.Addr().String()))
+
+	state := router.StateMaintenance
+
+	h := router.NewHTTPProxy("web.app.convox", "https", "443", func(path string) *router.Pool { return pool })
+	h.State = func() string { return state }
+
+	front := httptest.NewServer(h)
+	defer front.Close()
+
+	res, err := http.Get(front.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, 503, res.StatusCode)
+	assert.Equal(t, "60", res.Header.Get("Retry-After"))
+
+	state = router.StateServing
+
+	res, err = http.Get(front.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestHTTPProxyErrors(t *testing.T) {
+	backend := httptest.NewServer(http.NotFoundHandler())
+	backend.Close()
+
+	tests := map[string]int{
+		"": 503,
+		"http://" + backend.Listener.Addr().String(): 502,
+	}
+
+	for target, status := range tests {
+		pool, err := router.NewPool("")
+		require.NoError(t, err)
+
+		if target != "" {
+			pool.Add(testURL(t, target))
+		}
+
+		front := httptest.NewServer(router.NewHTTPProxy("web.app.convox", "https", "443", func(path string) *router.Pool { return pool }))
+
+		req, err := http.NewRequest("GET", front.URL, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept", "application/json")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		front.Close()
+
+		assert.Equal(t, status, res.StatusCode, target)
+		assert.Contains(t, string(data), fmt.Sprintf(`"status":%d`, status))
+	}
+}
+
+func testHTTPProxy(t *testing.T, target string) *httptest.Server {
+	pool, err := router.NewPool("")
+	require.NoError(t, err)
+
+	pool.Add(testURL(t, target))
+
+	h := router.NewHTTPProxy("web.app.convox", "https", "443", func(path string) *router.Pool { return pool })
+
+	return httptest.NewUnstartedServer(h)
+}
+
+// testH2CServer serves cleartext http/2 with prior knowledge
+func testH2CServer(t *testing.T, h http.Handler) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { ln.Close() })
+
+	s := &http2.Server{}
+
+	go func() {
+		for {
+			cn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.ServeConn(cn, &http2.ServeConnOpts{Handler: h})
+		}
+	}()
+
+	return ln.Addr().String()
+}
@@ -1,13 +1,56 @@
 package router
 
 import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/convox/praxis/sdk/rack"
 )
 
+const (
+	accessLogSize = 1000
+)
+
+type contextKey int
+
+const (
+	contextAccessEntry contextKey = iota
+)
+
+var regexpRequestId = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// AccessEntry records a single request handled by a proxy
+type AccessEntry struct {
+	Time     time.Time `json:"time"`
+	Id       string    `json:"id"`
+	Host     string    `json:"host"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Status   int       `json:"status"`
+	Bytes    int64     `json:"bytes"`
+	Latency  int64     `json:"latency"` // milliseconds
+	Remote   string    `json:"remote"`
+	Upstream string    `json:"upstream"`
+}
+
+// AccessLog keeps recent access entries and fans new ones out to subscribers
+type AccessLog struct {
+	entries     []AccessEntry
+	lock        sync.Mutex
+	size        int
+	subscribers map[chan AccessEntry]bool
+}
+
 type logTransport struct {
 	http.RoundTripper
 	rack rack.Rack
@@ -19,8 +62,184 @@ func (t logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return t.RoundTripper.RoundTrip(req)
 }
 
+func NewAccessLog(size int) *AccessLog {
+	return &AccessLog{
+		entries:     []AccessEntry{},
+		size:        size,
+		subscribers: map[chan AccessEntry]bool{},
+	}
+}
+
+// Add records an entry, writes it to stdout, and sends it to subscribers
+func (l *AccessLog) Add(e AccessEntry) {
+	fmt.Println(e.Logfmt())
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.entries = append(l.entries, e)
+
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// drop entries for slow subscribers rather than blocking requests
+		}
+	}
+}
+
+// Recent returns the retained entries, oldest first
+func (l *AccessLog) Recent() []AccessEntry {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	es := make([]AccessEntry, len(l.entries))
+	copy(es, l.entries)
+
+	return es
+}
+
+// Subscribe returns a channel of new entries and a function to cancel the subscription
+func (l *AccessLog) Subscribe() (<-chan AccessEntry, func()) {
+	ch := make(chan AccessEntry, 100)
+
+	l.lock.Lock()
+	l.subscribers[ch] = true
+	l.lock.Unlock()
+
+	cancel := func() {
+		l.lock.Lock()
+		delete(l.subscribers, ch)
+		l.lock.Unlock()
+	}
+
+	return ch, cancel
+}
+
+// Wrap logs every request handled by h and ensures each carries an X-Request-Id
+// that is returned to the client and propagated to the upstream
+func (l *AccessLog) Wrap(host string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+
+		// an id from the client is written to the access log so it can only be kept if it can not forge fields
+		if !regexpRequestId.MatchString(id) {
+			id = requestId()
+			r.Header.Set("X-Request-Id", id)
+		}
+
+		w.Header().Set("X-Request-Id", id)
+
+		e := &AccessEntry{
+			Time:   time.Now().UTC(),
+			Id:     id,
+			Host:   host,
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Remote: r.RemoteAddr,
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+
+		h.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), contextAccessEntry, e)))
+
+		e.Status = sw.status
+		e.Bytes = sw.bytes
+		e.Latency = int64(time.Since(e.Time) / time.Millisecond)
+
+		if e.Status == 0 {
+			e.Status = http.StatusOK
+		}
+
+		l.Add(*e)
+	})
+}
+
+// Format renders the entry as json or logfmt
+func (e AccessEntry) Format(format string) (string, error) {
+	switch format {
+	case "", "logfmt":
+		return e.Logfmt(), nil
+	case "json":
+		data, err := json.Marshal(e)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unknown log format: %s", format)
+	}
+}
+
+func (e AccessEntry) Logfmt() string {
+	return fmt.Sprintf("ns=convox.router at=access time=%s id=%q host=%q method=%s path=%q status=%d bytes=%d latency=%dms remote=%q upstream=%q", e.Time.Format(time.RFC3339Nano), e.Id, e.Host, e.Method, e.Path, e.Status, e.Bytes, e.Latency, e.Remote, e.Upstream)
+}
+
+// setUpstream records the upstream chosen for the request in its access entry
+func setUpstream(ctx context.Context, upstream string) {
+	if e, ok := ctx.Value(contextAccessEntry).(*AccessEntry); ok {
+		e.Upstream = upstream
+	}
+}
+
+func requestId() string {
+	data := make([]byte, 16)
+
+	if _, err := rand.Read(data); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(data)
+}
+
+// statusWriter captures the status and size of a response
+type statusWriter struct {
+	http.ResponseWriter
+	bytes  int64
+	status int
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response can not be hijacked")
+	}
+
+	w.status = http.StatusSwitchingProtocols
+
+	return h.Hijack()
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
 func logError(err error) {
-	fmt.Printf("err = %+v\n", err)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
 	}
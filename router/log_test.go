@@ -0,0 +1,134 @@
+package router_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogWrap(t *testing.T) {
+	l := router.NewAccessLog(10)
+
+	upstream := ""
+
+	h := l.Wrap("web.app.convox", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream = r.Header.Get("X-Request-Id")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "hello")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/things", nil))
+
+	id := w.Header().Get("X-Request-Id")
+
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, upstream)
+
+	es := l.Recent()
+	require.Len(t, es, 1)
+
+	assert.Equal(t, id, es[0].Id)
+	assert.Equal(t, "web.app.convox", es[0].Host)
+	assert.Equal(t, "POST", es[0].Method)
+	assert.Equal(t, "/things", es[0].Path)
+	assert.Equal(t, http.StatusCreated, es[0].Status)
+	assert.Equal(t, int64(5), es[0].Bytes)
+}
+
+func TestAccessLogWrapExistingRequestId(t *testing.T) {
+	l := router.NewAccessLog(10)
+
+	h := l.Wrap("web.app.convox", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-Id", "abc123")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "abc123", w.Header().Get("X-Request-Id"))
+
+	es := l.Recent()
+	require.Len(t, es, 1)
+
+	assert.Equal(t, "abc123", es[0].Id)
+	assert.Equal(t, http.StatusOK, es[0].Status)
+}
+
+func TestAccessLogWrapInvalidRequestId(t *testing.T) {
+	l := router.NewAccessLog(10)
+
+	h := l.Wrap("web.app.convox", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, id := range []string{"abc status=500", "abc\nns=convox.router", `"abc"`, strings.Repeat("a", 65)} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Request-Id", id)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Len(t, w.Header().Get("X-Request-Id"), 32, id)
+	}
+
+	for _, e := range l.Recent() {
+		assert.Len(t, e.Id, 32)
+	}
+}
+
+func TestAccessLogSize(t *testing.T) {
+	l := router.NewAccessLog(2)
+
+	l.Add(router.AccessEntry{Id: "1"})
+	l.Add(router.AccessEntry{Id: "2"})
+	l.Add(router.AccessEntry{Id: "3"})
+
+	es := l.Recent()
+	require.Len(t, es, 2)
+
+	assert.Equal(t, "2", es[0].Id)
+	assert.Equal(t, "3", es[1].Id)
+}
+
+func TestAccessLogSubscribe(t *testing.T) {
+	l := router.NewAccessLog(10)
+
+	ch, cancel := l.Subscribe()
+
+	l.Add(router.AccessEntry{Id: "1"})
+
+	e := <-ch
+	assert.Equal(t, "1", e.Id)
+
+	cancel()
+
+	l.Add(router.AccessEntry{Id: "2"})
+
+	assert.Len(t, ch, 0)
+}
+
+func TestAccessEntryFormat(t *testing.T) {
+	e := router.AccessEntry{Id: "abc", Host: "web.app.convox", Method: "GET", Path: "/", Status: 200, Bytes: 10, Latency: 3, Upstream: "http://rack/app/process/web:3000"}
+
+	line, err := e.Format("logfmt")
+	require.NoError(t, err)
+	assert.Contains(t, line, `id="abc" host="web.app.convox" method=GET path="/" status=200 bytes=10 latency=3ms`)
+	assert.Contains(t, line, `upstream="http://rack/app/process/web:3000"`)
+
+	line, err = e.Format("json")
+	require.NoError(t, err)
+
+	var parsed router.AccessEntry
+	require.NoError(t, json.Unmarshal([]byte(line), &parsed))
+	assert.Equal(t, e, parsed)
+
+	_, err = e.Format("xml")
+	assert.EqualError(t, err, "unknown log format: xml")
+}
@@ -0,0 +1,112 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PageBadGateway     = "bad-gateway"
+	PageGatewayTimeout = "gateway-timeout"
+	PageMaintenance    = "maintenance"
+	PageUnavailable    = "unavailable"
+)
+
+// Pages renders the responses the router sends in place of a response from a target
+// custom pages are read from <dir>/<page>.html and <dir>/<page>.json and fall back to built-in defaults
+type Pages struct {
+	Dir string
+}
+
+type page struct {
+	Status  int
+	Title   string
+	Message string
+}
+
+var defaultPages = map[string]page{
+	PageBadGateway:     {http.StatusBadGateway, "Bad Gateway", "The service could not be reached."},
+	PageGatewayTimeout: {http.StatusGatewayTimeout, "Gateway Timeout", "The service did not respond in time."},
+	PageMaintenance:    {http.StatusServiceUnavailable, "Down for Maintenance", "This service is undergoing maintenance, please try again shortly."},
+	PageUnavailable:    {http.StatusServiceUnavailable, "Service Unavailable", "This service is currently unavailable."},
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <title>{{ .Title }}</title>
+  </head>
+  <body>
+    <h1>{{ .Title }}</h1>
+    <p>{{ .Message }}</p>
+  </body>
+</html>
+`))
+
+// Render writes the named page, as json if the client prefers it
+func (p *Pages) Render(w http.ResponseWriter, r *http.Request, name string) error {
+	pg, ok := defaultPages[name]
+	if !ok {
+		return fmt.Errorf("unknown page: %s", name)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if pg.Status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "60")
+	}
+
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+
+		data, ok := p.custom(name, "json")
+		if !ok {
+			d, err := json.Marshal(map[string]interface{}{"error": pg.Message, "status": pg.Status})
+			if err != nil {
+				return err
+			}
+			data = append(d, '\n')
+		}
+
+		w.WriteHeader(pg.Status)
+		_, err := w.Write(data)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if data, ok := p.custom(name, "html"); ok {
+		w.WriteHeader(pg.Status)
+		_, err := w.Write(data)
+		return err
+	}
+
+	w.WriteHeader(pg.Status)
+
+	return pageTemplate.Execute(w, pg)
+}
+
+// custom reads a custom page from the pages directory if one exists
+func (p *Pages) custom(name, ext string) ([]byte, bool) {
+	if p == nil || p.Dir == "" {
+		return nil, false
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(p.Dir, fmt.Sprintf("%s.%s", name, ext)))
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,84 @@
+package router_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagesDefault(t *testing.T) {
+	var p *router.Pages
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+
+	require.NoError(t, p.Render(w, r, router.PageMaintenance))
+
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<h1>Down for Maintenance</h1>")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json")
+
+	require.NoError(t, p.Render(w, r, router.PageBadGateway))
+
+	assert.Equal(t, 502, w.Code)
+	assert.Equal(t, "", w.Header().Get("Retry-After"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"The service could not be reached.","status":502}`, w.Body.String())
+}
+
+func TestPagesCustom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "maintenance.html"), []byte("<p>back soon</p>"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "maintenance.json"), []byte(`{"maintenance":true}`), 0644))
+
+	p := &router.Pages{Dir: dir}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+
+	require.NoError(t, p.Render(w, r, router.PageMaintenance))
+
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, "<p>back soon</p>", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json")
+
+	require.NoError(t, p.Render(w, r, router.PageMaintenance))
+
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, `{"maintenance":true}`, w.Body.String())
+
+	// pages without a custom file fall back to the default
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/", nil)
+
+	require.NoError(t, p.Render(w, r, router.PageUnavailable))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "<h1>Service Unavailable</h1>")
+}
+
+func TestPagesUnknown(t *testing.T) {
+	p := &router.Pages{}
+
+	err := p.Render(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), "missing")
+
+	assert.EqualError(t, err, "unknown page: missing")
+}
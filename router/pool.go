@@ -0,0 +1,208 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	BalanceLeastConnections = "least-connections"
+	BalanceRoundRobin       = "round-robin"
+)
+
+const (
+	targetFailureLimit    = 3
+	targetFailureCooldown = 10 * time.Second
+)
+
+var errNoTargets = errors.New("no targets available")
+
+// Pool is a set of targets that a proxy balances traffic across
+type Pool struct {
+	Balance string
+
+	lock    sync.Mutex
+	next    int
+	targets []*Target
+}
+
+// Target is a single upstream in a Pool
+type Target struct {
+	URL *url.URL
+
+	active   int64
+	down     time.Time
+	failures int
+	lock     sync.Mutex
+}
+
+func NewPool(balance string) (*Pool, error) {
+	switch balance {
+	case "":
+		balance = BalanceRoundRobin
+	case BalanceLeastConnections, BalanceRoundRobin:
+	default:
+		return nil, fmt.Errorf("unknown balance: %s", balance)
+	}
+
+	return &Pool{Balance: balance}, nil
+}
+
+// Add adds a target to the pool if it is not already present
+func (p *Pool) Add(u *url.URL) *Target {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, t := range p.targets {
+		if t.URL.String() == u.String() {
+			return t
+		}
+	}
+
+	t := &Target{URL: u}
+
+	p.targets = append(p.targets, t)
+
+	return t
+}
+
+// Len returns the number of targets in the pool
+func (p *Pool) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.targets)
+}
+
+// Next selects the target for the next request or connection
+// Targets that are failing are skipped unless no healthy targets remain
+func (p *Pool) Next() (*Target, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.targets) == 0 {
+		return nil, errNoTargets
+	}
+
+	candidates := []*Target{}
+
+	for _, t := range p.targets {
+		if t.Healthy() {
+			candidates = append(candidates, t)
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = p.targets
+	}
+
+	offset := p.next % len(candidates)
+
+	p.next++
+
+	switch p.Balance {
+	case BalanceLeastConnections:
+		best := candidates[offset]
+
+		for i := 1; i < len(candidates); i++ {
+			if t := candidates[(offset+i)%len(candidates)]; t.Active() < best.Active() {
+				best = t
+			}
+		}
+
+		return best, nil
+	default:
+		return candidates[offset], nil
+	}
+}
+
+// Remove removes a target from the pool and returns true if it was found
+func (p *Pool) Remove(target string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i, t := range p.targets {
+		if t.URL.String() == target {
+			p.targets = append(p.targets[:i], p.targets[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Targets returns a copy of the targets in the pool
+func (p *Pool) Targets() []*Target {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ts := make([]*Target, len(p.targets))
+	copy(ts, p.targets)
+
+	return ts
+}
+
+func (p *Pool) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"balance": p.Balance,
+		"targets": p.Targets(),
+	})
+}
+
+// Acquire records a new request or connection to the target
+func (t *Target) Acquire() {
+	atomic.AddInt64(&t.active, 1)
+}
+
+// Active returns the number of requests or connections in flight to the target
+func (t *Target) Active() int64 {
+	return atomic.LoadInt64(&t.active)
+}
+
+// Failure records a failed request and takes the target out of rotation
+// for a cooldown period once it fails repeatedly
+func (t *Target) Failure() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.failures++
+
+	if t.failures >= targetFailureLimit {
+		t.down = time.Now().Add(targetFailureCooldown)
+	}
+}
+
+// Healthy returns false while the target is cooling down from repeated failures
+func (t *Target) Healthy() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.down.IsZero() || time.Now().After(t.down)
+}
+
+// Release records the end of a request or connection to the target
+func (t *Target) Release() {
+	atomic.AddInt64(&t.active, -1)
+}
+
+// Success resets the failure count of the target
+func (t *Target) Success() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.failures = 0
+	t.down = time.Time{}
+}
+
+func (t *Target) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"active":  t.Active(),
+		"healthy": t.Healthy(),
+		"target":  t.URL.String(),
+	})
+}
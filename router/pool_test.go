@@ -0,0 +1,122 @@
+package router_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolRoundRobin(t *testing.T) {
+	p, err := router.NewPool("")
+	require.NoError(t, err)
+
+	assert.Equal(t, router.BalanceRoundRobin, p.Balance)
+
+	p.Add(testURL(t, "http://one:3000"))
+	p.Add(testURL(t, "http://two:3000"))
+	p.Add(testURL(t, "http://one:3000"))
+
+	assert.Equal(t, 2, p.Len())
+
+	hosts := []string{}
+
+	for i := 0; i < 4; i++ {
+		target, err := p.Next()
+		require.NoError(t, err)
+		hosts = append(hosts, target.URL.Hostname())
+	}
+
+	assert.Equal(t, []string{"one", "two", "one", "two"}, hosts)
+}
+
+func TestPoolLeastConnections(t *testing.T) {
+	p, err := router.NewPool(router.BalanceLeastConnections)
+	require.NoError(t, err)
+
+	one := p.Add(testURL(t, "http://one:3000"))
+	two := p.Add(testURL(t, "http://two:3000"))
+
+	one.Acquire()
+	one.Acquire()
+
+	for i := 0; i < 3; i++ {
+		target, err := p.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "two", target.URL.Hostname())
+	}
+
+	one.Release()
+	one.Release()
+	two.Acquire()
+
+	target, err := p.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "one", target.URL.Hostname())
+}
+
+func TestPoolFailures(t *testing.T) {
+	p, err := router.NewPool("")
+	require.NoError(t, err)
+
+	one := p.Add(testURL(t, "http://one:3000"))
+	p.Add(testURL(t, "http://two:3000"))
+
+	one.Failure()
+	one.Failure()
+	assert.True(t, one.Healthy())
+
+	one.Failure()
+	assert.False(t, one.Healthy())
+
+	for i := 0; i < 3; i++ {
+		target, err := p.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "two", target.URL.Hostname())
+	}
+
+	one.Success()
+	assert.True(t, one.Healthy())
+}
+
+func TestPoolAllUnhealthy(t *testing.T) {
+	p, err := router.NewPool("")
+	require.NoError(t, err)
+
+	one := p.Add(testURL(t, "http://one:3000"))
+
+	for i := 0; i < 3; i++ {
+		one.Failure()
+	}
+
+	target, err := p.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "one", target.URL.Hostname())
+}
+
+func TestPoolRemove(t *testing.T) {
+	p, err := router.NewPool("")
+	require.NoError(t, err)
+
+	p.Add(testURL(t, "http://one:3000"))
+
+	assert.False(t, p.Remove("http://two:3000"))
+	assert.True(t, p.Remove("http://one:3000"))
+	assert.Equal(t, 0, p.Len())
+
+	_, err = p.Next()
+	assert.EqualError(t, err, "no targets available")
+}
+
+func TestPoolInvalidBalance(t *testing.T) {
+	_, err := router.NewPool("random")
+	assert.EqualError(t, err, "unknown balance: random")
+}
+
+func testURL(t *testing.T, s string) *url.URL {
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	return u
+}
@@ -1,14 +1,12 @@
 package router
 
 import (
-	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"strconv"
 	"strings"
@@ -16,25 +14,36 @@ import (
 	"github.com/convox/praxis/helpers"
 	"github.com/convox/praxis/sdk/rack"
 	"github.com/convox/praxis/types"
-	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 
 	mrand "math/rand"
 )
 
 type Proxy struct {
-	Listen *url.URL
-	Port   int
-	Target *url.URL
+	Listen  *url.URL
+	Port    int
+	Rules   *Rules
+	Targets *Pool
 
 	endpoint *Endpoint
 	listener net.Listener
+	packet   net.PacketConn
 }
 
-func (e *Endpoint) NewProxy(host string, listen, target *url.URL) (*Proxy, error) {
+func (e *Endpoint) NewProxy(host string, listen *url.URL, balance string) (*Proxy, error) {
+	pool, err := NewPool(balance)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := NewRules(pool.Balance)
+	if err != nil {
+		return nil, err
+	}
+
 	p := &Proxy{
 		Listen:   listen,
-		Target:   target,
+		Rules:    rules,
+		Targets:  pool,
 		endpoint: e,
 	}
 
@@ -49,58 +58,154 @@ func (e *Endpoint) NewProxy(host string, listen, target *url.URL) (*Proxy, error
 		return nil, fmt.Errorf("proxy already exists for port: %d", pi)
 	}
 
-	e.Proxies[pi] = *p
-
 	return p, nil
 }
 
 func (p Proxy) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]string{
-		"listen": p.Listen.String(),
-		"target": p.Target.String(),
+	return json.Marshal(map[string]interface{}{
+		"listen":  p.Listen.String(),
+		"balance": p.Targets.Balance,
+		"paths":   p.Rules,
+		"targets": p.Targets.Targets(),
 	})
 }
 
-func (p *Proxy) Serve() error {
+// Add adds a target for a path prefix, or for all other paths when path is empty
+func (p *Proxy) Add(path string, target *url.URL) error {
+	if p.Listen.Scheme == "udp" {
+		if path != "" {
+			return fmt.Errorf("udp proxies do not support paths")
+		}
+
+		if target.Hostname() == "rack" {
+			return fmt.Errorf("udp targets must be addressed directly: %s", target)
+		}
+	}
+
+	if path == "" {
+		p.Targets.Add(target)
+		return nil
+	}
+
+	return p.Rules.Add(path, target)
+}
+
+// Len returns the number of targets across all paths
+func (p *Proxy) Len() int {
+	n := p.Targets.Len()
+
+	for _, path := range p.Rules.Paths() {
+		if pool := p.Rules.Pool(path); pool != nil {
+			n += pool.Len()
+		}
+	}
+
+	return n
+}
+
+// Remove removes a target for a path prefix, or for all other paths when path is empty
+func (p *Proxy) Remove(path, target string) bool {
+	if path == "" {
+		return p.Targets.Remove(target)
+	}
+
+	return p.Rules.Remove(path, target)
+}
+
+// pool returns the pool that should serve a request path
+func (p *Proxy) pool(path string) *Pool {
+	if pool, ok := p.Rules.Match(path); ok {
+		return pool
+	}
+
+	return p.Targets
+}
+
+// Open binds the listener for the proxy so that errors surface before it is registered
+func (p *Proxy) Open() error {
+	if p.Listen.Scheme == "udp" {
+		pc, err := net.ListenPacket("udp", p.Listen.Host)
+		if err != nil {
+			return err
+		}
+
+		p.packet = pc
+
+		return nil
+	}
+
 	ln, err := net.Listen("tcp", p.Listen.Host)
 	if err != nil {
 		return err
 	}
 
-	defer ln.Close()
-
 	switch p.Listen.Scheme {
 	case "https", "tls":
-		cert, err := p.endpoint.router.generateCertificate(p.endpoint.Host)
-		if err != nil {
+		// generate the certificate up front so that errors surface before registration
+		if _, err := p.endpoint.router.certificate(p.endpoint.Host); err != nil {
+			ln.Close()
 			return err
 		}
 
 		cfg := &tls.Config{
-			Certificates: []tls.Certificate{cert},
+			GetCertificate: p.certificate,
 		}
 
-		// TODO: check for h2
-		cfg.NextProtos = []string{"h2"}
+		cfg.NextProtos = []string{"h2", "http/1.1"}
 
 		ln = tls.NewListener(ln, cfg)
 	}
 
 	p.listener = ln
-	p.endpoint.Proxies[p.Port] = *p
 
-	switch p.Listen.Scheme {
-	case "http", "https":
-		h, err := p.proxyHTTP(p.Listen, p.Target)
-		if err != nil {
+	return nil
+}
+
+// certificate returns the certificate for the endpoint or one of its aliases
+// certificates come from the router so that renewals and rotations apply to running proxies
+func (p *Proxy) certificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := p.endpoint.Host
+	name := strings.ToLower(hello.ServerName)
+
+	if name != "" && name != host && !strings.HasSuffix(name, "."+host) {
+		if ep, err := p.endpoint.router.matchEndpoint(name); err == nil && ep.IP.Equal(p.endpoint.IP) {
+			host = name
+		}
+	}
+
+	cert, err := p.endpoint.router.certificate(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cert, nil
+}
+
+func (p *Proxy) Serve() error {
+	if p.listener == nil && p.packet == nil {
+		if err := p.Open(); err != nil {
 			return err
 		}
+	}
+
+	if p.Listen.Scheme == "udp" {
+		defer p.packet.Close()
+
+		u := NewUDPProxy(p.Targets, udpIdleTimeout)
+		u.State = p.state
 
-		if err := http.Serve(ln, h); err != nil {
+		return u.Serve(p.packet)
+	}
+
+	defer p.listener.Close()
+
+	switch p.Listen.Scheme {
+	case "http", "https":
+		if err := http.Serve(p.listener, p.proxyHTTP()); err != nil {
 			return err
 		}
 	case "tcp":
-		if err := proxyTCP(ln, p.Target); err != nil {
+		if err := proxyTCP(p.listener, p.Targets, p.state); err != nil {
 			return err
 		}
 	default:
@@ -111,87 +216,123 @@ func (p *Proxy) Serve() error {
 }
 
 func (p *Proxy) Terminate() error {
-	if err := p.listener.Close(); err != nil {
-		return err
+	if p.packet != nil {
+		if err := p.packet.Close(); err != nil {
+			return err
+		}
+	}
+
+	if p.listener != nil {
+		if err := p.listener.Close(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (p *Proxy) proxyHTTP(listen, target *url.URL) (http.Handler, error) {
-	if target.Hostname() == "rack" {
-		h, err := p.proxyRackHTTP()
-		if err != nil {
-			return nil, err
-		}
-
-		return h, nil
-	}
+func (p *Proxy) proxyHTTP() http.Handler {
+	h := NewHTTPProxy(p.endpoint.Host, p.Listen.Scheme, p.Listen.Port(), p.pool)
 
-	px := httputil.NewSingleHostReverseProxy(target)
+	h.Pages = p.endpoint.router.Pages
+	h.State = p.state
 
-	px.Transport = logTransport{RoundTripper: defaultTransport()}
+	return p.endpoint.router.access.Wrap(p.endpoint.Host, h)
+}
 
-	return px, nil
+// state returns the current state of the endpoint for the proxy
+func (p *Proxy) state() string {
+	return p.endpoint.router.endpointState(p.endpoint.Host)
 }
 
-func proxyTCP(listener net.Listener, target *url.URL) error {
+// proxyTCP accepts connections and pipes them to the pool
+// connections are closed immediately while the endpoint is not serving
+func proxyTCP(listener net.Listener, pool *Pool, state func() string) error {
 	for {
 		cn, err := listener.Accept()
 		if err != nil {
 			return err
 		}
 
-		go proxyRackTCP(cn, target)
+		if state() != StateServing {
+			cn.Close()
+			continue
+		}
+
+		go func() {
+			if err := proxyTCPConnection(cn, pool); err != nil {
+				logError(err)
+			}
+		}()
 	}
 }
 
-func proxyTCPConnection(cn net.Conn, target *url.URL) error {
-	if target.Hostname() == "rack" {
-		return proxyRackTCP(cn, target)
+func proxyTCPConnection(cn net.Conn, pool *Pool) error {
+	t, err := pool.Next()
+	if err != nil {
+		cn.Close()
+		return err
+	}
+
+	t.Acquire()
+	defer t.Release()
+
+	if t.URL.Hostname() == "rack" {
+		if err := proxyRackTCP(cn, t.URL); err != nil {
+			t.Failure()
+			return err
+		}
+
+		t.Success()
+
+		return nil
 	}
 
 	defer cn.Close()
 
-	oc, err := net.Dial("tcp", target.Host)
+	oc, err := net.Dial("tcp", t.URL.Host)
 	if err != nil {
+		t.Failure()
 		return err
 	}
 
 	defer oc.Close()
 
+	t.Success()
+
 	return helpers.Pipe(cn, oc)
 }
 
 func proxyRackTCP(cn net.Conn, target *url.URL) error {
 	defer cn.Close()
 
-	parts := strings.Split(target.Path, "/")
-
-	if len(parts) < 4 {
-		return fmt.Errorf("invalid rack endpoint: %s", target)
-	}
-
-	app := parts[1]
-	kind := parts[2]
-	rp := strings.Split(parts[3], ":")
-
-	if len(rp) < 2 {
-		return fmt.Errorf("invalid %s endpoint: %s", kind, parts[2])
+	app, kind, name, port, err := parseRackTarget(target)
+	if err != nil {
+		return err
 	}
 
-	resource := rp[0]
-
 	var pr io.ReadCloser
 
-	r, err := rack.NewFromEnv()
+	r, err := rackClient()
 	if err != nil {
 		return err
 	}
 
-	switch kind {
-	case "resource":
-		rc, err := r.ResourceProxy(app, resource, cn)
+	switch {
+	case kind == "resource" && app == "":
+		rc, err := r.SystemResourceProxy(name, cn)
+		if err != nil {
+			return err
+		}
+		pr = rc
+	case kind == "resource":
+		rc, err := r.ResourceProxy(app, name, cn)
+		if err != nil {
+			return err
+		}
+		pr = rc
+	case kind == "process":
+		rc, err := r.ProcessProxy(app, name, port, cn)
 		if err != nil {
 			return err
 		}
@@ -209,81 +350,76 @@ func proxyRackTCP(cn net.Conn, target *url.URL) error {
 	return nil
 }
 
-func (p *Proxy) proxyRackHTTP() (http.Handler, error) {
-	parts := strings.Split(p.Target.Path, "/")
+// parseRackTarget splits a rack target such as http://rack/<app>/<kind>/<name>:<port>
+// rack-level targets have no app, e.g. tcp://rack/resource/<name>:<port>
+func parseRackTarget(target *url.URL) (string, string, string, int, error) {
+	parts := strings.Split(target.Path, "/")
+
+	if len(parts) == 3 {
+		parts = []string{"", "", parts[1], parts[2]}
+	}
 
 	if len(parts) < 4 {
-		return nil, fmt.Errorf("invalid rack endpoint")
+		return "", "", "", 0, fmt.Errorf("invalid rack endpoint: %s", target)
 	}
 
 	app := parts[1]
 	kind := parts[2]
-	sp := strings.Split(parts[3], ":")
+	np := strings.Split(parts[3], ":")
 
-	if len(sp) < 2 {
-		return nil, fmt.Errorf("invalid %s endpoint: %s", kind, parts[2])
+	if len(np) < 2 {
+		return "", "", "", 0, fmt.Errorf("invalid %s endpoint: %s", kind, parts[3])
 	}
 
-	service := sp[0]
-
-	pi, err := strconv.Atoi(sp[1])
+	port, err := strconv.Atoi(np[1])
 	if err != nil {
-		return nil, err
+		return "", "", "", 0, err
 	}
 
-	rp := &httputil.ReverseProxy{Director: p.rackDirector}
+	return app, kind, np[0], port, nil
+}
 
-	switch kind {
-	case "service":
-		rp.Transport = logTransport{RoundTripper: serviceTransport(app, service, pi)}
-	default:
-		return nil, fmt.Errorf("unknown proxy type: %s", kind)
+// dialTarget opens a connection to a target, tunneling through the rack for rack targets
+func dialTarget(target *url.URL) (net.Conn, error) {
+	if target.Hostname() != "rack" {
+		return net.Dial("tcp", target.Host)
 	}
 
-	px := mux.NewRouter()
-	px.HandleFunc("/{path:.*}", p.ws(app, service, pi)).Methods("GET").Headers("Upgrade", "websocket")
-	px.Handle("/{path:.*}", rp)
-
-	return px, nil
-}
-
-func (p *Proxy) rackDirector(r *http.Request) {
-	r.URL.Host = p.endpoint.Host
-	r.URL.Scheme = p.Target.Scheme
+	app, kind, name, port, err := parseRackTarget(target)
+	if err != nil {
+		return nil, err
+	}
 
-	r.Header.Add("X-Forwarded-For", r.RemoteAddr)
-	r.Header.Add("X-Forwarded-Port", p.Listen.Port())
-	r.Header.Add("X-Forwarded-Proto", p.Listen.Scheme)
-}
+	r, err := rackClient()
+	if err != nil {
+		return nil, err
+	}
 
-func serviceTransport(app, service string, port int) http.RoundTripper {
-	tr := defaultTransport()
+	pid := ""
 
-	tr.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
-		r, err := rack.NewFromEnv()
-		if err != nil {
-			return nil, err
-		}
-
-		pss, err := r.ProcessList(app, types.ProcessListOptions{Service: service})
+	switch kind {
+	case "process":
+		pid = name
+	case "service":
+		pss, err := r.ProcessList(app, types.ProcessListOptions{Service: name})
 		if err != nil {
 			return nil, err
 		}
 
 		if len(pss) < 1 {
-			return nil, fmt.Errorf("no processes available for service: %s", service)
+			return nil, fmt.Errorf("no processes available for service: %s", name)
 		}
 
-		ps := pss[mrand.Intn(len(pss))]
-
-		a, b := net.Pipe()
+		pid = pss[mrand.Intn(len(pss))].Id
+	default:
+		return nil, fmt.Errorf("unknown proxy type: %s", kind)
+	}
 
-		go serviceProxy(r, app, ps.Id, port, a)
+	a, b := net.Pipe()
 
-		return b, nil
-	}
+	go serviceProxy(r, app, pid, port, a)
 
-	return tr
+	return b, nil
 }
 
 func serviceProxy(rk rack.Rack, app, pid string, port int, rw io.ReadWriter) error {
@@ -300,87 +436,3 @@ func serviceProxy(rk rack.Rack, app, pid string, port int, rw io.ReadWriter) err
 
 	return nil
 }
-
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-}
-
-func (p *Proxy) ws(app, service string, port int) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		frontend, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			fmt.Printf("ns=convox.router at=proxy type=ws.upgrader error=%q\n", err)
-			return
-		}
-
-		dialer := &websocket.Dialer{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}
-
-		dialer.NetDial = func(network, address string) (net.Conn, error) {
-			r, err := rack.NewFromEnv()
-			if err != nil {
-				return nil, err
-			}
-
-			pss, err := r.ProcessList(app, types.ProcessListOptions{Service: service})
-			if err != nil {
-				return nil, err
-			}
-
-			if len(pss) < 1 {
-				return nil, fmt.Errorf("no processes available for service: %s", service)
-			}
-
-			ps := pss[mrand.Intn(len(pss))]
-
-			a, b := net.Pipe()
-
-			go serviceProxy(r, app, ps.Id, port, a)
-
-			return &nopDeadlineConn{b}, nil
-		}
-
-		r.URL.Host = p.endpoint.Host
-		r.URL.Scheme = "wss"
-
-		headers := http.Header{}
-		headers.Add("X-Forwarded-For", r.RemoteAddr)
-		headers.Add("X-Forwarded-Port", p.Listen.Port())
-		headers.Add("X-Forwarded-Proto", p.Listen.Scheme)
-
-		for k, v := range r.Header {
-			// Websocket headers to skip as they are set by the dialer and duplicates aren't allowed
-			if k == "Upgrade" || k == "Connection" || k == "Sec-Websocket-Key" ||
-				k == "Sec-Websocket-Version" || k == "Sec-Websocket-Extensions" || k == "Sec-Websocket-Protocol" {
-				continue
-			}
-			for _, s := range v {
-				headers.Add(k, s)
-			}
-		}
-
-		backend, _, err := dialer.Dial(r.URL.String(), headers)
-		if err != nil {
-			fmt.Printf("ns=convox.router at=proxy type=ws.dial error=%q\n", err)
-			return
-		}
-
-		errc := make(chan error, 2)
-		cp := func(dst io.Writer, src io.Reader) {
-			_, err := io.Copy(dst, src)
-			errc <- err
-		}
-
-		go cp(frontend.UnderlyingConn(), backend.UnderlyingConn())
-		go cp(backend.UnderlyingConn(), frontend.UnderlyingConn())
-
-		if err := <-errc; err != nil {
-			fmt.Printf("ns=convox.router at=proxy type=ws.cp error=%q\n", err)
-		}
-	}
-}
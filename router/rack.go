@@ -0,0 +1,41 @@
+package router
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/types"
+)
+
+// RackCertificate is the certificate of the local rack, pinned when neither RACK_FINGERPRINT nor RACK_CA is set
+// it is read for every connection as the rack only writes it once it starts
+var RackCertificate string
+
+// rackClient returns a client for the rack that rack targets tunnel through
+func rackClient() (rack.Rack, error) {
+	r, err := rack.NewFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := r.(*rack.Client)
+	if !ok || c.Fingerprint != "" || c.CA != nil || RackCertificate == "" {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(RackCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	b, _ := pem.Decode(data)
+	if b == nil {
+		return nil, fmt.Errorf("no certificate found in %s", RackCertificate)
+	}
+
+	c.Fingerprint = types.Fingerprint(b.Bytes)
+
+	return c, nil
+}
@@ -0,0 +1,41 @@
+package router_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRackClientPinsCertificate(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"convox"}`))
+	}))
+	defer ts.Close()
+
+	fd, err := ioutil.TempFile("", "rack")
+	require.NoError(t, err)
+	defer os.Remove(fd.Name())
+
+	require.NoError(t, pem.Encode(fd, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	require.NoError(t, fd.Close())
+
+	os.Setenv("RACK_URL", ts.URL)
+	defer os.Unsetenv("RACK_URL")
+
+	router.RackCertificate = fd.Name()
+	defer func() { router.RackCertificate = "" }()
+
+	r, err := router.RackClient()
+	require.NoError(t, err)
+
+	s, err := r.SystemGet()
+	require.NoError(t, err)
+	assert.Equal(t, "convox", s.Name)
+}
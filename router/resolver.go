@@ -0,0 +1,262 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	resolverCacheMax = 10000
+	resolverMaxTTL   = 1 * time.Hour
+	resolverTimeout  = 5 * time.Second
+)
+
+// Resolver forwards queries to upstream servers and caches their answers
+type Resolver struct {
+	Servers []string
+
+	cache map[string]resolverEntry
+	lock  sync.Mutex
+	stats ResolverStats
+}
+
+// ResolverStats counts queries handled by a Resolver
+type ResolverStats struct {
+	Cached    int64 `json:"cached"`
+	Failures  int64 `json:"failures"`
+	Forwarded int64 `json:"forwarded"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+}
+
+type resolverEntry struct {
+	expires time.Time
+	msg     *dns.Msg
+	stored  time.Time
+}
+
+// NewResolver returns a resolver for the given servers, defaulting to those in /etc/resolv.conf
+func NewResolver(servers []string) *Resolver {
+	if len(servers) == 0 {
+		servers = systemResolvers()
+	}
+
+	ss := make([]string, len(servers))
+
+	for i, s := range servers {
+		ss[i] = resolverAddress(s)
+	}
+
+	return &Resolver{
+		Servers: ss,
+		cache:   map[string]resolverEntry{},
+	}
+}
+
+// Exchange answers a query from the cache or the first upstream server that responds
+func (r *Resolver) Exchange(m *dns.Msg) (*dns.Msg, error) {
+	key := resolverKey(m)
+
+	if rs := r.cached(key, m.Id); rs != nil {
+		atomic.AddInt64(&r.stats.Hits, 1)
+		return rs, nil
+	}
+
+	atomic.AddInt64(&r.stats.Misses, 1)
+
+	for _, s := range r.Servers {
+		rs, err := r.forward(m, s)
+		if err != nil {
+			continue
+		}
+
+		atomic.AddInt64(&r.stats.Forwarded, 1)
+
+		r.store(key, rs)
+
+		return rs, nil
+	}
+
+	atomic.AddInt64(&r.stats.Failures, 1)
+
+	return nil, fmt.Errorf("no resolvers responded")
+}
+
+// Stats returns a snapshot of the resolver counters
+func (r *Resolver) Stats() ResolverStats {
+	r.lock.Lock()
+	cached := int64(len(r.cache))
+	r.lock.Unlock()
+
+	return ResolverStats{
+		Cached:    cached,
+		Failures:  atomic.LoadInt64(&r.stats.Failures),
+		Forwarded: atomic.LoadInt64(&r.stats.Forwarded),
+		Hits:      atomic.LoadInt64(&r.stats.Hits),
+		Misses:    atomic.LoadInt64(&r.stats.Misses),
+	}
+}
+
+func (r *Resolver) cached(key string, id uint16) *dns.Msg {
+	if key == "" {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	e, ok := r.cache[key]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	if now.After(e.expires) {
+		delete(r.cache, key)
+		return nil
+	}
+
+	rs := e.msg.Copy()
+	rs.Id = id
+
+	age := uint32(now.Sub(e.stored) / time.Second)
+
+	for _, rrs := range [][]dns.RR{rs.Answer, rs.Ns, rs.Extra} {
+		for _, rr := range rrs {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+
+			if rr.Header().Ttl > age {
+				rr.Header().Ttl -= age
+			} else {
+				rr.Header().Ttl = 0
+			}
+		}
+	}
+
+	return rs
+}
+
+func (r *Resolver) forward(m *dns.Msg, server string) (*dns.Msg, error) {
+	c := dns.Client{Net: "udp", Timeout: resolverTimeout}
+
+	rs, _, err := c.Exchange(m, server)
+	if err != nil {
+		return nil, err
+	}
+
+	if rs.Truncated {
+		c.Net = "tcp"
+
+		rs, _, err = c.Exchange(m, server)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rs, nil
+}
+
+func (r *Resolver) store(key string, m *dns.Msg) {
+	if key == "" {
+		return
+	}
+
+	switch m.Rcode {
+	case dns.RcodeSuccess, dns.RcodeNameError:
+	default:
+		return
+	}
+
+	ttl, ok := resolverTTL(m)
+	if !ok || ttl == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.cache) >= resolverCacheMax {
+		for k, e := range r.cache {
+			if now.After(e.expires) {
+				delete(r.cache, k)
+			}
+		}
+
+		if len(r.cache) >= resolverCacheMax {
+			return
+		}
+	}
+
+	r.cache[key] = resolverEntry{
+		expires: now.Add(ttl),
+		msg:     m.Copy(),
+		stored:  now,
+	}
+}
+
+// resolverAddress adds the default port to a server address if it has none
+func resolverAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+
+	return net.JoinHostPort(server, "53")
+}
+
+func resolverKey(m *dns.Msg) string {
+	if len(m.Question) != 1 {
+		return ""
+	}
+
+	q := m.Question[0]
+
+	return fmt.Sprintf("%s/%d/%d", strings.ToLower(q.Name), q.Qtype, q.Qclass)
+}
+
+// resolverTTL returns the lowest ttl of the records in a message
+func resolverTTL(m *dns.Msg) (time.Duration, bool) {
+	ttl := resolverMaxTTL
+	found := false
+
+	for _, rrs := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		for _, rr := range rrs {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+
+			if t := time.Duration(rr.Header().Ttl) * time.Second; t < ttl {
+				ttl = t
+			}
+
+			found = true
+		}
+	}
+
+	return ttl, found
+}
+
+func systemResolvers() []string {
+	cc, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil || len(cc.Servers) == 0 {
+		return []string{"8.8.8.8:53"}
+	}
+
+	ss := []string{}
+
+	for _, s := range cc.Servers {
+		ss = append(ss, net.JoinHostPort(s, cc.Port))
+	}
+
+	return ss
+}
@@ -0,0 +1,129 @@
+package router_test
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverCache(t *testing.T) {
+	addr, queries := testDNSServer(t, 60)
+
+	r := router.NewResolver([]string{addr})
+
+	m := &dns.Msg{}
+	m.SetQuestion("example.org.", dns.TypeA)
+
+	rs, err := r.Exchange(m)
+	require.NoError(t, err)
+	require.Len(t, rs.Answer, 1)
+	assert.Equal(t, "10.0.0.1", rs.Answer[0].(*dns.A).A.String())
+
+	m.Id = 1234
+
+	rs, err = r.Exchange(m)
+	require.NoError(t, err)
+	require.Len(t, rs.Answer, 1)
+	assert.Equal(t, uint16(1234), rs.Id)
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(queries))
+
+	s := r.Stats()
+	assert.Equal(t, int64(1), s.Cached)
+	assert.Equal(t, int64(1), s.Forwarded)
+	assert.Equal(t, int64(1), s.Hits)
+	assert.Equal(t, int64(1), s.Misses)
+	assert.Equal(t, int64(0), s.Failures)
+}
+
+func TestResolverNoCacheZeroTTL(t *testing.T) {
+	addr, queries := testDNSServer(t, 0)
+
+	r := router.NewResolver([]string{addr})
+
+	m := &dns.Msg{}
+	m.SetQuestion("example.org.", dns.TypeA)
+
+	for i := 0; i < 2; i++ {
+		_, err := r.Exchange(m)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(2), atomic.LoadInt64(queries))
+	assert.Equal(t, int64(0), r.Stats().Cached)
+}
+
+func TestResolverFailover(t *testing.T) {
+	addr, _ := testDNSServer(t, 60)
+
+	dead, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	dead.Close()
+
+	r := router.NewResolver([]string{dead.LocalAddr().String(), addr})
+
+	m := &dns.Msg{}
+	m.SetQuestion("example.org.", dns.TypeA)
+
+	rs, err := r.Exchange(m)
+	require.NoError(t, err)
+	assert.Len(t, rs.Answer, 1)
+}
+
+func TestResolverFailure(t *testing.T) {
+	dead, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	dead.Close()
+
+	r := router.NewResolver([]string{dead.LocalAddr().String()})
+
+	m := &dns.Msg{}
+	m.SetQuestion("example.org.", dns.TypeA)
+
+	_, err = r.Exchange(m)
+	assert.EqualError(t, err, "no resolvers responded")
+	assert.Equal(t, int64(1), r.Stats().Failures)
+}
+
+func TestResolverServers(t *testing.T) {
+	r := router.NewResolver([]string{"1.1.1.1", "9.9.9.9:5353", "::1"})
+
+	assert.Equal(t, []string{"1.1.1.1:53", "9.9.9.9:5353", "[::1]:53"}, r.Servers)
+}
+
+func testDNSServer(t *testing.T, ttl uint32) (string, *int64) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var queries int64
+
+	mux := dns.NewServeMux()
+
+	mux.HandleFunc(".", func(w dns.ResponseWriter, r *dns.Msg) {
+		atomic.AddInt64(&queries, 1)
+
+		m := &dns.Msg{}
+		m.SetReply(r)
+
+		rr, err := dns.NewRR(r.Question[0].Name + " A 10.0.0.1")
+		require.NoError(t, err)
+
+		rr.Header().Ttl = ttl
+		m.Answer = append(m.Answer, rr)
+
+		w.WriteMsg(m)
+	})
+
+	s := &dns.Server{PacketConn: pc, Handler: mux}
+
+	go s.ActivateAndServe()
+
+	t.Cleanup(func() { s.Shutdown() })
+
+	return pc.LocalAddr().String(), &queries
+}
@@ -9,7 +9,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -22,11 +21,19 @@ const (
 	cleanupAge      = 60 * time.Second
 )
 
+const (
+	StateMaintenance = "maintenance"
+	StateServing     = "serving"
+	StateUnavailable = "unavailable"
+)
+
 type Endpoint struct {
+	Aliases []string      `json:"aliases"`
 	Expires time.Time     `json:"expires"`
 	Host    string        `json:"host"`
 	IP      net.IP        `json:"ip"`
 	Proxies map[int]Proxy `json:"proxies"`
+	State   string        `json:"state"`
 
 	router *Router
 }
@@ -34,15 +41,23 @@ type Endpoint struct {
 type Router struct {
 	Domain    string
 	Interface string
+	Pages     *Pages
+	Resolvers []string
+	Storage   string
 	Subnet    string
 	Version   string
 
+	access    *AccessLog
 	ca        tls.Certificate
+	certLock  sync.Mutex
+	certs     map[string]tls.Certificate
 	dns       *DNS
 	endpoints map[string]Endpoint
 	lock      sync.Mutex
 	ip        net.IP
 	net       *net.IPNet
+	saved     []byte
+	token     string
 }
 
 func New(version, domain, iface, subnet string) (*Router, error) {
@@ -56,18 +71,27 @@ func New(version, domain, iface, subnet string) (*Router, error) {
 		Interface: iface,
 		Subnet:    subnet,
 		Version:   version,
+		access:    NewAccessLog(accessLogSize),
+		certs:     map[string]tls.Certificate{},
 		endpoints: map[string]Endpoint{},
 		ip:        ip,
 		net:       net,
 	}
 
-	ca, err := caCertificate()
+	ca, err := caCertificate(r.Domain)
 	if err != nil {
 		return nil, err
 	}
 
 	r.ca = ca
 
+	token, err := routerToken()
+	if err != nil {
+		return nil, err
+	}
+
+	r.token = token
+
 	d, err := r.NewDNS()
 	if err != nil {
 		return nil, err
@@ -75,8 +99,9 @@ func New(version, domain, iface, subnet string) (*Router, error) {
 
 	r.dns = d
 
-	fmt.Printf("ns=convox.router at=new version=%q domain=%q iface=%q subnet=%q\n", r.Version, r.Domain, r.Interface, r.Subnet)
+	fmt.Printf("ns=convox.router at=new version=%q domain=%q iface=%q subnet=%q ca=%q\n", r.Version, r.Domain, r.Interface, r.Subnet, Fingerprint(r.ca.Certificate[0]))
 
+	go r.caTick()
 	go r.cleanupTick()
 
 	return r, nil
@@ -96,39 +121,62 @@ func (r *Router) Serve() error {
 
 	rh := fmt.Sprintf("rack.%s", r.Domain)
 
-	ep, err := r.createEndpoint(rh, true)
-	if err != nil {
+	if _, err := r.createEndpoint(rh, true, nil); err != nil {
 		return err
 	}
 
-	if _, err := r.createProxy(rh, fmt.Sprintf("https://%s:443", ep.IP), "https://localhost:5443"); err != nil {
+	if _, err := r.createProxy(rh, 443, "https", "", "https://localhost:5443", ""); err != nil {
 		return err
 	}
 
+	if err := r.restore(); err != nil {
+		logError(err)
+	}
+
+	r.dns.SetResolvers(r.Resolvers)
+
 	go func() {
 		logError(r.dns.Serve())
 	}()
 
+	a := r.server()
+
+	if err := a.Listen("https", fmt.Sprintf("%s:443", r.ip)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// server returns the router api
+func (r *Router) server() *api.Server {
 	a := api.New("convox.router", fmt.Sprintf("router.%s", r.Domain))
 
+	a.Route("GET", "/ca", r.CAGet)
+	a.Route("POST", "/ca/rotate", r.authorize(r.CARotate))
+	a.Route("GET", "/dns", r.DNSStats)
 	a.Route("GET", "/endpoints", r.EndpointList)
+	a.Route("PUT", "/endpoints", r.EndpointReconcile)
 	a.Route("POST", "/endpoints/{host}", r.EndpointCreate)
 	a.Route("DELETE", "/endpoints/{host}", r.EndpointDelete)
 	a.Route("POST", "/endpoints/{host}/proxies/{port}", r.ProxyCreate)
+	a.Route("DELETE", "/endpoints/{host}/proxies/{port}", r.ProxyDelete)
+	a.Route("POST", "/endpoints/{host}/state", r.EndpointState)
+	a.Route("GET", "/logs", r.LogsGet)
 	a.Route("POST", "/terminate", r.Terminate)
 	a.Route("GET", "/version", r.VersionGet)
 
-	if err := a.Listen("https", fmt.Sprintf("%s:443", r.ip)); err != nil {
-		return err
-	}
-
-	return nil
+	return a
 }
 
 func (r *Router) cleanupTick() {
 	tick := time.Tick(cleanupInterval)
 
 	for range tick {
+		r.lock.Lock()
+
+		removed := false
+
 		for host, ep := range r.endpoints {
 			if ep.Expires.IsZero() {
 				continue
@@ -137,37 +185,224 @@ func (r *Router) cleanupTick() {
 			if ep.Expires.Before(time.Now()) {
 				fmt.Printf("ns=convox.router at=cleanup endpoint=%q\n", host)
 
-				if err := r.destroyEndpoint(host); err != nil {
+				if err := r.removeEndpoint(host); err != nil {
 					logError(err)
+					continue
 				}
+
+				removed = true
 			}
 		}
+
+		if removed {
+			if err := r.save(); err != nil {
+				logError(err)
+			}
+		}
+
+		r.lock.Unlock()
+	}
+}
+
+func (r *Router) createEndpoint(host string, system bool, aliases []string) (*Endpoint, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ep, err := r.addEndpoint(host, nil, system, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.save(); err != nil {
+		logError(err)
+	}
+
+	return ep, nil
+}
+
+func (r *Router) destroyEndpoint(host string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.removeEndpoint(host); err != nil {
+		return err
+	}
+
+	if err := r.save(); err != nil {
+		logError(err)
+	}
+
+	return nil
+}
+
+// setEndpointState changes whether an endpoint is serving traffic
+func (r *Router) setEndpointState(host, state string) (*Endpoint, error) {
+	switch state {
+	case StateMaintenance, StateServing, StateUnavailable:
+	default:
+		return nil, fmt.Errorf("unknown endpoint state: %s", state)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ep, ok := r.endpoints[host]
+	if !ok {
+		return nil, fmt.Errorf("no such endpoint: %s", host)
+	}
+
+	ep.State = state
+
+	r.endpoints[host] = ep
+
+	fmt.Printf("ns=convox.router at=state host=%q state=%q\n", host, state)
+
+	if err := r.save(); err != nil {
+		logError(err)
+	}
+
+	return &ep, nil
+}
+
+// endpointState returns the state of an endpoint
+func (r *Router) endpointState(host string) string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if ep, ok := r.endpoints[host]; ok && ep.State != "" {
+		return ep.State
+	}
+
+	return StateServing
+}
+
+// matchEndpoint returns a snapshot of the endpoint for a host or its parent
+func (r *Router) matchEndpoint(host string) (*Endpoint, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ep, ok := r.endpoints[host]
+
+	if !ok {
+		for _, e := range r.endpoints {
+			for _, a := range e.Aliases {
+				if a == host {
+					ep, ok = e, true
+				}
+			}
+		}
+	}
+
+	if !ok {
+		parts := strings.Split(host, ".")
+
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("no such endpoint: %s", host)
+		}
+
+		base := strings.Join(parts[len(parts)-3:len(parts)], ".")
+
+		if ep, ok = r.endpoints[base]; !ok {
+			return nil, fmt.Errorf("no such endpoint: %s", host)
+		}
+	}
+
+	ps := map[int]Proxy{}
+
+	for port, p := range ep.Proxies {
+		ps[port] = p
+	}
+
+	ep.Proxies = ps
+
+	return &ep, nil
+}
+
+// createProxy creates a proxy on the given port, or adds target to an existing proxy
+// targets with a path only receive requests under that path prefix
+func (r *Router) createProxy(host string, port int, scheme, path, target, balance string) (*Proxy, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	p, err := r.addProxy(host, port, scheme, path, target, balance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.save(); err != nil {
+		logError(err)
 	}
+
+	return p, nil
 }
 
-func (r *Router) createEndpoint(host string, system bool) (*Endpoint, error) {
+// destroyProxy removes target from a proxy, terminating the proxy
+// when no target is specified or its last target is removed
+func (r *Router) destroyProxy(host string, port int, path, target string) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	if err := r.removeProxy(host, port, path, target); err != nil {
+		return err
+	}
+
+	if err := r.save(); err != nil {
+		logError(err)
+	}
+
+	return nil
+}
+
+// addEndpoint creates an endpoint, preferring ip if it is available
+// aliases replace those of an existing endpoint when specified
+// the caller must hold r.lock
+func (r *Router) addEndpoint(host string, ip net.IP, system bool, aliases []string) (*Endpoint, error) {
+	for h, e := range r.endpoints {
+		if h == host {
+			continue
+		}
+
+		for _, a := range aliases {
+			if a == h || contains(e.Aliases, a) {
+				return nil, fmt.Errorf("host already in use: %s", a)
+			}
+		}
+	}
+
+	r.registerHosts(append([]string{host}, aliases...))
+
 	if ep, ok := r.endpoints[host]; ok {
-		ep.Expires = time.Now().Add(cleanupAge).UTC()
+		if !ep.Expires.IsZero() {
+			ep.Expires = time.Now().Add(cleanupAge).UTC()
+		}
+		if len(aliases) > 0 {
+			ep.Aliases = aliases
+		}
 		r.endpoints[host] = ep
 		return &ep, nil
 	}
 
-	ip, err := r.nextIP()
-	if err != nil {
-		return nil, err
+	if ip == nil || !r.net.Contains(ip) || r.hasIP(ip) {
+		nip, err := r.nextIP()
+		if err != nil {
+			return nil, err
+		}
+		ip = nip
 	}
 
-	if err := createAlias(r.Interface, ip.String()); err != nil {
-		return nil, err
+	// a router without an interface serves from addresses that are already up such as the loopback range
+	if r.Interface != "" {
+		if err := createAlias(r.Interface, ip.String()); err != nil {
+			return nil, err
+		}
 	}
 
 	e := Endpoint{
+		Aliases: aliases,
 		Host:    host,
 		IP:      ip,
 		Proxies: map[int]Proxy{},
+		State:   StateServing,
 		router:  r,
 	}
 
@@ -180,77 +415,98 @@ func (r *Router) createEndpoint(host string, system bool) (*Endpoint, error) {
 	return &e, nil
 }
 
-func (r *Router) destroyEndpoint(host string) error {
-	if ep, ok := r.endpoints[host]; ok {
-		for _, p := range ep.Proxies {
-			if err := p.Terminate(); err != nil {
-				logError(err)
-			}
-		}
-		delete(r.endpoints, host)
-		return nil
-	}
-
-	return fmt.Errorf("no such endpoint: %s", host)
-}
-
-func (r *Router) matchEndpoint(host string) (*Endpoint, error) {
-	if ep, ok := r.endpoints[host]; ok {
-		return &ep, nil
+// removeEndpoint terminates all proxies for an endpoint and removes it
+// the caller must hold r.lock
+func (r *Router) removeEndpoint(host string) error {
+	ep, ok := r.endpoints[host]
+	if !ok {
+		return fmt.Errorf("no such endpoint: %s", host)
 	}
 
-	parts := strings.Split(host, ".")
-
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("no such endpoint: %s", host)
+	for _, p := range ep.Proxies {
+		if err := p.Terminate(); err != nil {
+			logError(err)
+		}
 	}
 
-	base := strings.Join(parts[len(parts)-3:len(parts)], ".")
-	ep := r.endpoints[base]
+	delete(r.endpoints, host)
 
-	return &ep, nil
+	return nil
 }
 
-func (r *Router) createProxy(host, listen, target string) (*Proxy, error) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-
+// addProxy starts a proxy or adds target to an existing one
+// the caller must hold r.lock
+func (r *Router) addProxy(host string, port int, scheme, path, target, balance string) (*Proxy, error) {
 	ep, ok := r.endpoints[host]
 	if !ok {
 		return nil, fmt.Errorf("no such endpoint: %s", host)
 	}
 
-	ul, err := url.Parse(listen)
+	ut, err := url.Parse(target)
 	if err != nil {
 		return nil, err
 	}
 
-	ut, err := url.Parse(target)
+	if p, ok := ep.Proxies[port]; ok {
+		if err := p.Add(path, ut); err != nil {
+			return nil, err
+		}
+		return &p, nil
+	}
+
+	ul, err := url.Parse(fmt.Sprintf("%s://%s:%d", scheme, ep.IP, port))
 	if err != nil {
 		return nil, err
 	}
 
-	pi, err := strconv.Atoi(ul.Port())
+	p, err := ep.NewProxy(host, ul, balance)
 	if err != nil {
 		return nil, err
 	}
 
-	if p, ok := r.endpoints[host].Proxies[pi]; ok {
-		return &p, nil
+	if err := p.Add(path, ut); err != nil {
+		return nil, err
 	}
 
-	p, err := ep.NewProxy(host, ul, ut)
-	if err != nil {
+	if err := p.Open(); err != nil {
 		return nil, err
 	}
 
-	r.endpoints[host].Proxies[pi] = *p
+	ep.Proxies[port] = *p
 
 	go p.Serve()
 
 	return p, nil
 }
 
+// removeProxy removes target from a proxy, terminating it when empty
+// the caller must hold r.lock
+func (r *Router) removeProxy(host string, port int, path, target string) error {
+	ep, ok := r.endpoints[host]
+	if !ok {
+		return fmt.Errorf("no such endpoint: %s", host)
+	}
+
+	p, ok := ep.Proxies[port]
+	if !ok {
+		return fmt.Errorf("no such proxy: %s:%d", host, port)
+	}
+
+	if target != "" {
+		if !p.Remove(path, target) {
+			return fmt.Errorf("no such target: %s", target)
+		}
+
+		if p.Len() > 0 {
+			return nil
+		}
+	}
+
+	delete(ep.Proxies, port)
+
+	return p.Terminate()
+}
+
 func (r *Router) hasIP(ip net.IP) bool {
 	for _, e := range r.endpoints {
 		if e.IP.Equal(ip) {
@@ -276,6 +532,33 @@ func (r *Router) nextIP() (net.IP, error) {
 	return ip, nil
 }
 
+// registerHosts serves dns for hosts outside the router domain
+func (r *Router) registerHosts(hosts []string) {
+	if r.dns == nil {
+		return
+	}
+
+	for _, h := range hosts {
+		if h == r.Domain || strings.HasSuffix(h, "."+r.Domain) {
+			continue
+		}
+
+		go func(host string) {
+			logError(r.dns.registerHost(host))
+		}(h)
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 func incrementIP(ip net.IP) net.IP {
 	for i := len(ip) - 1; i >= 0; i-- {
 		ip[i]++
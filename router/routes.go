@@ -1,18 +1,50 @@
 package router
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/convox/praxis/api"
 )
 
+func (rt *Router) CAGet(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	ca, err := rt.CA()
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(ca)
+}
+
+func (rt *Router) CARotate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	if err := rt.rotateCA(); err != nil {
+		return err
+	}
+
+	ca, err := rt.CA()
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(ca)
+}
+
+func (rt *Router) DNSStats(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	return c.RenderJSON(rt.dns.Stats())
+}
+
 func (rt *Router) EndpointCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 	host := c.Var("host")
 
-	ep, err := rt.createEndpoint(host, false)
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	ep, err := rt.createEndpoint(host, false, r.Form["alias"])
 	if err != nil {
 		return err
 	}
@@ -27,21 +59,97 @@ func (rt *Router) EndpointDelete(w http.ResponseWriter, r *http.Request, c *api.
 }
 
 func (rt *Router) EndpointList(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
 	return c.RenderJSON(rt.endpoints)
 }
 
+func (rt *Router) EndpointReconcile(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	var states []EndpointState
+
+	if err := json.NewDecoder(r.Body).Decode(&states); err != nil {
+		return err
+	}
+
+	suffix := ""
+
+	if rack := c.Query("rack"); rack != "" {
+		suffix = fmt.Sprintf(".%s", rack)
+	}
+
+	if err := rt.reconcile(suffix, states); err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func (rt *Router) EndpointState(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	host := c.Var("host")
+	state := c.Form("state")
+
+	ep, err := rt.setEndpointState(host, state)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(ep)
+}
+
+func (rt *Router) LogsGet(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	format := c.Query("format")
+
+	for _, e := range rt.access.Recent() {
+		line, err := e.Format(format)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(w, line)
+	}
+
+	if c.Query("follow") != "true" {
+		return nil
+	}
+
+	ch, cancel := rt.access.Subscribe()
+	defer cancel()
+
+	for {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		select {
+		case <-r.Context().Done():
+			return nil
+		case e := <-ch:
+			line, err := e.Format(format)
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
 func (rt *Router) ProxyCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 	host := c.Var("host")
-	port := c.Var("port")
 	scheme := c.Form("scheme")
+	path := c.Form("path")
 	target := c.Form("target")
+	balance := c.Form("balance")
 
-	ep, ok := rt.endpoints[host]
-	if !ok {
-		return fmt.Errorf("no such endpoint: %s", host)
+	port, err := strconv.Atoi(c.Var("port"))
+	if err != nil {
+		return err
 	}
 
-	p, err := rt.createProxy(host, fmt.Sprintf("%s://%s:%s", scheme, ep.IP, port), target)
+	p, err := rt.createProxy(host, port, scheme, path, target, balance)
 	if err != nil {
 		return err
 	}
@@ -49,6 +157,23 @@ func (rt *Router) ProxyCreate(w http.ResponseWriter, r *http.Request, c *api.Con
 	return c.RenderJSON(p)
 }
 
+func (rt *Router) ProxyDelete(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	host := c.Var("host")
+	path := c.Form("path")
+	target := c.Form("target")
+
+	port, err := strconv.Atoi(c.Var("port"))
+	if err != nil {
+		return err
+	}
+
+	if err := rt.destroyProxy(host, port, path, target); err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
 func (rt *Router) Terminate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 	go func() {
 		time.Sleep(1 * time.Second)
@@ -0,0 +1,149 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Rules routes requests on a proxy to pools of targets by path prefix
+type Rules struct {
+	Balance string
+
+	lock  sync.Mutex
+	pools map[string]*Pool
+}
+
+func NewRules(balance string) (*Rules, error) {
+	if _, err := NewPool(balance); err != nil {
+		return nil, err
+	}
+
+	return &Rules{Balance: balance, pools: map[string]*Pool{}}, nil
+}
+
+// Add adds a target to the pool for a path prefix
+func (r *Rules) Add(path string, u *url.URL) error {
+	path, err := rulePath(path)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	pool, ok := r.pools[path]
+	if !ok {
+		p, err := NewPool(r.Balance)
+		if err != nil {
+			return err
+		}
+		pool = p
+		r.pools[path] = pool
+	}
+
+	pool.Add(u)
+
+	return nil
+}
+
+// Len returns the number of path prefixes
+func (r *Rules) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.pools)
+}
+
+// Match returns the pool for the longest prefix that matches path
+func (r *Rules) Match(path string) (*Pool, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	best := ""
+	var pool *Pool
+
+	for prefix, p := range r.pools {
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+
+		if pool == nil || len(prefix) > len(best) {
+			best = prefix
+			pool = p
+		}
+	}
+
+	return pool, pool != nil
+}
+
+// Paths returns the path prefixes in order
+func (r *Rules) Paths() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ps := []string{}
+
+	for p := range r.pools {
+		ps = append(ps, p)
+	}
+
+	sort.Strings(ps)
+
+	return ps
+}
+
+// Pool returns the pool for an exact path prefix
+func (r *Rules) Pool(path string) *Pool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.pools[strings.TrimSuffix(path, "/")]
+}
+
+// Remove removes a target from the pool for a path prefix, dropping the prefix when it is empty
+func (r *Rules) Remove(path, target string) bool {
+	path = strings.TrimSuffix(path, "/")
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	pool, ok := r.pools[path]
+	if !ok {
+		return false
+	}
+
+	if !pool.Remove(target) {
+		return false
+	}
+
+	if pool.Len() == 0 {
+		delete(r.pools, path)
+	}
+
+	return true
+}
+
+func (r *Rules) MarshalJSON() ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return json.Marshal(r.pools)
+}
+
+func rulePath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("path must begin with /: %s", path)
+	}
+
+	path = strings.TrimSuffix(path, "/")
+
+	if path == "" {
+		return "", fmt.Errorf("path must not be /")
+	}
+
+	return path, nil
+}
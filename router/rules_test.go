@@ -0,0 +1,67 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesMatch(t *testing.T) {
+	r, err := router.NewRules("")
+	require.NoError(t, err)
+
+	require.NoError(t, r.Add("/api", testURL(t, "http://api:3000")))
+	require.NoError(t, r.Add("/api/v2/", testURL(t, "http://api2:3000")))
+
+	assert.Equal(t, []string{"/api", "/api/v2"}, r.Paths())
+
+	tests := map[string]string{
+		"/api":         "api",
+		"/api/":        "api",
+		"/api/users":   "api",
+		"/api/v2":      "api2",
+		"/api/v2/user": "api2",
+	}
+
+	for path, host := range tests {
+		pool, ok := r.Match(path)
+		require.True(t, ok, path)
+
+		target, err := pool.Next()
+		require.NoError(t, err)
+		assert.Equal(t, host, target.URL.Hostname(), path)
+	}
+
+	for _, path := range []string{"/", "/apis", "/other/api"} {
+		_, ok := r.Match(path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestRulesRemove(t *testing.T) {
+	r, err := router.NewRules("")
+	require.NoError(t, err)
+
+	require.NoError(t, r.Add("/api", testURL(t, "http://one:3000")))
+	require.NoError(t, r.Add("/api", testURL(t, "http://two:3000")))
+
+	assert.Equal(t, 1, r.Len())
+	assert.Equal(t, 2, r.Pool("/api").Len())
+
+	assert.True(t, r.Remove("/api", "http://one:3000"))
+	assert.False(t, r.Remove("/api", "http://one:3000"))
+	assert.False(t, r.Remove("/other", "http://two:3000"))
+
+	assert.True(t, r.Remove("/api/", "http://two:3000"))
+	assert.Equal(t, 0, r.Len())
+}
+
+func TestRulesInvalidPath(t *testing.T) {
+	r, err := router.NewRules("")
+	require.NoError(t, err)
+
+	assert.EqualError(t, r.Add("api", testURL(t, "http://api:3000")), "path must begin with /: api")
+	assert.EqualError(t, r.Add("/", testURL(t, "http://api:3000")), "path must not be /")
+}
@@ -0,0 +1,308 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EndpointState is the desired state of an endpoint and its proxies
+type EndpointState struct {
+	Aliases []string     `json:"aliases,omitempty"`
+	Host    string       `json:"host"`
+	IP      net.IP       `json:"ip,omitempty"`
+	Proxies []ProxyState `json:"proxies"`
+	State   string       `json:"state,omitempty"`
+}
+
+// ProxyState is the desired state of a proxy on an endpoint
+type ProxyState struct {
+	Port    int                 `json:"port"`
+	Scheme  string              `json:"scheme"`
+	Balance string              `json:"balance,omitempty"`
+	Paths   map[string][]string `json:"paths,omitempty"`
+	Targets []string            `json:"targets"`
+}
+
+// reconcile replaces all non-system endpoints under the given domain suffix with the desired state
+// all states are validated before any changes are made
+func (r *Router) reconcile(suffix string, states []EndpointState) error {
+	desired := map[string]EndpointState{}
+
+	for _, s := range states {
+		if s.Host == "" {
+			return fmt.Errorf("host required")
+		}
+
+		switch s.State {
+		case "", StateMaintenance, StateServing, StateUnavailable:
+		default:
+			return fmt.Errorf("unknown endpoint state: %s", s.State)
+		}
+
+		if !strings.HasSuffix(s.Host, suffix) {
+			return fmt.Errorf("endpoint not in scope: %s", s.Host)
+		}
+
+		for _, ps := range s.Proxies {
+			switch ps.Scheme {
+			case "http", "https", "tcp", "udp":
+			default:
+				return fmt.Errorf("unknown listener scheme: %s", ps.Scheme)
+			}
+
+			if ps.Scheme == "udp" && len(ps.Paths) > 0 {
+				return fmt.Errorf("udp proxies do not support paths")
+			}
+
+			if _, err := NewPool(ps.Balance); err != nil {
+				return err
+			}
+
+			for path, ts := range ps.Paths {
+				if _, err := rulePath(path); err != nil {
+					return err
+				}
+
+				for _, t := range ts {
+					if _, err := url.Parse(t); err != nil {
+						return err
+					}
+				}
+			}
+
+			for _, t := range ps.Targets {
+				if _, err := url.Parse(t); err != nil {
+					return err
+				}
+			}
+		}
+
+		desired[s.Host] = s
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var errs []error
+
+	for host, ep := range r.endpoints {
+		if ep.Expires.IsZero() || !strings.HasSuffix(host, suffix) {
+			continue
+		}
+
+		if _, ok := desired[host]; !ok {
+			if err := r.removeEndpoint(host); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, s := range states {
+		if ep, ok := r.endpoints[s.Host]; ok && ep.Expires.IsZero() {
+			continue
+		}
+
+		ep, err := r.addEndpoint(s.Host, s.IP, false, s.Aliases)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// endpoints keep their state unless one is specified
+		if s.State != "" {
+			ep.State = s.State
+			r.endpoints[s.Host] = *ep
+		}
+
+		proxies := map[int]ProxyState{}
+
+		for _, ps := range s.Proxies {
+			proxies[ps.Port] = ps
+		}
+
+		for port, p := range ep.Proxies {
+			ps, ok := proxies[port]
+
+			balance := ps.Balance
+			if balance == "" {
+				balance = BalanceRoundRobin
+			}
+
+			if !ok || ps.Scheme != p.Listen.Scheme || balance != p.Targets.Balance {
+				if err := r.removeProxy(s.Host, port, "", ""); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+
+			targets := map[string]bool{}
+
+			for _, t := range ps.Targets {
+				targets[t] = true
+			}
+
+			for _, t := range p.Targets.Targets() {
+				if !targets[t.URL.String()] {
+					p.Targets.Remove(t.URL.String())
+				}
+			}
+
+			for _, path := range p.Rules.Paths() {
+				targets := map[string]bool{}
+
+				for _, t := range ps.Paths[path] {
+					targets[t] = true
+				}
+
+				for _, t := range p.Rules.Pool(path).Targets() {
+					if !targets[t.URL.String()] {
+						p.Rules.Remove(path, t.URL.String())
+					}
+				}
+			}
+		}
+
+		for _, ps := range s.Proxies {
+			for _, t := range ps.Targets {
+				if _, err := r.addProxy(s.Host, ps.Port, ps.Scheme, "", t, ps.Balance); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			for path, ts := range ps.Paths {
+				for _, t := range ts {
+					if _, err := r.addProxy(s.Host, ps.Port, ps.Scheme, path, t, ps.Balance); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			}
+		}
+	}
+
+	if err := r.save(); err != nil {
+		logError(err)
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// restore loads persisted endpoints from storage
+func (r *Router) restore() error {
+	if r.Storage == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(r.Storage)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var states []EndpointState
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+
+	fmt.Printf("ns=convox.router at=restore endpoints=%d\n", len(states))
+
+	return r.reconcile("", states)
+}
+
+// save persists all non-system endpoints to storage
+// the caller must hold r.lock
+func (r *Router) save() error {
+	if r.Storage == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.state(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(data, r.saved) {
+		return nil
+	}
+
+	if err := writeFile(r.Storage+".tmp", data); err != nil {
+		return err
+	}
+
+	if err := os.Rename(r.Storage+".tmp", r.Storage); err != nil {
+		return err
+	}
+
+	r.saved = data
+
+	return nil
+}
+
+// state returns the current non-system endpoints sorted by host
+// the caller must hold r.lock
+func (r *Router) state() []EndpointState {
+	states := []EndpointState{}
+
+	for host, ep := range r.endpoints {
+		if ep.Expires.IsZero() {
+			continue
+		}
+
+		s := EndpointState{
+			Aliases: ep.Aliases,
+			Host:    host,
+			IP:      ep.IP,
+			Proxies: []ProxyState{},
+		}
+
+		if ep.State != StateServing {
+			s.State = ep.State
+		}
+
+		for port, p := range ep.Proxies {
+			ps := ProxyState{
+				Port:    port,
+				Scheme:  p.Listen.Scheme,
+				Balance: p.Targets.Balance,
+				Targets: []string{},
+			}
+
+			for _, t := range p.Targets.Targets() {
+				ps.Targets = append(ps.Targets, t.URL.String())
+			}
+
+			for _, path := range p.Rules.Paths() {
+				if ps.Paths == nil {
+					ps.Paths = map[string][]string{}
+				}
+
+				for _, t := range p.Rules.Pool(path).Targets() {
+					ps.Paths[path] = append(ps.Paths[path], t.URL.String())
+				}
+			}
+
+			s.Proxies = append(s.Proxies, ps)
+		}
+
+		sort.Slice(s.Proxies, func(i, j int) bool { return s.Proxies[i].Port < s.Proxies[j].Port })
+
+		states = append(states, s)
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Host < states[j].Host })
+
+	return states
+}
@@ -0,0 +1,88 @@
+package router_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileSuffix(t *testing.T) {
+	r := router.NewTestRouter("")
+
+	require.NoError(t, r.Reconcile(".app1.convox", []router.EndpointState{
+		{Host: "web.app1.convox", Proxies: []router.ProxyState{{Port: 18001, Scheme: "tcp", Targets: []string{"tcp://127.0.0.1:9001"}}}},
+		{Host: "api.app1.convox"},
+	}))
+
+	require.NoError(t, r.Reconcile(".app2.convox", []router.EndpointState{
+		{Host: "web.app2.convox", State: router.StateMaintenance},
+	}))
+
+	assert.Equal(t, []string{"api.app1.convox", "web.app1.convox", "web.app2.convox"}, testHosts(r))
+
+	require.NoError(t, r.Reconcile(".app1.convox", []router.EndpointState{
+		{Host: "web.app1.convox", Proxies: []router.ProxyState{{Port: 18001, Scheme: "tcp", Targets: []string{"tcp://127.0.0.1:9002"}}}},
+	}))
+
+	states := r.State()
+
+	require.Len(t, states, 2)
+	assert.Equal(t, "web.app1.convox", states[0].Host)
+	require.Len(t, states[0].Proxies, 1)
+	assert.Equal(t, []string{"tcp://127.0.0.1:9002"}, states[0].Proxies[0].Targets)
+	assert.Equal(t, "web.app2.convox", states[1].Host)
+	assert.Equal(t, router.StateMaintenance, states[1].State)
+
+	assert.EqualError(t, r.Reconcile(".app1.convox", []router.EndpointState{{Host: "web.app2.convox"}}), "endpoint not in scope: web.app2.convox")
+	assert.EqualError(t, r.Reconcile(".app1.convox", []router.EndpointState{{Host: "web.app1.convox", State: "broken"}}), "unknown endpoint state: broken")
+
+	require.NoError(t, r.Reconcile(".app1.convox", nil))
+
+	assert.Equal(t, []string{"web.app2.convox"}, testHosts(r))
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "router")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := router.NewTestRouter(filepath.Join(dir, "endpoints.json"))
+
+	require.NoError(t, r.Reconcile(".app.convox", []router.EndpointState{
+		{Host: "web.app.convox", Aliases: []string{"www.example.org"}, Proxies: []router.ProxyState{
+			{Port: 18011, Scheme: "tcp", Balance: router.BalanceRoundRobin, Targets: []string{"tcp://127.0.0.1:9001", "tcp://127.0.0.1:9002"}},
+		}},
+		{Host: "worker.app.convox", State: router.StateUnavailable},
+	}))
+
+	saved := r.State()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "endpoints.json"))
+	require.NoError(t, err)
+
+	// release the listeners of the first router before the second one opens them
+	require.NoError(t, r.Reconcile(".app.convox", nil))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "restore.json"), data, 0600))
+
+	r2 := router.NewTestRouter(filepath.Join(dir, "restore.json"))
+
+	require.NoError(t, r2.Restore())
+
+	assert.Equal(t, saved, r2.State())
+}
+
+func testHosts(r *router.Router) []string {
+	hosts := []string{}
+
+	for _, s := range r.State() {
+		hosts = append(hosts, s.Host)
+	}
+
+	return hosts
+}
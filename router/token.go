@@ -0,0 +1,62 @@
+package router
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/convox/praxis/api"
+)
+
+// TokenPath returns the file holding the token that authorizes changes to the certificate authority
+// it is only readable by root on the router host
+func TokenPath() string {
+	return filepath.Join(caDir, "router.token")
+}
+
+// routerToken reads the token of the router, generating it on first start
+func routerToken() (string, error) {
+	data, err := ioutil.ReadFile(TokenPath())
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	key := make([]byte, 32)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(key)
+
+	if err := os.MkdirAll(caDir, 0755); err != nil {
+		return "", err
+	}
+
+	if err := ioutil.WriteFile(TokenPath(), []byte(token), 0600); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// authorize only passes requests that carry the router token as a bearer token
+func (r *Router) authorize(fn api.HandlerFunc) api.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request, c *api.Context) error {
+		auth := c.Header("Authorization")
+
+		if r.token == "" || !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(r.token)) != 1 {
+			return api.Errorf(401, "unauthorized")
+		}
+
+		return fn(w, req, c)
+	}
+}
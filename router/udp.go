@@ -0,0 +1,193 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	udpBufferSize  = 65535
+	udpIdleTimeout = 60 * time.Second
+)
+
+// UDPProxy forwards datagrams to the targets of a pool
+// each client address gets a session with its own upstream socket so that replies find their way back
+// sessions are closed once they have been idle for longer than the timeout
+// datagrams are dropped while State reports that the endpoint is not serving
+type UDPProxy struct {
+	State   func() string
+	Timeout time.Duration
+
+	lock     sync.Mutex
+	pool     *Pool
+	sessions map[string]*udpSession
+}
+
+type udpSession struct {
+	client   net.Addr
+	last     int64
+	once     sync.Once
+	target   *Target
+	upstream net.Conn
+}
+
+func NewUDPProxy(pool *Pool, timeout time.Duration) *UDPProxy {
+	if timeout == 0 {
+		timeout = udpIdleTimeout
+	}
+
+	return &UDPProxy{
+		Timeout:  timeout,
+		pool:     pool,
+		sessions: map[string]*udpSession{},
+	}
+}
+
+// Serve reads datagrams from pc until it is closed
+func (p *UDPProxy) Serve(pc net.PacketConn) error {
+	defer p.closeAll()
+
+	buf := make([]byte, udpBufferSize)
+
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		if p.State != nil && p.State() != StateServing {
+			continue
+		}
+
+		s, err := p.session(pc, addr)
+		if err != nil {
+			logError(err)
+			continue
+		}
+
+		s.touch()
+
+		if _, err := s.upstream.Write(buf[:n]); err != nil {
+			s.target.Failure()
+			p.close(s)
+		}
+	}
+}
+
+// Sessions returns the number of open client sessions
+func (p *UDPProxy) Sessions() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.sessions)
+}
+
+// session returns the session for a client, opening one to the next target if needed
+func (p *UDPProxy) session(pc net.PacketConn, client net.Addr) (*udpSession, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if s, ok := p.sessions[client.String()]; ok {
+		return s, nil
+	}
+
+	t, err := p.pool.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.URL.Hostname() == "rack" {
+		return nil, fmt.Errorf("udp targets must be addressed directly: %s", t.URL)
+	}
+
+	cn, err := net.Dial("udp", t.URL.Host)
+	if err != nil {
+		t.Failure()
+		return nil, err
+	}
+
+	t.Acquire()
+
+	s := &udpSession{
+		client:   client,
+		target:   t,
+		upstream: cn,
+	}
+
+	p.sessions[client.String()] = s
+
+	go p.reply(pc, s)
+
+	fmt.Printf("ns=convox.router at=udp state=open client=%q target=%q\n", client, t.URL)
+
+	return s, nil
+}
+
+// reply copies datagrams from the upstream back to the client until the session goes idle
+func (p *UDPProxy) reply(pc net.PacketConn, s *udpSession) {
+	defer p.close(s)
+
+	buf := make([]byte, udpBufferSize)
+
+	for {
+		s.upstream.SetReadDeadline(s.lastActive().Add(p.Timeout))
+
+		n, err := s.upstream.Read(buf)
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			if time.Since(s.lastActive()) < p.Timeout {
+				continue
+			}
+			return
+		}
+		if err != nil {
+			s.target.Failure()
+			return
+		}
+
+		s.target.Success()
+		s.touch()
+
+		if _, err := pc.WriteTo(buf[:n], s.client); err != nil {
+			return
+		}
+	}
+}
+
+func (p *UDPProxy) close(s *udpSession) {
+	s.once.Do(func() {
+		p.lock.Lock()
+		if p.sessions[s.client.String()] == s {
+			delete(p.sessions, s.client.String())
+		}
+		p.lock.Unlock()
+
+		s.upstream.Close()
+		s.target.Release()
+
+		fmt.Printf("ns=convox.router at=udp state=closed client=%q target=%q\n", s.client, s.target.URL)
+	})
+}
+
+func (p *UDPProxy) closeAll() {
+	p.lock.Lock()
+	ss := make([]*udpSession, 0, len(p.sessions))
+	for _, s := range p.sessions {
+		ss = append(ss, s)
+	}
+	p.lock.Unlock()
+
+	for _, s := range ss {
+		p.close(s)
+	}
+}
+
+func (s *udpSession) lastActive() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&s.last))
+}
+
+func (s *udpSession) touch() {
+	atomic.StoreInt64(&s.last, time.Now().UnixNano())
+}
@@ -0,0 +1,122 @@
+package router_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUDPProxy(t *testing.T) {
+	backend := testUDPEcho(t)
+
+	pool, err := router.NewPool("")
+	require.NoError(t, err)
+
+	pool.Add(testURL(t, "udp://"+backend))
+
+	p := router.NewUDPProxy(pool, 200*time.Millisecond)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	go p.Serve(pc)
+
+	c1 := testUDPRoundTrip(t, pc.LocalAddr().String(), "one")
+	defer c1.Close()
+
+	c2 := testUDPRoundTrip(t, pc.LocalAddr().String(), "two")
+	defer c2.Close()
+
+	assert.Equal(t, 2, p.Sessions())
+
+	// traffic on an existing session reuses it
+	_, err = c1.Write([]byte("again"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, err := c1.Read(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "echo: again", string(buf[:n]))
+	assert.Equal(t, 2, p.Sessions())
+
+	// idle sessions are closed after the timeout
+	for i := 0; i < 100 && p.Sessions() > 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	assert.Equal(t, 0, p.Sessions())
+}
+
+func TestUDPProxyRackTarget(t *testing.T) {
+	pool, err := router.NewPool("")
+	require.NoError(t, err)
+
+	pool.Add(testURL(t, "udp://rack/app/process/web:8125"))
+
+	p := router.NewUDPProxy(pool, 0)
+
+	assert.Equal(t, 60*time.Second, p.Timeout)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	go p.Serve(pc)
+
+	cn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer cn.Close()
+
+	_, err = cn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, 0, p.Sessions())
+}
+
+func testUDPEcho(t *testing.T) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			pc.WriteTo(append([]byte("echo: "), buf[:n]...), addr)
+		}
+	}()
+
+	return pc.LocalAddr().String()
+}
+
+func testUDPRoundTrip(t *testing.T, addr, message string) net.Conn {
+	cn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+
+	_, err = cn.Write([]byte(message))
+	require.NoError(t, err)
+
+	cn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	buf := make([]byte, 1024)
+	n, err := cn.Read(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "echo: "+message, string(buf[:n]))
+
+	return cn
+}
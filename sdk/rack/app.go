@@ -3,7 +3,6 @@ package rack
 import (
 	"fmt"
 	"io"
-	"strconv"
 
 	"github.com/convox/praxis/types"
 )
@@ -35,15 +34,7 @@ func (c *Client) AppList() (apps types.Apps, err error) {
 
 func (c *Client) AppLogs(app string, opts types.LogsOptions) (io.ReadCloser, error) {
 	ro := RequestOptions{
-		Query: Query{
-			"filter": opts.Filter,
-			"follow": fmt.Sprintf("%t", opts.Follow),
-			"prefix": fmt.Sprintf("%t", opts.Prefix),
-		},
-	}
-
-	if !opts.Since.IsZero() {
-		ro.Query["since"] = strconv.Itoa(int(opts.Since.UTC().Unix()))
+		Query: logsQuery(opts),
 	}
 
 	res, err := c.GetStream(fmt.Sprintf("/apps/%s/logs", app), ro)
@@ -0,0 +1,33 @@
+package rack
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/convox/praxis/types"
+)
+
+func (c *Client) SystemAudit(opts types.SystemAuditOptions) (events types.AuditEvents, err error) {
+	ro := RequestOptions{
+		Query: Query{
+			"actor": opts.Actor,
+			"app":   opts.App,
+		},
+	}
+
+	if opts.Limit > 0 {
+		ro.Query["limit"] = strconv.Itoa(opts.Limit)
+	}
+
+	if !opts.Since.IsZero() {
+		ro.Query["since"] = strconv.Itoa(int(opts.Since.UTC().Unix()))
+	}
+
+	err = c.Get("/system/audit", ro, &events)
+	return
+}
+
+// SystemAuditRecord is called by the rack itself as requests are served
+func (c *Client) SystemAuditRecord(event types.AuditEvent) error {
+	return fmt.Errorf("unimplemented")
+}
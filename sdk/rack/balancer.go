@@ -0,0 +1,12 @@
+package rack
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (c *Client) BalancerList(app string) (bs types.Balancers, err error) {
+	err = c.Get(fmt.Sprintf("/apps/%s/balancers", app), RequestOptions{}, &bs)
+	return
+}
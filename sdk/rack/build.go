@@ -30,8 +30,12 @@ func (c *Client) BuildList(app string) (builds types.Builds, err error) {
 	return
 }
 
-func (c *Client) BuildLogs(app, id string) (io.ReadCloser, error) {
-	res, err := c.GetStream(fmt.Sprintf("/apps/%s/builds/%s/logs", app, id), RequestOptions{})
+func (c *Client) BuildLogs(app, id string, opts types.LogsOptions) (io.ReadCloser, error) {
+	ro := RequestOptions{
+		Query: logsQuery(opts),
+	}
+
+	res, err := c.GetStream(fmt.Sprintf("/apps/%s/builds/%s/logs", app, id), ro)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,20 +13,29 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/convox/praxis/stdcli"
+	"github.com/convox/praxis/types"
 
 	"golang.org/x/net/websocket"
 )
 
 type Client struct {
-	Debug    bool
-	Endpoint *url.URL
-	Key      string
-	Socket   string
-	Version  string
+	CA          *x509.CertPool
+	Debug       bool
+	Endpoint    *url.URL
+	Fingerprint string
+	Key         string
+	Retries     int
+	Socket      string
+	Timeout     time.Duration
+	Version     string
+
+	capabilities *capabilities
+	ctx          context.Context
 }
 
 type Headers map[string]string
@@ -90,13 +100,14 @@ func (o *RequestOptions) ContentType() string {
 	return "application/octet-stream"
 }
 
+// Stream opens a bidirectional stream using whichever transport the rack supports
 func (c *Client) Stream(path string, opts RequestOptions) (io.ReadCloser, error) {
-	so, err := c.SystemOptions()
+	caps, err := c.Capabilities()
 	if err != nil {
 		return nil, err
 	}
 
-	switch so["streaming"] {
+	switch caps["streaming"] {
 	case "websocket":
 		u, err := url.Parse(fmt.Sprintf("wss://%s%s%s?%s", c.Endpoint.Host, c.Endpoint.Path, path, opts.Querystring()))
 		if err != nil {
@@ -109,6 +120,7 @@ func (c *Client) Stream(path string, opts RequestOptions) (io.ReadCloser, error)
 		}
 
 		header := http.Header{}
+		header.Set(types.ApiVersionHeader, strconv.Itoa(types.ApiVersion))
 		for k, v := range opts.Headers {
 			header.Add(k, v)
 		}
@@ -119,20 +131,18 @@ func (c *Client) Stream(path string, opts RequestOptions) (io.ReadCloser, error)
 		}
 
 		config := &websocket.Config{
-			Header:   header,
-			Location: u,
-			Origin:   u,
-			Version:  websocket.ProtocolVersionHybi13,
-			TlsConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+			Header:    header,
+			Location:  u,
+			Origin:    u,
+			Version:   websocket.ProtocolVersionHybi13,
+			TlsConfig: c.tlsConfig(),
 		}
 
 		for k, v := range opts.Headers {
 			config.Header.Set(k, v)
 		}
 
-		ws, err := websocket.DialConfig(config)
+		ws, err := c.websocket(config)
 		if err != nil {
 			return nil, err
 		}
@@ -150,112 +160,93 @@ func (c *Client) Stream(path string, opts RequestOptions) (io.ReadCloser, error)
 
 		return res.Body, nil
 	default:
-		return nil, fmt.Errorf("unknown streaming type: %s", so["streaming"])
+		return nil, fmt.Errorf("unknown streaming type: %s", caps["streaming"])
 	}
 }
 
-func (c *Client) Head(path string, opts RequestOptions) error {
-	req, err := c.Request("HEAD", path, opts)
-	if err != nil {
-		return err
-	}
+// websocket dials a websocket under the client context, the stream is closed if the context is cancelled
+func (c *Client) websocket(config *websocket.Config) (*websocket.Conn, error) {
+	ctx := c.Context()
 
-	_, err = c.handleRequest(req)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
 
-	return err
-}
-
-func (c *Client) Options(path string, opts RequestOptions, out interface{}) error {
-	req, err := c.Request("OPTIONS", path, opts)
-	if err != nil {
-		return err
-	}
+	host := config.Location.Host
 
-	res, err := c.handleRequest(req)
-	if err != nil {
-		return err
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
 	}
 
-	return unmarshalReader(res.Body, out)
-}
-
-func (c *Client) GetStream(path string, opts RequestOptions) (*http.Response, error) {
-	req, err := c.Request("GET", path, opts)
+	raw, err := dialer.DialContext(ctx, "tcp", host)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.handleRequest(req)
-}
-
-func (c *Client) Get(path string, opts RequestOptions, out interface{}) error {
-	res, err := c.GetStream(path, opts)
-	if err != nil {
-		return err
-	}
+	tc := config.TlsConfig.Clone()
+	tc.ServerName = config.Location.Hostname()
 
-	defer res.Body.Close()
+	cn := tls.Client(raw, tc)
 
-	return unmarshalReader(res.Body, out)
-}
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.SetDeadline(deadline)
+	}
 
-func (c *Client) PostStream(path string, opts RequestOptions) (*http.Response, error) {
-	req, err := c.Request("POST", path, opts)
-	if err != nil {
+	if err := cn.Handshake(); err != nil {
+		raw.Close()
 		return nil, err
 	}
 
-	res, err := c.handleRequest(req)
+	ws, err := websocket.NewClient(config, cn)
 	if err != nil {
+		cn.Close()
 		return nil, err
 	}
 
-	return res, nil
-}
+	cn.SetDeadline(time.Time{})
 
-func (c *Client) Post(path string, opts RequestOptions, out interface{}) error {
-	res, err := c.PostStream(path, opts)
-	if err != nil {
-		return err
+	if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+			ws.Close()
+		}()
 	}
 
-	defer res.Body.Close()
+	return ws, nil
+}
 
-	return unmarshalReader(res.Body, out)
+func (c *Client) Head(path string, opts RequestOptions) error {
+	return c.request("HEAD", path, opts, nil)
 }
 
-func (c *Client) PutStream(path string, opts RequestOptions) (*http.Response, error) {
-	req, err := c.Request("PUT", path, opts)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) Options(path string, opts RequestOptions, out interface{}) error {
+	return c.request("OPTIONS", path, opts, out)
+}
 
-	return c.handleRequest(req)
+func (c *Client) GetStream(path string, opts RequestOptions) (*http.Response, error) {
+	return c.send("GET", path, opts)
 }
 
-func (c *Client) Put(path string, opts RequestOptions, out interface{}) error {
-	res, err := c.PutStream(path, opts)
-	if err != nil {
-		return err
-	}
+func (c *Client) Get(path string, opts RequestOptions, out interface{}) error {
+	return c.request("GET", path, opts, out)
+}
 
-	defer res.Body.Close()
+func (c *Client) PostStream(path string, opts RequestOptions) (*http.Response, error) {
+	return c.send("POST", path, opts)
+}
 
-	return unmarshalReader(res.Body, out)
+func (c *Client) Post(path string, opts RequestOptions, out interface{}) error {
+	return c.request("POST", path, opts, out)
 }
 
-func (c *Client) Delete(path string, opts RequestOptions, out interface{}) error {
-	req, err := c.Request("DELETE", path, opts)
-	if err != nil {
-		return err
-	}
+func (c *Client) PutStream(path string, opts RequestOptions) (*http.Response, error) {
+	return c.send("PUT", path, opts)
+}
 
-	res, err := c.handleRequest(req)
-	if err != nil {
-		return err
-	}
+func (c *Client) Put(path string, opts RequestOptions, out interface{}) error {
+	return c.request("PUT", path, opts, out)
+}
 
-	return unmarshalReader(res.Body, out)
+func (c *Client) Delete(path string, opts RequestOptions, out interface{}) error {
+	return c.request("DELETE", path, opts, out)
 }
 
 func (c *Client) Client() *http.Client {
@@ -272,9 +263,7 @@ func (c *Client) Client() *http.Client {
 			}
 			return dialer.DialContext(ctx, proto, addr)
 		},
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
+		TLSClientConfig: c.tlsConfig(),
 	}
 
 	// disabled because HTTP2 over ALB doesn't work yet
@@ -303,10 +292,13 @@ func (c *Client) Request(method, path string, opts RequestOptions) (*http.Reques
 		return nil, err
 	}
 
+	req = req.WithContext(c.Context())
+
 	req.Header.Add("Accept", "*/*")
 	req.Header.Set("Content-Type", opts.ContentType())
 	req.Header.Set("User-Agent", fmt.Sprintf("convox.go/%s", c.Version))
 	req.Header.Set("Version", c.Version)
+	req.Header.Set(types.ApiVersionHeader, strconv.Itoa(types.ApiVersion))
 
 	for k, v := range opts.Headers {
 		req.Header.Set(k, v)
@@ -319,6 +311,54 @@ func (c *Client) Request(method, path string, opts RequestOptions) (*http.Reques
 	return req, nil
 }
 
+// request sends a request and reads the response into out, the whole exchange is limited by Timeout
+func (c *Client) request(method, path string, opts RequestOptions, out interface{}) error {
+	cc := c
+
+	if c.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Context(), c.Timeout)
+		defer cancel()
+
+		cc = c.withContext(ctx)
+	}
+
+	res, err := cc.send(method, path, opts)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalReader(res.Body, out)
+}
+
+// send sends a request, retrying idempotent requests that fail to connect or find the rack unavailable
+func (c *Client) send(method, path string, opts RequestOptions) (*http.Response, error) {
+	// a body that is not built from params can not be read twice
+	retry := idempotent(method) && opts.Body == nil
+
+	backoff := RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		req, err := c.Request(method, path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.handleRequest(req)
+
+		if !retry || attempt >= c.Retries || !retryable(err) {
+			return res, err
+		}
+
+		select {
+		case <-c.Context().Done():
+			return nil, c.Context().Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
 func (c *Client) handleRequest(req *http.Request) (*http.Response, error) {
 	if c.Debug {
 		stdcli.DefaultWriter.Writef("<debug>%s %s </debug>", req.Method, req.URL)
@@ -329,6 +369,8 @@ func (c *Client) handleRequest(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	c.readVersion(res)
+
 	if c.Debug {
 		switch res.StatusCode / 100 {
 		case 2, 3:
@@ -339,12 +381,66 @@ func (c *Client) handleRequest(req *http.Request) (*http.Response, error) {
 	}
 
 	if err := responseError(res); err != nil {
-		return nil, err
+		return nil, c.unsupported(req, res, err)
 	}
 
 	return res, nil
 }
 
+// unsupported explains a request the rack has no route for, the rack predates it
+// routes a rack knows answer with its api version and a 404 of theirs carries a message
+func (c *Client) unsupported(req *http.Request, res *http.Response, err error) error {
+	e, ok := err.(Error)
+	if !ok || e.Code != http.StatusNotFound || res.Header.Get(types.ApiVersionHeader) != "" || e.Message != fmt.Sprintf("response status %d", e.Code) {
+		return err
+	}
+
+	if v := c.rackVersion(); v > 0 {
+		return Error{Code: e.Code, Message: fmt.Sprintf("rack does not support %s %s (rack api %d, client api %d), update with: cx rack update", req.Method, req.URL.Path, v, types.ApiVersion)}
+	}
+
+	return Error{Code: e.Code, Message: fmt.Sprintf("rack does not support %s %s, update with: cx rack update", req.Method, req.URL.Path)}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "DELETE", "GET", "HEAD", "OPTIONS", "PUT":
+		return true
+	}
+
+	return false
+}
+
+// retryable returns true for errors that may succeed if tried again
+// a pinned certificate mismatch or a cancelled context will not
+func retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if e, ok := err.(Error); ok {
+		return e.Code == http.StatusBadGateway || e.Code == http.StatusServiceUnavailable
+	}
+
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return false
+	}
+
+	if _, ok := ue.Err.(*FingerprintError); ok {
+		return false
+	}
+
+	switch ue.Err {
+	case context.Canceled, context.DeadlineExceeded:
+		return false
+	}
+
+	_, ok = ue.Err.(net.Error)
+
+	return ok
+}
+
 func responseError(res *http.Response) error {
 	// disabled because HTTP2 over ALB doesnt work yet
 
@@ -356,6 +452,8 @@ func responseError(res *http.Response) error {
 		return nil
 	}
 
+	defer res.Body.Close()
+
 	data, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return err
@@ -363,11 +461,11 @@ func responseError(res *http.Response) error {
 
 	msg := strings.TrimSpace(string(data))
 
-	if len(msg) > 0 {
-		return fmt.Errorf(msg)
+	if len(msg) == 0 {
+		msg = fmt.Sprintf("response status %d", res.StatusCode)
 	}
 
-	return fmt.Errorf("response status %d", res.StatusCode)
+	return Error{Code: res.StatusCode, Message: msg}
 }
 
 func unmarshalReader(r io.ReadCloser, out interface{}) error {
@@ -0,0 +1,304 @@
+package rack_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	rack.RetryBackoff = 1 * time.Millisecond
+}
+
+func TestClientRetryUnavailable(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			http.Error(w, "unavailable", 503)
+			return
+		}
+		w.Write([]byte(`{"name":"convox"}`))
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	s, err := r.SystemGet()
+	require.NoError(t, err)
+	assert.Equal(t, "convox", s.Name)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestClientRetryExhausted(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "bad gateway", 502)
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	_, err = r.SystemGet()
+	require.Error(t, err)
+	assert.Equal(t, "bad gateway", err.Error())
+	assert.Equal(t, int32(rack.DefaultRetries+1), atomic.LoadInt32(&calls))
+}
+
+func TestClientNoRetryPost(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "unavailable", 503)
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	_, err = r.AppCreate("app1")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps":
+			http.Error(w, "app already exists: app1", 409)
+		case "/apps/missing":
+			http.Error(w, "no such app: missing", 404)
+		default:
+			http.Error(w, "invalid auth", 401)
+		}
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	_, err = r.AppCreate("app1")
+	assert.True(t, rack.IsConflict(err))
+	assert.Equal(t, "app already exists: app1", err.Error())
+
+	_, err = r.AppGet("missing")
+	assert.True(t, rack.IsNotFound(err))
+	assert.False(t, rack.IsConflict(err))
+
+	_, err = r.SystemGet()
+	assert.True(t, rack.IsUnauthorized(err))
+	assert.Equal(t, rack.Error{Code: 401, Message: "invalid auth"}, err)
+}
+
+func TestClientCapabilitiesCached(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			atomic.AddInt32(&calls, 1)
+			w.Write([]byte(`{"streaming":"websocket"}`))
+		}
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	c := r.(*rack.Client)
+
+	for i := 0; i < 3; i++ {
+		caps, err := c.Capabilities()
+		require.NoError(t, err)
+		assert.Equal(t, "websocket", caps["streaming"])
+	}
+
+	caps, err := c.WithContext(context.Background()).(*rack.Client).Capabilities()
+	require.NoError(t, err)
+	assert.Equal(t, "websocket", caps["streaming"])
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestClientContextCancel(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err = r.WithContext(ctx).SystemGet()
+	require.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestClientTimeout(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	r.(*rack.Client).Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+
+	_, err = r.SystemGet()
+	require.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestClientTimeoutDefault(t *testing.T) {
+	r, err := rack.New("https://localhost:5443")
+	require.NoError(t, err)
+
+	assert.Equal(t, rack.DefaultTimeout, r.(*rack.Client).Timeout)
+}
+
+func TestClientTimeoutStream(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("one\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("two\n"))
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	r.(*rack.Client).Timeout = 50 * time.Millisecond
+
+	rc, err := r.ObjectFetch("app", "key")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+}
+
+func TestClientApiVersion(t *testing.T) {
+	var versions []string
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions = append(versions, r.Header.Get("Api-Version"))
+		w.Write([]byte(`{"api":"7","features":"events,tokens","streaming":"websocket"}`))
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	c := r.(*rack.Client)
+
+	v, err := c.ApiVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 7, v)
+
+	ok, err := c.Supports("tokens")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.Supports("webhooks")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = c.SystemGet()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{strconv.Itoa(types.ApiVersion), strconv.Itoa(types.ApiVersion)}, versions)
+}
+
+func TestClientApiVersionHeader(t *testing.T) {
+	var requests []string
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch r.URL.Path {
+		case "/system":
+			w.Header().Set("Api-Version", "3")
+			w.Write([]byte(`{"name":"convox"}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	c := r.(*rack.Client)
+
+	_, err = c.SystemGet()
+	require.NoError(t, err)
+
+	v, err := c.ApiVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	_, err = c.WebhookList()
+	require.Error(t, err)
+	assert.True(t, rack.IsNotFound(err))
+	assert.Equal(t, "rack does not support GET /webhooks (rack api 3, client api 1), update with: cx rack update", err.Error())
+
+	assert.Equal(t, []string{"GET /system", "GET /webhooks"}, requests)
+}
+
+func TestClientApiVersionRejected(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "9")
+		w.WriteHeader(400)
+		w.Write([]byte("client api version 1 is no longer supported by this rack (minimum 5), update with: cx update"))
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	_, err = r.SystemGet()
+	require.Error(t, err)
+	assert.Equal(t, "client api version 1 is no longer supported by this rack (minimum 5), update with: cx update", err.Error())
+
+	v, err := r.(*rack.Client).ApiVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 9, v)
+}
+
+func TestClientApiVersionLegacy(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"streaming":"websocket"}`))
+	}))
+	defer ts.Close()
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	v, err := r.(*rack.Client).ApiVersion()
+	require.NoError(t, err)
+	assert.Equal(t, types.ApiVersionMin, v)
+}
@@ -6,6 +6,23 @@ import (
 	"github.com/convox/praxis/types"
 )
 
+// Context returns the context requests from this client are bound to
+func (c *Client) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+// WithContext returns a copy of the client whose requests are cancelled when ctx is done
+// every types.Provider call made through the copy honors ctx
 func (c *Client) WithContext(ctx context.Context) types.Provider {
-	return c
+	return c.withContext(ctx)
+}
+
+func (c *Client) withContext(ctx context.Context) *Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
 }
@@ -0,0 +1,27 @@
+package rack
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (c *Client) DrainCreate(app, url string) (drain *types.Drain, err error) {
+	ro := RequestOptions{
+		Params: Params{
+			"url": url,
+		},
+	}
+
+	err = c.Post(fmt.Sprintf("/apps/%s/drains", app), ro, &drain)
+	return
+}
+
+func (c *Client) DrainDelete(app, id string) error {
+	return c.Delete(fmt.Sprintf("/apps/%s/drains/%s", app, id), RequestOptions{}, nil)
+}
+
+func (c *Client) DrainList(app string) (drains types.Drains, err error) {
+	err = c.Get(fmt.Sprintf("/apps/%s/drains", app), RequestOptions{}, &drains)
+	return
+}
@@ -0,0 +1,49 @@
+package rack
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Error is returned when the rack responds with an error status
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+// IsConflict returns true if err reports that a resource already exists
+func IsConflict(err error) bool {
+	return errorCode(err) == http.StatusConflict
+}
+
+// IsNotFound returns true if err reports that a resource does not exist
+func IsNotFound(err error) bool {
+	return errorCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized returns true if err reports missing or invalid credentials
+func IsUnauthorized(err error) bool {
+	return errorCode(err) == http.StatusUnauthorized
+}
+
+// errorCode returns the status of an error from the rack
+// errors from an in-process provider carry their status through a StatusCode method
+func errorCode(err error) int {
+	switch t := errors.Cause(err).(type) {
+	case Error:
+		return t.Code
+	case *Error:
+		return t.Code
+	case interface {
+		StatusCode() int
+	}:
+		return t.StatusCode()
+	}
+
+	return 0
+}
@@ -0,0 +1,128 @@
+package rack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/convox/praxis/types"
+)
+
+func (c *Client) EventSend(action string, opts types.EventSendOptions) error {
+	ro := RequestOptions{
+		Params: Params{
+			"action": action,
+			"app":    opts.App,
+			"error":  opts.Error,
+		},
+	}
+
+	if len(opts.Data) > 0 {
+		data, err := json.Marshal(opts.Data)
+		if err != nil {
+			return err
+		}
+
+		ro.Params["data"] = string(data)
+	}
+
+	return c.Post("/events", ro, nil)
+}
+
+// EventStream returns the rack event stream as newline-delimited json events
+func (c *Client) EventStream(opts types.EventStreamOptions) (io.ReadCloser, error) {
+	body, err := c.eventStream(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+
+	go func() {
+		defer body.Close()
+
+		w.CloseWithError(eventFrames(body, func(data []byte) error {
+			_, err := w.Write(append(data, '\n'))
+			return err
+		}))
+	}()
+
+	return r, nil
+}
+
+// EventSubscribe calls fn for each event on the rack event stream until the stream ends or fn returns an error
+func (c *Client) EventSubscribe(opts types.EventStreamOptions, fn func(types.Event) error) error {
+	body, err := c.eventStream(opts)
+	if err != nil {
+		return err
+	}
+
+	defer body.Close()
+
+	return eventFrames(body, func(data []byte) error {
+		var e types.Event
+
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+
+		return fn(e)
+	})
+}
+
+func (c *Client) eventStream(opts types.EventStreamOptions) (io.ReadCloser, error) {
+	ro := RequestOptions{
+		Headers: Headers{
+			"Accept": "text/event-stream",
+		},
+		Query: Query{
+			"actions": strings.Join(opts.Actions, ","),
+			"app":     opts.App,
+		},
+	}
+
+	res, err := c.GetStream("/events", ro)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// eventFrames calls fn with the data of each server-sent event frame in r
+func eventFrames(r io.Reader, fn func([]byte) error) error {
+	s := bufio.NewScanner(r)
+
+	data := []byte{}
+
+	for s.Scan() {
+		line := s.Bytes()
+
+		switch {
+		case len(line) == 0:
+			if len(data) > 0 {
+				if err := fn(data); err != nil {
+					return err
+				}
+			}
+			data = []byte{}
+		case bytes.HasPrefix(line, []byte("data:")):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" "))...)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	if len(data) > 0 {
+		return fn(data)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package rack_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventRack(t *testing.T) (rack.Rack, func()) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/events", r.URL.Path)
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"action\":\"app:create\",\"app\":\"web\"}\n\n: keepalive\n\ndata: {\"action\":\"build:create\",\"app\":\"web\"}\n\n"))
+	}))
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	return r, ts.Close
+}
+
+func TestEventSubscribe(t *testing.T) {
+	r, done := eventRack(t)
+	defer done()
+
+	actions := []string{}
+
+	err := r.(*rack.Client).EventSubscribe(types.EventStreamOptions{App: "web"}, func(e types.Event) error {
+		actions = append(actions, e.Action)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"app:create", "build:create"}, actions)
+}
+
+func TestEventStream(t *testing.T) {
+	r, done := eventRack(t)
+	defer done()
+
+	s, err := r.EventStream(types.EventStreamOptions{App: "web"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	data, err := ioutil.ReadAll(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, "{\"action\":\"app:create\",\"app\":\"web\"}\n{\"action\":\"build:create\",\"app\":\"web\"}\n", string(data))
+}
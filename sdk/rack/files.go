@@ -21,5 +21,11 @@ func (c *Client) FilesUpload(app, pid string, r io.Reader) error {
 		Body: r,
 	}
 
-	return c.Post(fmt.Sprintf("/apps/%s/processes/%s/files", app, pid), ro, nil)
+	// an upload can outlast Timeout so it is sent as a stream
+	res, err := c.PostStream(fmt.Sprintf("/apps/%s/processes/%s/files", app, pid), ro)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
 }
@@ -0,0 +1,174 @@
+package rack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/convox/praxis/types"
+)
+
+// LogFollower copies a log stream and reopens it where it left off if the connection drops
+// racks that support cursors resume after the last line written
+// older racks are read again from the start each time Copy is called, skipping what was already written
+type LogFollower struct {
+	Open    func(opts types.LogsOptions) (io.ReadCloser, error)
+	Options types.LogsOptions
+
+	cursors bool
+	offset  int64
+}
+
+// NewLogFollower returns a follower for the stream returned by open, such as a call to AppLogs
+func NewLogFollower(r Rack, opts types.LogsOptions, open func(opts types.LogsOptions) (io.ReadCloser, error)) *LogFollower {
+	f := &LogFollower{
+		Open:    open,
+		Options: opts,
+		cursors: Supports(r, "logs.cursors"),
+	}
+
+	f.Options.Cursors = f.cursors
+
+	return f
+}
+
+// Copy writes the stream to w until the rack ends it
+func (f *LogFollower) Copy(w io.Writer) error {
+	if !f.cursors {
+		return f.copyOffset(w)
+	}
+
+	logs, err := f.Open(f.Options)
+	if err != nil {
+		return err
+	}
+
+	failures := 0
+
+	for {
+		n, err := f.copyCursors(w, logs)
+		logs.Close()
+		if err == nil {
+			return nil
+		}
+
+		if n > 0 {
+			failures = 0
+		}
+
+		for {
+			failures++
+
+			if failures > LogReconnectAttempts {
+				return err
+			}
+
+			time.Sleep(LogReconnectDelay)
+
+			var oerr error
+
+			if logs, oerr = f.Open(f.Options); oerr == nil {
+				break
+			}
+
+			err = oerr
+		}
+	}
+}
+
+// copyCursors strips the cursor from each line and remembers the last one, a partial line is dropped to be read again after resuming
+func (f *LogFollower) copyCursors(w io.Writer, r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+
+	lines := 0
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return lines, err
+		}
+
+		if line != "" {
+			if parts := strings.SplitN(line, " ", 2); len(parts) == 2 {
+				if _, cerr := types.ParseLogCursors(parts[0]); cerr == nil {
+					f.Options.Cursor = parts[0]
+					line = parts[1]
+				}
+			}
+
+			if _, err := io.WriteString(w, line); err != nil {
+				return lines, err
+			}
+
+			lines++
+		}
+
+		if err == io.EOF {
+			return lines, nil
+		}
+	}
+}
+
+func (f *LogFollower) copyOffset(w io.Writer) error {
+	logs, err := f.Open(f.Options)
+	if err != nil {
+		return err
+	}
+
+	defer logs.Close()
+
+	if _, err := io.CopyN(ioutil.Discard, logs, f.offset); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, logs)
+
+	f.offset += n
+
+	return err
+}
+
+// Supports returns true if the rack advertises feature, an in-process rack is asked for the features its provider reports in SystemOptions
+func Supports(r Rack, feature string) bool {
+	if c, ok := r.(*Client); ok {
+		ok, err := c.Supports(feature)
+		return err == nil && ok
+	}
+
+	options, err := r.SystemOptions()
+	if err != nil {
+		return false
+	}
+
+	return hasFeature(options, feature)
+}
+
+func logsQuery(opts types.LogsOptions) Query {
+	q := Query{
+		"filter": opts.Filter,
+		"follow": fmt.Sprintf("%t", opts.Follow),
+		"prefix": fmt.Sprintf("%t", opts.Prefix),
+	}
+
+	if opts.Cursor != "" {
+		q["cursor"] = opts.Cursor
+	}
+
+	if opts.Cursors {
+		q["cursors"] = "true"
+	}
+
+	if opts.Format != "" {
+		q["format"] = opts.Format
+	}
+
+	if !opts.Since.IsZero() {
+		q["since"] = strconv.Itoa(int(opts.Since.UTC().Unix()))
+	}
+
+	return q
+}
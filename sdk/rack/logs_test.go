@@ -0,0 +1,117 @@
+package rack_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/provider/memory"
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dropped is a log stream whose connection goes away after some lines
+type dropped struct {
+	io.Reader
+}
+
+func (d dropped) Read(p []byte) (int, error) {
+	n, err := d.Reader.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (d dropped) Close() error {
+	return nil
+}
+
+func cursorRack(t *testing.T) (rack.Rack, func()) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api":"2","features":"logs.cursors","streaming":"websocket"}`))
+	}))
+
+	r, err := testServerRack(ts)
+	require.NoError(t, err)
+
+	return r, ts.Close
+}
+
+func TestLogFollowerResume(t *testing.T) {
+	rack.LogReconnectDelay = 1 * time.Millisecond
+
+	r, done := cursorRack(t)
+	defer done()
+
+	calls := []types.LogsOptions{}
+
+	f := rack.NewLogFollower(r, types.LogsOptions{Follow: true}, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		calls = append(calls, opts)
+
+		switch len(calls) {
+		case 1:
+			return dropped{strings.NewReader("100-0 one\n100-1 two\n200-0 thr")}, nil
+		case 2:
+			return nil, io.ErrUnexpectedEOF
+		default:
+			return ioutil.NopCloser(strings.NewReader("200-0 three\n")), nil
+		}
+	})
+
+	var buf bytes.Buffer
+
+	require.NoError(t, f.Copy(&buf))
+
+	assert.Equal(t, "one\ntwo\nthree\n", buf.String())
+	require.Len(t, calls, 3)
+	assert.Equal(t, types.LogsOptions{Cursors: true, Follow: true}, calls[0])
+	assert.Equal(t, "100-1", calls[1].Cursor)
+	assert.Equal(t, "100-1", calls[2].Cursor)
+}
+
+func TestLogFollowerGivesUp(t *testing.T) {
+	rack.LogReconnectDelay = 1 * time.Millisecond
+
+	r, done := cursorRack(t)
+	defer done()
+
+	calls := 0
+
+	f := rack.NewLogFollower(r, types.LogsOptions{}, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		calls++
+		return dropped{strings.NewReader("")}, nil
+	})
+
+	assert.Equal(t, io.ErrUnexpectedEOF, f.Copy(ioutil.Discard))
+	assert.Equal(t, rack.LogReconnectAttempts+1, calls)
+}
+
+func TestLogFollowerWithoutCursors(t *testing.T) {
+	r := rack.NewFromProvider(memory.New())
+
+	logs := []string{"one\n", "one\ntwo\n"}
+
+	f := rack.NewLogFollower(r, types.LogsOptions{}, func(opts types.LogsOptions) (io.ReadCloser, error) {
+		assert.False(t, opts.Cursors)
+
+		l := logs[0]
+		logs = logs[1:]
+
+		return ioutil.NopCloser(strings.NewReader(l)), nil
+	})
+
+	var buf bytes.Buffer
+
+	require.NoError(t, f.Copy(&buf))
+	require.NoError(t, f.Copy(&buf))
+
+	assert.Equal(t, "one\ntwo\n", buf.String())
+}
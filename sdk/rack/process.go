@@ -53,10 +53,7 @@ func (c *Client) ProcessList(app string, opts types.ProcessListOptions) (ps type
 
 func (c *Client) ProcessLogs(app, pid string, opts types.LogsOptions) (io.ReadCloser, error) {
 	ro := RequestOptions{
-		Query: Query{
-			"follow": fmt.Sprintf("%t", opts.Follow),
-			"prefix": fmt.Sprintf("%t", opts.Prefix),
-		},
+		Query: logsQuery(opts),
 	}
 
 	res, err := c.GetStream(fmt.Sprintf("/apps/%s/processes/%s/logs", app, pid), ro)
@@ -3,6 +3,7 @@ package rack
 import (
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/convox/praxis/types"
 )
@@ -11,6 +12,23 @@ const (
 	sortableTime = "20060102.150405.000000000"
 )
 
+var (
+	// DefaultRetries is how many times an idempotent request is retried when the rack can not be reached
+	DefaultRetries = 3
+
+	// DefaultTimeout limits each buffered request, streams are not limited
+	DefaultTimeout = 2 * time.Minute
+
+	// RetryBackoff is the delay before the first retry, it doubles with each attempt
+	RetryBackoff = 250 * time.Millisecond
+
+	// LogReconnectAttempts is how many times in a row a dropped log stream is reopened before giving up
+	LogReconnectAttempts = 5
+
+	// LogReconnectDelay is the wait before reopening a dropped log stream
+	LogReconnectDelay = 1 * time.Second
+)
+
 type Rack types.Provider
 
 func New(endpoint string) (Rack, error) {
@@ -19,7 +37,33 @@ func New(endpoint string) (Rack, error) {
 		return nil, err
 	}
 
-	return &Client{Debug: os.Getenv("CONVOX_DEBUG") == "true", Endpoint: u, Version: "dev"}, nil
+	c := &Client{
+		Debug:       os.Getenv("CONVOX_DEBUG") == "true",
+		Endpoint:    u,
+		Fingerprint: os.Getenv("RACK_FINGERPRINT"),
+		Retries:     DefaultRetries,
+		Timeout:     DefaultTimeout,
+		Version:     "dev",
+
+		capabilities: &capabilities{},
+	}
+
+	if ca := os.Getenv("RACK_CA"); ca != "" {
+		pool, err := LoadCA(ca)
+		if err != nil {
+			return nil, err
+		}
+
+		c.CA = pool
+	}
+
+	return c, nil
+}
+
+// NewFromProvider returns a rack that calls a provider in process instead of over https
+// a memory provider makes it possible to test code that talks to a rack without docker
+func NewFromProvider(p types.Provider) Rack {
+	return p
 }
 
 func NewFromEnv() (Rack, error) {
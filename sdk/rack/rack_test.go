@@ -2,9 +2,14 @@ package rack_test
 
 import (
 	"net/http/httptest"
+	"testing"
 
 	"github.com/convox/praxis/cycle"
+	"github.com/convox/praxis/provider/memory"
 	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var ts *httptest.Server
@@ -20,12 +25,44 @@ func testRack() (rack.Rack, *cycle.HTTP) {
 		panic(err)
 	}
 
+	r.(*rack.Client).Fingerprint = types.Fingerprint(c.Server.Certificate().Raw)
+
 	return r, c
 }
 
+// testServerRack returns a rack for ts that has its certificate pinned
+func testServerRack(ts *httptest.Server) (rack.Rack, error) {
+	r, err := rack.New(ts.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	r.(*rack.Client).Fingerprint = types.Fingerprint(ts.Certificate().Raw)
+
+	return r, nil
+}
+
 func cleanup() {
 	ts.Close()
 }
 
 type Server struct {
 }
+
+func TestNewFromProvider(t *testing.T) {
+	r := rack.NewFromProvider(memory.New())
+
+	_, err := r.AppCreate("app1")
+	require.NoError(t, err)
+
+	_, err = r.AppCreate("app1")
+	assert.True(t, rack.IsConflict(err))
+
+	_, err = r.AppGet("app2")
+	assert.True(t, rack.IsNotFound(err))
+
+	apps, err := r.AppList()
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "app1", apps[0].Name)
+}
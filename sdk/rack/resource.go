@@ -29,3 +29,42 @@ func (c *Client) ResourceProxy(app, resource string, in io.Reader) (io.ReadClose
 
 	return r, nil
 }
+
+func (c *Client) SystemResourceCreate(name, kind string) (r *types.Resource, err error) {
+	ro := RequestOptions{
+		Params: Params{
+			"name": name,
+			"type": kind,
+		},
+	}
+
+	err = c.Post("/system/resources", ro, &r)
+	return
+}
+
+func (c *Client) SystemResourceDelete(name string) error {
+	return c.Delete(fmt.Sprintf("/system/resources/%s", name), RequestOptions{}, nil)
+}
+
+func (c *Client) SystemResourceGet(name string) (r *types.Resource, err error) {
+	err = c.Get(fmt.Sprintf("/system/resources/%s", name), RequestOptions{}, &r)
+	return
+}
+
+func (c *Client) SystemResourceList() (rs types.Resources, err error) {
+	err = c.Get("/system/resources", RequestOptions{}, &rs)
+	return
+}
+
+func (c *Client) SystemResourceProxy(name string, in io.Reader) (io.ReadCloser, error) {
+	ro := RequestOptions{
+		Body: in,
+	}
+
+	r, err := c.Stream(fmt.Sprintf("/system/resources/%s/proxy", name), ro)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -3,7 +3,9 @@ package rack
 import (
 	"fmt"
 	"io"
-	"strconv"
+	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/convox/praxis/types"
 )
@@ -19,15 +21,7 @@ func (c *Client) SystemInstall(name string, opts types.SystemInstallOptions) (st
 
 func (c *Client) SystemLogs(opts types.LogsOptions) (io.ReadCloser, error) {
 	ro := RequestOptions{
-		Query: Query{
-			"filter": opts.Filter,
-			"follow": fmt.Sprintf("%t", opts.Follow),
-			"prefix": fmt.Sprintf("%t", opts.Prefix),
-		},
-	}
-
-	if !opts.Since.IsZero() {
-		ro.Query["since"] = strconv.Itoa(int(opts.Since.UTC().Unix()))
+		Query: logsQuery(opts),
 	}
 
 	res, err := c.GetStream("/system/logs", ro)
@@ -43,6 +37,101 @@ func (c *Client) SystemOptions() (options map[string]string, err error) {
 	return
 }
 
+type capabilities struct {
+	lock    sync.Mutex
+	options map[string]string
+	version int
+}
+
+// Capabilities returns the options the rack supports, they are fetched once and cached for the life of the client
+func (c *Client) Capabilities() (map[string]string, error) {
+	if c.capabilities == nil {
+		return c.SystemOptions()
+	}
+
+	c.capabilities.lock.Lock()
+	defer c.capabilities.lock.Unlock()
+
+	if c.capabilities.options != nil {
+		return c.capabilities.options, nil
+	}
+
+	options, err := c.SystemOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	c.capabilities.options = options
+
+	return options, nil
+}
+
+// ApiVersion returns the api version the rack speaks, racks that predate versioning report none and speak the minimum version
+// the version a rack reported on an earlier response is used without asking it again
+func (c *Client) ApiVersion() (int, error) {
+	if v := c.rackVersion(); v > 0 {
+		return v, nil
+	}
+
+	caps, err := c.Capabilities()
+	if err != nil {
+		return 0, err
+	}
+
+	return types.ParseApiVersion(caps["api"])
+}
+
+// rackVersion returns the api version from the last response that carried one, or 0 if none has
+func (c *Client) rackVersion() int {
+	if c.capabilities == nil {
+		return 0
+	}
+
+	c.capabilities.lock.Lock()
+	defer c.capabilities.lock.Unlock()
+
+	return c.capabilities.version
+}
+
+// readVersion records the api version a rack reported on a response
+func (c *Client) readVersion(res *http.Response) {
+	h := res.Header.Get(types.ApiVersionHeader)
+
+	if h == "" || c.capabilities == nil {
+		return
+	}
+
+	v, err := types.ParseApiVersion(h)
+	if err != nil {
+		return
+	}
+
+	c.capabilities.lock.Lock()
+	defer c.capabilities.lock.Unlock()
+
+	c.capabilities.version = v
+}
+
+// Supports returns true if the rack advertises feature
+func (c *Client) Supports(feature string) (bool, error) {
+	caps, err := c.Capabilities()
+	if err != nil {
+		return false, err
+	}
+
+	return hasFeature(caps, feature), nil
+}
+
+func hasFeature(options map[string]string, feature string) bool {
+	for _, f := range strings.Split(options["features"], ",") {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *Client) SystemProxy(host string, port int, in io.Reader) (io.ReadCloser, error) {
 	ro := RequestOptions{
 		Body: in,
@@ -0,0 +1,90 @@
+package rack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/convox/praxis/types"
+)
+
+// FingerprintError is returned when a rack presents a certificate other than the one pinned for it
+type FingerprintError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *FingerprintError) Error() string {
+	return fmt.Sprintf("rack certificate does not match pinned fingerprint: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// ErrUnverified is returned when there is nothing to verify the rack certificate against
+var ErrUnverified = fmt.Errorf("rack certificate can not be verified, set RACK_FINGERPRINT or RACK_CA")
+
+// LoadCA reads a pem bundle of certificate authorities to verify a rack against
+// the path system uses the certificate authorities of the host
+func LoadCA(path string) (*x509.CertPool, error) {
+	if path == "system" {
+		return x509.SystemCertPool()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return pool, nil
+}
+
+// VerifyFingerprint returns a certificate check that only accepts a leaf certificate matching fingerprint
+func VerifyFingerprint(fingerprint string) func([][]byte, [][]*x509.Certificate) error {
+	return func(raw [][]byte, _ [][]*x509.Certificate) error {
+		if len(raw) == 0 {
+			return fmt.Errorf("rack presented no certificate")
+		}
+
+		actual := types.Fingerprint(raw[0])
+
+		if normalizeFingerprint(actual) != normalizeFingerprint(fingerprint) {
+			return &FingerprintError{Expected: fingerprint, Actual: actual}
+		}
+
+		return nil
+	}
+}
+
+// tlsConfig verifies the rack against the pinned fingerprint, or the ca bundle if there is no pin
+// without either every connection fails as racks generate their own self-signed certificates
+func (c *Client) tlsConfig() *tls.Config {
+	switch {
+	case c.Fingerprint != "":
+		// the chain is not verified, only that the certificate is the pinned one
+		return &tls.Config{
+			InsecureSkipVerify:    true,
+			VerifyPeerCertificate: VerifyFingerprint(c.Fingerprint),
+		}
+	case c.CA != nil:
+		return &tls.Config{
+			RootCAs: c.CA,
+		}
+	default:
+		return &tls.Config{
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func([][]byte, [][]*x509.Certificate) error {
+				return ErrUnverified
+			},
+		}
+	}
+}
+
+func normalizeFingerprint(fp string) string {
+	return strings.ToUpper(strings.Replace(strings.TrimSpace(fp), ":", "", -1))
+}
@@ -0,0 +1,91 @@
+package rack_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/convox/praxis/sdk/rack"
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTLSServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"convox"}`))
+	}))
+}
+
+func TestFingerprintPinned(t *testing.T) {
+	ts := testTLSServer()
+	defer ts.Close()
+
+	r, err := rack.New(ts.URL)
+	require.NoError(t, err)
+
+	r.(*rack.Client).Fingerprint = strings.ToLower(types.Fingerprint(ts.Certificate().Raw))
+
+	s, err := r.SystemGet()
+	require.NoError(t, err)
+	assert.Equal(t, "convox", s.Name)
+}
+
+func TestFingerprintMismatch(t *testing.T) {
+	ts := testTLSServer()
+	defer ts.Close()
+
+	r, err := rack.New(ts.URL)
+	require.NoError(t, err)
+
+	r.(*rack.Client).Fingerprint = types.Fingerprint([]byte("other"))
+
+	_, err = r.SystemGet()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rack certificate does not match pinned fingerprint: expected "+types.Fingerprint([]byte("other"))+", got "+types.Fingerprint(ts.Certificate().Raw))
+}
+
+func TestUnverified(t *testing.T) {
+	ts := testTLSServer()
+	defer ts.Close()
+
+	r, err := rack.New(ts.URL)
+	require.NoError(t, err)
+
+	_, err = r.SystemGet()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), rack.ErrUnverified.Error())
+}
+
+func TestCA(t *testing.T) {
+	ts := testTLSServer()
+	defer ts.Close()
+
+	fd, err := ioutil.TempFile("", "ca")
+	require.NoError(t, err)
+	defer os.Remove(fd.Name())
+
+	require.NoError(t, pem.Encode(fd, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	require.NoError(t, fd.Close())
+
+	pool, err := rack.LoadCA(fd.Name())
+	require.NoError(t, err)
+
+	r, err := rack.New(ts.URL)
+	require.NoError(t, err)
+
+	r.(*rack.Client).CA = pool
+
+	_, err = r.SystemGet()
+	assert.NoError(t, err)
+
+	r.(*rack.Client).CA = x509.NewCertPool()
+
+	_, err = r.SystemGet()
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package rack
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (c *Client) TokenCreate(name string, opts types.TokenCreateOptions) (token *types.Token, err error) {
+	ro := RequestOptions{
+		Params: Params{
+			"name":  name,
+			"scope": opts.Scopes,
+		},
+	}
+
+	err = c.Post("/tokens", ro, &token)
+	return
+}
+
+func (c *Client) TokenDelete(id string) error {
+	return c.Delete(fmt.Sprintf("/tokens/%s", id), RequestOptions{}, nil)
+}
+
+func (c *Client) TokenGet(id string) (token *types.Token, err error) {
+	err = c.Get(fmt.Sprintf("/tokens/%s", id), RequestOptions{}, &token)
+	return
+}
+
+func (c *Client) TokenList() (tokens types.Tokens, err error) {
+	err = c.Get("/tokens", RequestOptions{}, &tokens)
+	return
+}
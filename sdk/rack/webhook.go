@@ -0,0 +1,29 @@
+package rack
+
+import (
+	"fmt"
+
+	"github.com/convox/praxis/types"
+)
+
+func (c *Client) WebhookCreate(url string, opts types.WebhookCreateOptions) (webhook *types.Webhook, err error) {
+	ro := RequestOptions{
+		Params: Params{
+			"action": opts.Actions,
+			"app":    opts.App,
+			"url":    url,
+		},
+	}
+
+	err = c.Post("/webhooks", ro, &webhook)
+	return
+}
+
+func (c *Client) WebhookDelete(id string) error {
+	return c.Delete(fmt.Sprintf("/webhooks/%s", id), RequestOptions{}, nil)
+}
+
+func (c *Client) WebhookList() (webhooks types.Webhooks, err error) {
+	err = c.Get("/webhooks", RequestOptions{}, &webhooks)
+	return
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/server/controllers"
+	"github.com/convox/praxis/types"
+)
+
+// params whose values are never recorded
+var auditRedacted = []string{"env", "password", "secret", "token"}
+
+// audit records a request that may have changed the rack
+func audit(c *api.Context, code int, duration time.Duration) {
+	r := c.Request()
+
+	// the handler may not have read the form
+	r.ParseForm()
+
+	params := map[string]string{}
+
+	for k := range r.Form {
+		params[k] = auditValue(r.Form.Get(k))
+
+		for _, s := range auditRedacted {
+			if strings.Contains(strings.ToLower(k), s) {
+				params[k] = "[redacted]"
+			}
+		}
+	}
+
+	actor, token := c.Actor()
+
+	app := requestApp(c)
+
+	if c.Name() == "AppCreate" {
+		app = r.Form.Get("name")
+	}
+
+	e := types.AuditEvent{
+		Id:       types.Id("E", 10),
+		Time:     time.Now().UTC(),
+		Actor:    actor,
+		Token:    token,
+		Method:   r.Method,
+		Route:    c.Name(),
+		App:      app,
+		Params:   params,
+		Code:     code,
+		Duration: int64(duration / time.Millisecond),
+	}
+
+	if err := controllers.Provider.SystemAuditRecord(e); err != nil {
+		c.Logf("at=audit error=%q", err)
+	}
+}
+
+// auditValue removes the credentials a url may carry in its userinfo or query, such as that of a webhook or drain
+func auditValue(v string) string {
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+		return v
+	}
+
+	if u.User == nil && u.RawQuery == "" {
+		return v
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+
+	return u.String()
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/helpers"
+	"github.com/convox/praxis/server/controllers"
+	"github.com/convox/praxis/types"
+)
+
+// routes that only admin tokens may call
+var adminRoutes = map[string]bool{
+	"DrainCreate":   true,
+	"DrainDelete":   true,
+	"DrainList":     true,
+	"EventSend":     true,
+	"TokenCreate":   true,
+	"TokenDelete":   true,
+	"TokenGet":      true,
+	"TokenList":     true,
+	"WebhookCreate": true,
+	"WebhookDelete": true,
+	"WebhookList":   true,
+}
+
+// routes that a deploy token may call in addition to reads
+var deployRoutes = map[string]bool{
+	"BuildCreate":    true,
+	"BuildUpdate":    true,
+	"ObjectStore":    true,
+	"ReleaseCreate":  true,
+	"ReleasePromote": true,
+}
+
+// rack-level routes that an app token may call
+var appRackRoutes = map[string]bool{
+	"SystemGet": true,
+}
+
+// authenticate accepts the rack password with full access or a token limited to its scopes
+// requests without credentials are allowed only when no password is set
+func authenticate(password string) api.Middleware {
+	return func(fn api.HandlerFunc) api.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+			key, _, ok := r.BasicAuth()
+
+			switch {
+			case !ok && password == "":
+				c.Identify("anonymous", "")
+				return fn(w, r, c)
+			case !ok:
+				return api.Errorf(401, "invalid auth")
+			case password != "" && key == password:
+				c.Identify("rack", "")
+				return fn(w, r, c)
+			}
+
+			id, secret, err := types.ParseToken(key)
+			if err != nil {
+				return api.Errorf(401, "invalid auth")
+			}
+
+			t, err := controllers.Provider.TokenGet(id)
+			if err != nil || !t.Verify(secret) {
+				return api.Errorf(401, "invalid auth")
+			}
+
+			c.Identify(t.Name, t.Id)
+
+			if !authorized(t, r, c) {
+				return api.Errorf(403, "token %s is not authorized for %s", t.Name, c.Name())
+			}
+
+			return fn(w, r, c)
+		}
+	}
+}
+
+// authorized returns true if a token grants access to a request
+func authorized(t *types.Token, r *http.Request, c *api.Context) bool {
+	if t.HasScope(types.ScopeAdmin) {
+		return true
+	}
+
+	if adminRoutes[c.Name()] {
+		return false
+	}
+
+	read := (r.Method == "GET" || r.Method == "HEAD") && r.Header.Get("Upgrade") == ""
+
+	if apps := t.Apps(); len(apps) > 0 {
+		app := requestApp(c)
+
+		switch {
+		case app == "" && appRackRoutes[c.Name()] && read:
+			return true
+		case app == "" || !helpers.In(app, apps):
+			return false
+		}
+
+		// app tokens without other scopes operate their apps but can not delete them
+		if !t.HasScope(types.ScopeDeploy) && !t.HasScope(types.ScopeRead) {
+			return c.Name() != "AppDelete"
+		}
+	}
+
+	switch {
+	case t.HasScope(types.ScopeDeploy):
+		return read || deployRoutes[c.Name()]
+	case t.HasScope(types.ScopeRead):
+		return read
+	}
+
+	return false
+}
+
+// requestApp returns the app a request operates on, if any
+func requestApp(c *api.Context) string {
+	if app := c.Var("app"); app != "" {
+		return app
+	}
+
+	switch c.Name() {
+	case "AppDelete", "AppGet":
+		return c.Var("name")
+	case "EventStream":
+		return c.Query("app")
+	}
+
+	return ""
+}
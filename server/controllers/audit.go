@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func SystemAudit(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	opts := types.SystemAuditOptions{
+		Actor: c.Query("actor"),
+		App:   c.Query("app"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return err
+		}
+
+		opts.Limit = l
+	}
+
+	if since := c.Query("since"); since != "" {
+		t, err := strconv.Atoi(since)
+		if err != nil {
+			return err
+		}
+
+		opts.Since = time.Unix(int64(t), 0)
+	}
+
+	events, err := Provider.SystemAudit(opts)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(events)
+}
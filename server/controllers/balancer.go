@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/convox/praxis/api"
+)
+
+func BalancerList(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	app := c.Var("app")
+
+	_, err := Provider.AppGet(app)
+	if err != nil {
+		return err
+	}
+
+	bs, err := Provider.BalancerList(app)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(bs, func(i, j int) bool { return bs[i].Name < bs[j].Name })
+
+	return c.RenderJSON(bs)
+}
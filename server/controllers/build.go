@@ -70,7 +70,12 @@ func BuildLogs(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 		return err
 	}
 
-	logs, err := Provider.BuildLogs(app, id)
+	opts, err := logsOptions(c)
+	if err != nil {
+		return err
+	}
+
+	logs, err := Provider.BuildLogs(app, id, opts)
 	if err != nil {
 		return err
 	}
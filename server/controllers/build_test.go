@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/convox/praxis/types"
@@ -116,3 +117,48 @@ func TestBuildUpdate(t *testing.T) {
 		string(data),
 	)
 }
+
+func TestBuildLogs(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	opts := types.LogsOptions{Cursor: "100-2", Cursors: true}
+
+	mp.On("AppGet", "app").Return(&types.App{Name: "app"}, nil)
+	mp.On("BuildLogs", "app", "BTEST", opts).Return(ioutil.NopCloser(strings.NewReader("100-3 line\n")), nil)
+
+	res, err := testRequest(ts, "GET", "/apps/app/builds/BTEST/logs?cursor=100-2&cursors=true", nil)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "100-3 line\n", string(data))
+}
+
+func TestBuildLogsInvalid(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("AppGet", "app").Return(&types.App{Name: "app"}, nil)
+
+	tests := map[string]string{
+		"cursor=foo":  "invalid cursor: foo",
+		"filter=%3D1": "invalid filter term: =1",
+		"format=xml":  "invalid log format: xml",
+	}
+
+	for query, message := range tests {
+		res, err := testRequest(ts, "GET", "/apps/app/builds/BTEST/logs?"+query, nil)
+		assert.NoError(t, err)
+
+		data, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Equal(t, 400, res.StatusCode, query)
+		assert.Contains(t, string(data), message, query)
+	}
+}
@@ -13,6 +13,9 @@ const (
 )
 
 var (
+	// Fingerprint of the certificate presented by the rack api
+	Fingerprint string
+
 	Provider types.Provider
 )
 
@@ -18,6 +18,7 @@ func mockServer() (*httptest.Server, *mocks.Provider) {
 	controllers.Provider = mp
 
 	mp.On("WithContext", mock.Anything).Return(mp)
+	mp.On("SystemAuditRecord", mock.Anything).Return(nil)
 
 	s := server.New()
 
@@ -35,6 +36,10 @@ func mockServer() (*httptest.Server, *mocks.Provider) {
 }
 
 func testRequest(ts *httptest.Server, method, path string, r io.Reader) (*http.Response, error) {
+	return testRequestHeaders(ts, method, path, r, nil)
+}
+
+func testRequestHeaders(ts *httptest.Server, method, path string, r io.Reader, headers map[string]string) (*http.Response, error) {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
@@ -53,5 +58,9 @@ func testRequest(ts *httptest.Server, method, path string, r io.Reader) (*http.R
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
 	return client.Do(req)
 }
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func DrainCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	app := c.Var("app")
+	url := c.Form("url")
+
+	if _, err := types.ParseDrainUrl(url); err != nil {
+		return api.Errorf(400, "%s", err)
+	}
+
+	drain, err := Provider.DrainCreate(app, url)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(drain)
+}
+
+func DrainDelete(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	app := c.Var("app")
+	id := c.Var("id")
+
+	return Provider.DrainDelete(app, id)
+}
+
+func DrainList(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	app := c.Var("app")
+
+	drains, err := Provider.DrainList(app)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(drains)
+}
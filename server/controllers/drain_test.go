@@ -0,0 +1,86 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainCreate(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	drain := &types.Drain{Id: "DTEST", App: "app", Url: "https://logs.example.org/batch"}
+
+	mp.On("DrainCreate", "app", "https://logs.example.org/batch").Return(drain, nil)
+
+	v := url.Values{}
+	v.Add("url", "https://logs.example.org/batch")
+
+	res, err := testRequest(ts, "POST", "/apps/app/drains", bytes.NewReader([]byte(v.Encode())))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var d types.Drain
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&d))
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "DTEST", d.Id)
+}
+
+func TestDrainCreateInvalid(t *testing.T) {
+	ts, _ := mockServer()
+	defer ts.Close()
+
+	v := url.Values{}
+	v.Add("url", "ftp://logs.example.org")
+
+	res, err := testRequest(ts, "POST", "/apps/app/drains", bytes.NewReader([]byte(v.Encode())))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Contains(t, string(data), "invalid drain url: ftp://logs.example.org")
+}
+
+func TestDrainList(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("DrainList", "app").Return(types.Drains{{Id: "DTEST", App: "app", Url: "syslog+tls://logs.example.org:6514"}}, nil)
+
+	res, err := testRequest(ts, "GET", "/apps/app/drains", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var ds types.Drains
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&ds))
+
+	assert.Equal(t, 200, res.StatusCode)
+	require.Len(t, ds, 1)
+	assert.Equal(t, "DTEST", ds[0].Id)
+}
+
+func TestDrainDelete(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("DrainDelete", "app", "DTEST").Return(nil)
+
+	res, err := testRequest(ts, "DELETE", "/apps/app/drains/DTEST", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+}
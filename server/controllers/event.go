@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func EventSend(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	action := c.Form("action")
+
+	if action == "" {
+		return api.Errorf(400, "action required")
+	}
+
+	opts := types.EventSendOptions{
+		App:   c.Form("app"),
+		Error: c.Form("error"),
+	}
+
+	if d := c.Form("data"); d != "" {
+		if err := json.Unmarshal([]byte(d), &opts.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := Provider.EventSend(action, opts); err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func EventStream(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	opts := types.EventStreamOptions{
+		App: c.Query("app"),
+	}
+
+	if a := c.Query("actions"); a != "" {
+		opts.Actions = strings.Split(a, ",")
+	}
+
+	events, err := Provider.WithContext(c.Context()).EventStream(opts)
+	if err != nil {
+		return err
+	}
+
+	defer events.Close()
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(200)
+
+	f, _ := w.(http.Flusher)
+
+	if f != nil {
+		f.Flush()
+	}
+
+	s := bufio.NewScanner(events)
+
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", s.Bytes()); err != nil {
+			return nil
+		}
+
+		if f != nil {
+			f.Flush()
+		}
+	}
+
+	return s.Err()
+}
@@ -0,0 +1,31 @@
+package controllers_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	events := ioutil.NopCloser(strings.NewReader("{\"action\":\"app:create\"}\n{\"action\":\"app:delete\"}\n"))
+
+	mp.On("EventStream", types.EventStreamOptions{Actions: []string{"app:create", "app:delete"}}).Return(events, nil)
+
+	res, err := testRequest(ts, "GET", "/events?actions=app:create,app:delete", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	assert.Equal(t, "data: {\"action\":\"app:create\"}\n\ndata: {\"action\":\"app:delete\"}\n\n", string(data))
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+// logsOptions reads the query params shared by the log endpoints
+func logsOptions(c *api.Context) (types.LogsOptions, error) {
+	opts := types.LogsOptions{
+		Cursor:  c.Query("cursor"),
+		Cursors: c.Query("cursors") == "true",
+		Filter:  c.Query("filter"),
+		Follow:  c.Query("follow") == "true",
+		Format:  c.Query("format"),
+		Prefix:  c.Query("prefix") == "true",
+	}
+
+	if opts.Cursor != "" {
+		if _, err := types.ParseLogCursors(opts.Cursor); err != nil {
+			return opts, api.Errorf(400, "%s", err)
+		}
+	}
+
+	switch opts.Format {
+	case "", types.LogFormatJSON, types.LogFormatText:
+	default:
+		return opts, api.Errorf(400, "invalid log format: %s", opts.Format)
+	}
+
+	if _, err := types.ParseLogFilter(opts.Filter); err != nil {
+		return opts, api.Errorf(400, "%s", err)
+	}
+
+	if since := c.Query("since"); since != "" {
+		t, err := strconv.Atoi(since)
+		if err != nil {
+			return opts, api.Errorf(400, "invalid since: %s", since)
+		}
+
+		opts.Since = time.Unix(int64(t), 0)
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/metrics"
+)
+
+func Metrics(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	return metrics.Write(w)
+}
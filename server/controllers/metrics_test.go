@@ -0,0 +1,33 @@
+package controllers_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("AppList").Return(types.Apps{}, nil)
+
+	res, err := testRequest(ts, "GET", "/apps", nil)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	res, err = testRequest(ts, "GET", "/metrics", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Contains(t, string(data), "# TYPE convox_api_requests_total counter\n")
+	assert.Contains(t, string(data), `convox_api_requests_total{route="AppList",method="GET",code="200"}`)
+	assert.Contains(t, string(data), `convox_api_request_duration_seconds_count{route="AppList"}`)
+}
@@ -106,9 +106,9 @@ func ProcessLogs(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 	app := c.Var("app")
 	pid := c.Var("pid")
 
-	opts := types.LogsOptions{
-		Follow: c.Query("follow") == "true",
-		Prefix: c.Query("prefix") == "true",
+	opts, err := logsOptions(c)
+	if err != nil {
+		return err
 	}
 
 	logs, err := Provider.ProcessLogs(app, pid, opts)
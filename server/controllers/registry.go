@@ -25,6 +25,11 @@ func RegistryList(w http.ResponseWriter, r *http.Request, c *api.Context) error
 		return err
 	}
 
+	// registry credentials are handed to builds by the provider and never leave the rack
+	for i := range registries {
+		registries[i].Password = ""
+	}
+
 	return c.RenderJSON(registries)
 }
 
@@ -0,0 +1,30 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryList(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("RegistryList").Return(types.Registries{{Hostname: "registry.example.org", Username: "user", Password: "secret"}}, nil)
+
+	res, err := testRequest(ts, "GET", "/registries", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var rs types.Registries
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&rs))
+
+	assert.Equal(t, 200, res.StatusCode)
+	require.Len(t, rs, 1)
+	assert.Equal(t, "user", rs[0].Username)
+	assert.Equal(t, "", rs[0].Password)
+}
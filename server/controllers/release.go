@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
-	"time"
 
 	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
@@ -98,19 +97,9 @@ func ReleaseLogs(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 		return err
 	}
 
-	opts := types.LogsOptions{
-		Filter: c.Query("filter"),
-		Follow: c.Query("follow") == "true",
-		Prefix: c.Query("prefix") == "true",
-	}
-
-	if since := c.Query("since"); since != "" {
-		t, err := strconv.Atoi(since)
-		if err != nil {
-			return err
-		}
-
-		opts.Since = time.Unix(int64(t), 0)
+	opts, err := logsOptions(c)
+	if err != nil {
+		return err
 	}
 
 	logs, err := Provider.ReleaseLogs(app, id, opts)
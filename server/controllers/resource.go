@@ -56,3 +56,64 @@ func ResourceProxy(rw io.ReadWriteCloser, c *api.Context) error {
 
 	return nil
 }
+
+func SystemResourceCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	name := c.Form("name")
+	kind := c.Form("type")
+
+	rr, err := Provider.SystemResourceCreate(name, kind)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(rr)
+}
+
+func SystemResourceDelete(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	name := c.Var("name")
+
+	if err := Provider.SystemResourceDelete(name); err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func SystemResourceGet(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	name := c.Var("name")
+
+	rr, err := Provider.SystemResourceGet(name)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(rr)
+}
+
+func SystemResourceList(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	rs, err := Provider.SystemResourceList()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+
+	return c.RenderJSON(rs)
+}
+
+func SystemResourceProxy(rw io.ReadWriteCloser, c *api.Context) error {
+	name := c.Var("name")
+
+	p, err := Provider.SystemResourceProxy(name, rw)
+	if err != nil {
+		return err
+	}
+
+	defer p.Close()
+
+	if err := helpers.Stream(rw, p); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,8 +2,9 @@ package controllers
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/helpers"
@@ -16,23 +17,17 @@ func SystemGet(w http.ResponseWriter, r *http.Request, c *api.Context) error {
 		return err
 	}
 
+	if Fingerprint != "" {
+		system.Fingerprint = Fingerprint
+	}
+
 	return c.RenderJSON(system)
 }
 
 func SystemLogs(w http.ResponseWriter, r *http.Request, c *api.Context) error {
-	opts := types.LogsOptions{
-		Filter: c.Query("filter"),
-		Follow: c.Query("follow") == "true",
-		Prefix: c.Query("prefix") == "true",
-	}
-
-	if since := c.Query("since"); since != "" {
-		t, err := strconv.Atoi(since)
-		if err != nil {
-			return err
-		}
-
-		opts.Since = time.Unix(int64(t), 0)
+	opts, err := logsOptions(c)
+	if err != nil {
+		return err
 	}
 
 	logs, err := Provider.SystemLogs(opts)
@@ -55,6 +50,22 @@ func SystemOptions(w http.ResponseWriter, r *http.Request, c *api.Context) error
 		return err
 	}
 
+	if options == nil {
+		options = map[string]string{}
+	}
+
+	options["api"] = strconv.Itoa(types.ApiVersion)
+	options["api.min"] = strconv.Itoa(types.ApiVersionMin)
+	features := append([]string{}, types.ApiFeatures...)
+
+	if pf := options["features"]; pf != "" {
+		features = append(features, strings.Split(pf, ",")...)
+	}
+
+	sort.Strings(features)
+
+	options["features"] = strings.Join(features, ",")
+
 	return c.RenderJSON(options)
 }
 
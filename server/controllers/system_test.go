@@ -0,0 +1,88 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemOptions(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("SystemOptions").Return(map[string]string{"streaming": "websocket"}, nil)
+
+	res, err := testRequest(ts, "OPTIONS", "/system", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, strconv.Itoa(types.ApiVersion), res.Header.Get(types.ApiVersionHeader))
+
+	var options map[string]string
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&options))
+
+	assert.Equal(t, "websocket", options["streaming"])
+	assert.Equal(t, strconv.Itoa(types.ApiVersion), options["api"])
+	assert.Equal(t, strconv.Itoa(types.ApiVersionMin), options["api.min"])
+	assert.Contains(t, options["features"], "tokens")
+	assert.NotContains(t, options["features"], "logs.drains")
+}
+
+func TestSystemOptionsProviderFeatures(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("SystemOptions").Return(map[string]string{"features": "logs.drains"}, nil)
+
+	res, err := testRequest(ts, "OPTIONS", "/system", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var options map[string]string
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&options))
+
+	assert.Equal(t, "audit,events,logs.cursors,logs.drains,logs.filters,logs.json,metrics,openapi,tokens,webhooks", options["features"])
+}
+
+func TestSystemApiVersion(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("SystemGet").Return(&types.System{Name: "test"}, nil)
+
+	tests := []struct {
+		Version string
+		Code    int
+		Error   string
+	}{
+		{"", 200, ""},
+		{strconv.Itoa(types.ApiVersion), 200, ""},
+		{strconv.Itoa(types.ApiVersion + 1), 200, ""},
+		{strconv.Itoa(types.ApiVersionMin - 1), 400, "client api version 0 is no longer supported by this rack (minimum 1), update with: cx update"},
+		{"-1", 400, "invalid api version: -1"},
+		{"foo", 400, "invalid api version: foo"},
+	}
+
+	for _, tt := range tests {
+		res, err := testRequestHeaders(ts, "GET", "/system", nil, map[string]string{types.ApiVersionHeader: tt.Version})
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.Code, res.StatusCode, tt.Version)
+
+		if tt.Error != "" {
+			assert.Contains(t, string(data), tt.Error)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func TokenCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	name := c.Form("name")
+
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	token, err := Provider.TokenCreate(name, types.TokenCreateOptions{Scopes: r.Form["scope"]})
+	if err != nil {
+		return err
+	}
+
+	token.Hash = ""
+
+	return c.RenderJSON(token)
+}
+
+func TokenDelete(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	id := c.Var("id")
+
+	return Provider.TokenDelete(id)
+}
+
+func TokenGet(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	id := c.Var("id")
+
+	token, err := Provider.TokenGet(id)
+	if err != nil {
+		return err
+	}
+
+	token.Hash = ""
+	token.Secret = ""
+
+	return c.RenderJSON(token)
+}
+
+func TokenList(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	tokens, err := Provider.TokenList()
+	if err != nil {
+		return err
+	}
+
+	for i := range tokens {
+		tokens[i].Hash = ""
+		tokens[i].Secret = ""
+	}
+
+	return c.RenderJSON(tokens)
+}
@@ -0,0 +1,158 @@
+package controllers_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCreate(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	token := &types.Token{Id: "TTEST", Name: "ci", Scopes: []string{"deploy", "app:web"}, Hash: "hash", Secret: "secret"}
+
+	mp.On("TokenCreate", "ci", types.TokenCreateOptions{Scopes: []string{"deploy", "app:web"}}).Return(token, nil)
+
+	v := url.Values{}
+	v.Add("name", "ci")
+	v.Add("scope", "deploy")
+	v.Add("scope", "app:web")
+
+	res, err := testRequest(ts, "POST", "/tokens", bytes.NewReader([]byte(v.Encode())))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var tk types.Token
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&tk))
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "TTEST", tk.Id)
+	assert.Equal(t, "", tk.Hash)
+	assert.Equal(t, "secret", tk.Secret)
+}
+
+func TestTokenList(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	tokens := types.Tokens{
+		{Id: "TTEST", Name: "ci", Scopes: []string{"read"}, Hash: "hash"},
+	}
+
+	mp.On("TokenList").Return(tokens, nil)
+
+	res, err := testRequest(ts, "GET", "/tokens", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var tks types.Tokens
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&tks))
+
+	assert.Equal(t, 200, res.StatusCode)
+	require.Len(t, tks, 1)
+	assert.Equal(t, "", tks[0].Hash)
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	os.Setenv("PASSWORD", "password")
+	defer os.Unsetenv("PASSWORD")
+
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	read, err := types.NewToken("web-read", []string{"read", "app:web"})
+	require.NoError(t, err)
+
+	deploy, err := types.NewToken("deploy", []string{"deploy"})
+	require.NoError(t, err)
+
+	app, err := types.NewToken("app-web", []string{"app:web"})
+	require.NoError(t, err)
+
+	appAdmin, err := types.NewToken("app-web-admin", []string{"admin", "app:web"})
+	require.NoError(t, err)
+
+	mp.On("TokenGet", read.Id).Return(read, nil)
+	mp.On("TokenGet", appAdmin.Id).Return(appAdmin, nil)
+	mp.On("AppDelete", "web").Return(nil)
+	mp.On("TokenGet", deploy.Id).Return(deploy, nil)
+	mp.On("TokenGet", app.Id).Return(app, nil)
+	mp.On("AppList").Return(types.Apps{}, nil)
+	mp.On("AppGet", "web").Return(&types.App{Name: "web"}, nil)
+	mp.On("AppGet", "other").Return(&types.App{Name: "other"}, nil)
+	mp.On("BuildList", "web").Return(types.Builds{}, nil)
+	mp.On("BuildList", "other").Return(types.Builds{}, nil)
+	mp.On("RegistryList").Return(types.Registries{}, nil)
+	mp.On("QueueStore", "web", "jobs", map[string]string{}).Return(nil)
+
+	tests := []struct {
+		Key    string
+		Method string
+		Path   string
+		Code   int
+	}{
+		{"", "GET", "/apps", 401},
+		{"wrong", "GET", "/apps", 401},
+		{"password", "GET", "/apps", 200},
+		{read.Id + ".wrong", "GET", "/apps", 401},
+		{read.Value(), "GET", "/apps", 403},
+		{read.Value(), "GET", "/apps/web", 200},
+		{read.Value(), "GET", "/apps/web/builds", 200},
+		{read.Value(), "GET", "/apps/other/builds", 403},
+		{read.Value(), "POST", "/apps/web/queues/jobs", 403},
+		{read.Value(), "GET", "/tokens", 403},
+		{deploy.Value(), "GET", "/apps", 200},
+		{deploy.Value(), "GET", "/apps/other/builds", 200},
+		{deploy.Value(), "POST", "/apps/web/queues/jobs", 403},
+		{app.Value(), "GET", "/apps/web/builds", 200},
+		{app.Value(), "POST", "/apps/web/queues/jobs", 200},
+		{app.Value(), "GET", "/apps/other/builds", 403},
+		{app.Value(), "GET", "/registries", 403},
+		{app.Value(), "GET", "/apps", 403},
+		{app.Value(), "DELETE", "/apps/web", 403},
+		{appAdmin.Value(), "DELETE", "/apps/web", 200},
+	}
+
+	for _, tt := range tests {
+		res, err := testAuthRequest(ts, tt.Method, tt.Path, tt.Key, nil)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, tt.Code, res.StatusCode, "%s %s", tt.Method, tt.Path)
+	}
+}
+
+func testAuthRequest(ts *httptest.Server, method, path, key string, r io.Reader) (*http.Response, error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	req, err := http.NewRequest(method, ts.URL+path, r)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	if key != "" {
+		req.SetBasicAuth(key, "")
+	}
+
+	return client.Do(req)
+}
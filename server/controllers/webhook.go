@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+func WebhookCreate(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	url := c.Form("url")
+
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	opts := types.WebhookCreateOptions{
+		Actions: r.Form["action"],
+		App:     r.Form.Get("app"),
+	}
+
+	webhook, err := Provider.WebhookCreate(url, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(webhook)
+}
+
+func WebhookDelete(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	id := c.Var("id")
+
+	return Provider.WebhookDelete(id)
+}
+
+func WebhookList(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+	webhooks, err := Provider.WebhookList()
+	if err != nil {
+		return err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return c.RenderJSON(webhooks)
+}
@@ -0,0 +1,57 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookCreate(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	webhook := &types.Webhook{Id: "WTEST", Url: "https://example.org/hook", Actions: []string{"release:*"}, Secret: "secret"}
+
+	mp.On("WebhookCreate", "https://example.org/hook", types.WebhookCreateOptions{Actions: []string{"release:*"}, App: "web"}).Return(webhook, nil)
+
+	v := url.Values{}
+	v.Add("url", "https://example.org/hook")
+	v.Add("action", "release:*")
+	v.Add("app", "web")
+
+	res, err := testRequest(ts, "POST", "/webhooks", bytes.NewReader([]byte(v.Encode())))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var w types.Webhook
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&w))
+
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "WTEST", w.Id)
+	assert.Equal(t, "secret", w.Secret)
+}
+
+func TestWebhookList(t *testing.T) {
+	ts, mp := mockServer()
+	defer ts.Close()
+
+	mp.On("WebhookList").Return(types.Webhooks{{Id: "WTEST", Url: "https://example.org/hook", Secret: "secret"}}, nil)
+
+	res, err := testRequest(ts, "GET", "/webhooks", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var ws types.Webhooks
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&ws))
+
+	assert.Equal(t, 200, res.StatusCode)
+	require.Len(t, ws, 1)
+	assert.Equal(t, "", ws[0].Secret)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/server/controllers"
+	"github.com/convox/praxis/types"
 )
 
 func Routes(server *api.Server) {
@@ -15,72 +16,99 @@ func Routes(server *api.Server) {
 		fmt.Fprintf(w, "ok")
 	})
 
+	server.Router.HandleFunc(api.OpenAPIPath, server.OpenAPIHandler("Convox Rack API", coalesce(os.Getenv("VERSION"), "dev")))
+
 	auth := server.Subrouter("/")
 
-	if pw := os.Getenv("PASSWORD"); pw != "" {
-		auth.Use(authenticate(pw))
-	}
+	auth.Use(negotiate)
+	auth.Use(authenticate(os.Getenv("PASSWORD")))
 
-	auth.Route("POST", "/apps", controllers.AppCreate)
+	auth.Route("POST", "/apps", controllers.AppCreate).Form("name", "string").Required("name").Returns(types.App{})
 	auth.Route("DELETE", "/apps/{name}", controllers.AppDelete)
-	auth.Route("GET", "/apps/{name}", controllers.AppGet)
-	auth.Route("GET", "/apps", controllers.AppList)
-	auth.Route("GET", "/apps/{app}/logs", controllers.AppLogs)
-	auth.Route("GET", "/apps/{app}/registry", controllers.AppRegistry)
-
-	auth.Route("POST", "/apps/{app}/builds", controllers.BuildCreate)
-	auth.Route("GET", "/apps/{app}/builds/{id}", controllers.BuildGet)
-	auth.Route("GET", "/apps/{app}/builds", controllers.BuildList)
-	auth.Route("GET", "/apps/{app}/builds/{id}/logs", controllers.BuildLogs)
-	auth.Route("PUT", "/apps/{app}/builds/{id}", controllers.BuildUpdate)
-
-	auth.Route("GET", "/apps/{app}/caches/{cache}/{key}", controllers.CacheFetch)
+	auth.Route("GET", "/apps/{name}", controllers.AppGet).Returns(types.App{})
+	auth.Route("GET", "/apps", controllers.AppList).Returns(types.Apps{})
+	auth.Route("GET", "/apps/{app}/logs", controllers.AppLogs).Query("cursor", "string").Query("cursors", "boolean").Query("filter", "string").Query("follow", "boolean").Query("format", "string").Query("prefix", "boolean").Query("since", "integer").Produces("text/plain")
+	auth.Route("GET", "/apps/{app}/registry", controllers.AppRegistry).Returns(types.Registry{})
+
+	auth.Route("GET", "/apps/{app}/balancers", controllers.BalancerList).Returns(types.Balancers{})
+
+	auth.Route("POST", "/apps/{app}/builds", controllers.BuildCreate).Form("cache", "boolean").Form("development", "boolean").Form("url", "string").Required("url").Returns(types.Build{})
+	auth.Route("GET", "/apps/{app}/builds/{id}", controllers.BuildGet).Returns(types.Build{})
+	auth.Route("GET", "/apps/{app}/builds", controllers.BuildList).Returns(types.Builds{})
+	auth.Route("GET", "/apps/{app}/builds/{id}/logs", controllers.BuildLogs).Query("cursor", "string").Query("cursors", "boolean").Query("filter", "string").Query("format", "string").Produces("text/plain")
+	auth.Route("PUT", "/apps/{app}/builds/{id}", controllers.BuildUpdate).Form("ended", "string").Form("manifest", "string").Form("release", "string").Form("started", "string").Form("status", "string").Returns(types.Build{})
+
+	auth.Route("GET", "/apps/{app}/caches/{cache}/{key}", controllers.CacheFetch).Returns(map[string]string{})
 	auth.Route("POST", "/apps/{app}/caches/{cache}/{key}", controllers.CacheStore)
 
-	auth.Route("DELETE", "/apps/{app}/processes/{process}/files", controllers.FilesDelete)
-	auth.Route("POST", "/apps/{app}/processes/{process}/files", controllers.FilesUpload)
+	auth.Route("POST", "/apps/{app}/drains", controllers.DrainCreate).Form("url", "string").Required("url").Returns(types.Drain{})
+	auth.Route("DELETE", "/apps/{app}/drains/{id}", controllers.DrainDelete)
+	auth.Route("GET", "/apps/{app}/drains", controllers.DrainList).Returns(types.Drains{})
+
+	auth.Route("POST", "/events", controllers.EventSend).Form("action", "string").Form("app", "string").Form("data", "string").Form("error", "string").Required("action")
+	auth.Route("GET", "/events", controllers.EventStream).Query("actions", "string").Query("app", "string").Produces("text/event-stream")
+
+	auth.Route("DELETE", "/apps/{app}/processes/{process}/files", controllers.FilesDelete).Body("application/x-www-form-urlencoded")
+	auth.Route("POST", "/apps/{app}/processes/{process}/files", controllers.FilesUpload).Body("application/x-tar")
 
-	auth.Route("POST", "/apps/{app}/keys/{key}/decrypt", controllers.KeyDecrypt)
-	auth.Route("POST", "/apps/{app}/keys/{key}/encrypt", controllers.KeyEncrypt)
+	auth.Route("POST", "/apps/{app}/keys/{key}/decrypt", controllers.KeyDecrypt).Body("application/octet-stream").Produces("application/octet-stream")
+	auth.Route("POST", "/apps/{app}/keys/{key}/encrypt", controllers.KeyEncrypt).Body("application/octet-stream").Produces("application/octet-stream")
 
 	auth.Route("HEAD", "/apps/{app}/objects/{key:.*}", controllers.ObjectExists)
-	auth.Route("GET", "/apps/{app}/objects/{key:.*}", controllers.ObjectFetch)
-	auth.Route("POST", "/apps/{app}/objects/{key:.*}", controllers.ObjectStore)
-
-	auth.Stream("process.exec", "/apps/{app}/processes/{pid}/exec", controllers.ProcessExec)
-	auth.Stream("process.run", "/apps/{app}/processes/run", controllers.ProcessRun)
-	auth.Route("GET", "/apps/{app}/processes/{pid}", controllers.ProcessGet)
-	auth.Route("GET", "/apps/{app}/processes/{pid}/logs", controllers.ProcessLogs)
-	auth.Route("GET", "/apps/{app}/processes", controllers.ProcessList)
+	auth.Route("GET", "/apps/{app}/objects/{key:.*}", controllers.ObjectFetch).Produces("application/octet-stream")
+	auth.Route("POST", "/apps/{app}/objects/{key:.*}", controllers.ObjectStore).Body("application/octet-stream").Returns(types.Object{})
+
+	auth.Stream("process.exec", "/apps/{app}/processes/{pid}/exec", controllers.ProcessExec).Header("Command", "string").Header("Height", "integer").Header("Width", "integer").Required("Command")
+	auth.Stream("process.run", "/apps/{app}/processes/run", controllers.ProcessRun).Header("Command", "string").Header("Environment", "string").Header("Height", "integer").Header("Image", "string").Header("Links", "string").Header("Name", "string").Header("Ports", "string").Header("Release", "string").Header("Service", "string").Header("Volumes", "string").Header("Width", "integer")
+	auth.Route("GET", "/apps/{app}/processes/{pid}", controllers.ProcessGet).Returns(types.Process{})
+	auth.Route("GET", "/apps/{app}/processes/{pid}/logs", controllers.ProcessLogs).Query("cursor", "string").Query("cursors", "boolean").Query("filter", "string").Query("follow", "boolean").Query("format", "string").Query("prefix", "boolean").Query("since", "integer").Produces("text/plain")
+	auth.Route("GET", "/apps/{app}/processes", controllers.ProcessList).Query("service", "string").Returns(types.Processes{})
 	auth.Stream("process.proxy", "/apps/{app}/processes/{pid}/proxy/{port}", controllers.ProcessProxy)
-	auth.Route("POST", "/apps/{app}/processes", controllers.ProcessStart)
+	auth.Route("POST", "/apps/{app}/processes", controllers.ProcessStart).Form("command", "string").Form("environment", "string").Form("image", "string").Form("links", "string").Form("name", "string").Form("ports", "string").Form("release", "string").Form("service", "string").Form("volumes", "string").Returns("")
 	auth.Route("DELETE", "/apps/{app}/processes/{pid}", controllers.ProcessStop)
 
-	auth.Route("GET", "/apps/{app}/queues/{queue}", controllers.QueueFetch)
+	auth.Route("GET", "/metrics", controllers.Metrics).Produces("text/plain")
+
+	auth.Route("GET", "/apps/{app}/queues/{queue}", controllers.QueueFetch).Returns(map[string]string{})
 	auth.Route("POST", "/apps/{app}/queues/{queue}", controllers.QueueStore)
 
-	auth.Route("POST", "/registries", controllers.RegistryAdd)
-	auth.Route("GET", "/registries", controllers.RegistryList)
+	auth.Route("POST", "/registries", controllers.RegistryAdd).Form("hostname", "string").Form("password", "string").Form("username", "string").Required("hostname").Returns(types.Registry{})
+	auth.Route("GET", "/registries", controllers.RegistryList).Returns(types.Registries{})
 	auth.Route("DELETE", "/registries/{hostname:.*}", controllers.RegistryRemove)
 
-	auth.Route("POST", "/apps/{app}/releases", controllers.ReleaseCreate)
-	auth.Route("GET", "/apps/{app}/releases/{id}", controllers.ReleaseGet)
-	auth.Route("GET", "/apps/{app}/releases", controllers.ReleaseList)
-	auth.Route("GET", "/apps/{app}/releases/{id}/logs", controllers.ReleaseLogs)
+	auth.Route("POST", "/apps/{app}/releases", controllers.ReleaseCreate).Form("build", "string").Form("env", "string").Returns(types.Release{})
+	auth.Route("GET", "/apps/{app}/releases/{id}", controllers.ReleaseGet).Returns(types.Release{})
+	auth.Route("GET", "/apps/{app}/releases", controllers.ReleaseList).Query("count", "integer").Returns(types.Releases{})
+	auth.Route("GET", "/apps/{app}/releases/{id}/logs", controllers.ReleaseLogs).Query("cursor", "string").Query("cursors", "boolean").Query("filter", "string").Query("follow", "boolean").Query("format", "string").Query("prefix", "boolean").Query("since", "integer").Produces("text/plain")
 	auth.Route("POST", "/apps/{app}/releases/{id}", controllers.ReleasePromote)
 
 	auth.Stream("resource.proxy", "/apps/{app}/resources/{name}/proxy", controllers.ResourceProxy)
-	auth.Route("GET", "/apps/{app}/resources/{name}", controllers.ResourceGet)
-	auth.Route("GET", "/apps/{app}/resources", controllers.ResourceList)
+	auth.Route("GET", "/apps/{app}/resources/{name}", controllers.ResourceGet).Returns(types.Resource{})
+	auth.Route("GET", "/apps/{app}/resources", controllers.ResourceList).Returns(types.Resources{})
 
-	auth.Route("GET", "/apps/{app}/services/{name}", controllers.ServiceGet)
-	auth.Route("GET", "/apps/{app}/services", controllers.ServiceList)
+	auth.Route("GET", "/apps/{app}/services/{name}", controllers.ServiceGet).Returns(types.Service{})
+	auth.Route("GET", "/apps/{app}/services", controllers.ServiceList).Returns(types.Services{})
 
 	// auth.Stream("system.proxy", "/system/proxy/{host}/{port}", controllers.SystemProxy)
-	auth.Route("GET", "/system", controllers.SystemGet)
-	auth.Route("GET", "/system/logs", controllers.SystemLogs)
-	auth.Route("OPTIONS", "/system", controllers.SystemOptions)
-	auth.Route("POST", "/system", controllers.SystemUpdate)
+	auth.Route("GET", "/system", controllers.SystemGet).Returns(types.System{})
+	auth.Route("GET", "/system/audit", controllers.SystemAudit).Query("actor", "string").Query("app", "string").Query("limit", "integer").Query("since", "integer").Returns(types.AuditEvents{})
+	auth.Route("GET", "/system/logs", controllers.SystemLogs).Query("cursor", "string").Query("cursors", "boolean").Query("filter", "string").Query("follow", "boolean").Query("format", "string").Query("prefix", "boolean").Query("since", "integer").Produces("text/plain")
+	auth.Route("OPTIONS", "/system", controllers.SystemOptions).Returns(map[string]string{})
+	auth.Stream("system.resource.proxy", "/system/resources/{name}/proxy", controllers.SystemResourceProxy)
+	auth.Route("POST", "/system/resources", controllers.SystemResourceCreate).Form("name", "string").Form("type", "string").Required("name", "type").Returns(types.Resource{})
+	auth.Route("DELETE", "/system/resources/{name}", controllers.SystemResourceDelete)
+	auth.Route("GET", "/system/resources/{name}", controllers.SystemResourceGet).Returns(types.Resource{})
+	auth.Route("GET", "/system/resources", controllers.SystemResourceList).Returns(types.Resources{})
+	auth.Route("POST", "/system", controllers.SystemUpdate).Form("password", "string").Form("version", "string")
+
+	auth.Route("POST", "/tokens", controllers.TokenCreate).Form("name", "string").Form("scope", "array").Required("name", "scope").Returns(types.Token{})
+	auth.Route("DELETE", "/tokens/{id}", controllers.TokenDelete)
+	auth.Route("GET", "/tokens/{id}", controllers.TokenGet).Returns(types.Token{})
+	auth.Route("GET", "/tokens", controllers.TokenList).Returns(types.Tokens{})
+
+	auth.Route("POST", "/webhooks", controllers.WebhookCreate).Form("action", "array").Form("app", "string").Form("url", "string").Required("url").Returns(types.Webhook{})
+	auth.Route("DELETE", "/webhooks/{id}", controllers.WebhookDelete)
+	auth.Route("GET", "/webhooks", controllers.WebhookList).Returns(types.Webhooks{})
 
 	// pprof
 	auth.Router.HandleFunc("/debug/pprof/profile", pprof.Profile)
@@ -88,17 +116,3 @@ func Routes(server *api.Server) {
 	auth.Router.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	auth.Router.HandleFunc("/debug/pprof/{topic:.*}", pprof.Index)
 }
-
-func authenticate(password string) api.Middleware {
-	return func(fn api.HandlerFunc) api.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request, c *api.Context) error {
-			key, _, ok := r.BasicAuth()
-
-			if !ok || key != password {
-				return api.Errorf(401, "invalid auth")
-			}
-
-			return fn(w, r, c)
-		}
-	}
-}
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/convox/praxis/api"
 	"github.com/convox/praxis/server/controllers"
 	"github.com/pkg/errors"
@@ -13,15 +16,45 @@ type Server struct {
 func New() *Server {
 	server := api.New("api", "rack.convox")
 
+	server.Audit = audit
+
 	Routes(server)
 
 	return &Server{Server: server}
 }
 
 func (s *Server) Setup() error {
+	dir := coalesce(os.Getenv("CERTIFICATE_DIR"), os.Getenv("PROVIDER_ROOT"), "/var/convox")
+
+	cert, err := api.LoadCertificate(s.Hostname, filepath.Join(dir, "rack.crt"), filepath.Join(dir, "rack.key"))
+	if err != nil {
+		// fall back to a certificate generated for this process, clients will need to pin it again after a restart
+		s.Logger.At("setup").Error(err)
+
+		if cert, err = api.GenerateSelfSignedCertificate(s.Hostname); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	s.Certificate = &cert
+	controllers.Fingerprint = s.Fingerprint()
+
+	// processes started by the provider pin the rack certificate with this
+	os.Setenv("RACK_FINGERPRINT", controllers.Fingerprint)
+
 	if err := controllers.Setup(); err != nil {
 		return errors.WithStack(err)
 	}
 
 	return nil
 }
+
+func coalesce(ss ...string) string {
+	for _, s := range ss {
+		if s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
@@ -1,6 +1,54 @@
 package server_test
 
-import "testing"
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 func TestServer(t *testing.T) {
 }
+
+func TestOpenAPI(t *testing.T) {
+	s := server.New()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", api.OpenAPIPath, nil))
+	require.Equal(t, 200, w.Code)
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationId string `json:"operationId"`
+		}
+		Components struct {
+			Schemas map[string]interface{}
+		}
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+
+	ops := map[string]bool{}
+
+	for _, e := range s.Endpoints() {
+		ops[e.Name] = true
+	}
+
+	documented := map[string]bool{}
+
+	for _, methods := range doc.Paths {
+		for _, op := range methods {
+			documented[op.OperationId] = true
+		}
+	}
+
+	assert.Equal(t, ops, documented)
+	assert.Equal(t, "AppGet", doc.Paths["/apps/{name}"]["get"].OperationId)
+	assert.Equal(t, "ObjectFetch", doc.Paths["/apps/{app}/objects/{key}"]["get"].OperationId)
+	assert.Contains(t, doc.Components.Schemas, "App")
+	assert.Contains(t, doc.Components.Schemas, "Release")
+}
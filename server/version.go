@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/convox/praxis/api"
+	"github.com/convox/praxis/types"
+)
+
+// negotiate rejects clients that speak an api version this rack no longer serves
+// clients newer than the rack are served and read the rack version from the response to adapt
+func negotiate(fn api.HandlerFunc) api.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, c *api.Context) error {
+		w.Header().Set(types.ApiVersionHeader, strconv.Itoa(types.ApiVersion))
+
+		v, err := types.ParseApiVersion(r.Header.Get(types.ApiVersionHeader))
+		if err != nil {
+			return api.Errorf(400, "%s", err)
+		}
+
+		if v < types.ApiVersionMin {
+			return api.Errorf(400, "client api version %d is no longer supported by this rack (minimum %d), update with: cx update", v, types.ApiVersionMin)
+		}
+
+		c.Tag("api=%d", v)
+
+		return fn(w, r, c)
+	}
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ApiVersion is the version of the rack api spoken by this build, it is raised when params or responses change incompatibly
+	ApiVersion = 1
+
+	// ApiVersionMin is the oldest client api version a rack still serves
+	ApiVersionMin = 1
+
+	// ApiVersionHeader carries the api version of a client request and of a rack response
+	ApiVersionHeader = "Api-Version"
+)
+
+// ApiFeatures are the optional parts of the rack api that clients can check for before using them
+// a provider adds those only it supports, such as logs.drains, to the features of its SystemOptions
+var ApiFeatures = []string{"audit", "events", "logs.cursors", "logs.filters", "logs.json", "metrics", "openapi", "tokens", "webhooks"}
+
+// ParseApiVersion reads an api version header, clients that predate versioning send none and speak the minimum version
+// a version below ApiVersionMin parses so that it can be rejected as no longer supported
+func ParseApiVersion(value string) (int, error) {
+	if value == "" {
+		return ApiVersionMin, nil
+	}
+
+	v, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid api version: %s", value)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// AuditEvent records a mutating call to the rack api
+type AuditEvent struct {
+	Id       string            `json:"id"`
+	Time     time.Time         `json:"time"`
+	Actor    string            `json:"actor"`
+	Token    string            `json:"token,omitempty"`
+	Method   string            `json:"method"`
+	Route    string            `json:"route"`
+	App      string            `json:"app,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Code     int               `json:"code"`
+	Duration int64             `json:"duration"`
+}
+
+type AuditEvents []AuditEvent
+
+type SystemAuditOptions struct {
+	Actor string
+	App   string
+	Limit int
+	Since time.Time
+}
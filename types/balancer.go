@@ -0,0 +1,19 @@
+package types
+
+type Balancer struct {
+	Name string `json:"name"`
+
+	Endpoint  string            `json:"endpoint"`
+	Endpoints BalancerEndpoints `json:"endpoints"`
+}
+
+type Balancers []Balancer
+
+type BalancerEndpoint struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Redirect string `json:"redirect,omitempty"`
+	Target   string `json:"target,omitempty"`
+}
+
+type BalancerEndpoints []BalancerEndpoint
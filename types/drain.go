@@ -0,0 +1,49 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Drain forwards the logs of an app to an external sink
+// the url scheme picks the sink: syslog+tls, syslog+tcp or syslog+udp for a syslog server,
+// http or https to post batches of json entries, or file for a path on the rack host
+type Drain struct {
+	Id      string    `json:"id"`
+	App     string    `json:"app"`
+	Url     string    `json:"url"`
+	Created time.Time `json:"created"`
+}
+
+type Drains []Drain
+
+// DrainSchemes are the url schemes a drain can forward to
+var DrainSchemes = []string{"file", "http", "https", "syslog+tcp", "syslog+tls", "syslog+udp"}
+
+// ParseDrainUrl checks that a url names a sink a drain can forward to
+func ParseDrainUrl(u string) (*url.URL, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return nil, fmt.Errorf("invalid drain url: %s", u)
+	}
+
+	switch pu.Scheme {
+	case "file":
+		if pu.Path == "" || pu.Host != "" {
+			return nil, fmt.Errorf("invalid drain url: %s", u)
+		}
+	case "http", "https":
+		if pu.Host == "" {
+			return nil, fmt.Errorf("invalid drain url: %s", u)
+		}
+	case "syslog+tcp", "syslog+tls", "syslog+udp":
+		if pu.Hostname() == "" || pu.Port() == "" {
+			return nil, fmt.Errorf("invalid drain url: %s", u)
+		}
+	default:
+		return nil, fmt.Errorf("invalid drain url: %s", u)
+	}
+
+	return pu, nil
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Event is published by the rack as builds, releases, processes and apps change
+// actions are named <object>:<verb> such as build:create or release:promote
+type Event struct {
+	Id        string            `json:"id"`
+	Action    string            `json:"action"`
+	App       string            `json:"app,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Status    string            `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+type Events []Event
+
+type EventSendOptions struct {
+	App   string
+	Data  map[string]string
+	Error string
+}
+
+type EventStreamOptions struct {
+	Actions []string
+	App     string
+}
+
+// Match returns true if the event belongs to app and has one of actions
+// an empty app or list of actions matches everything, an action of build:* matches every build action
+func (e Event) Match(app string, actions []string) bool {
+	if app != "" && e.App != app {
+		return false
+	}
+
+	if len(actions) == 0 {
+		return true
+	}
+
+	for _, a := range actions {
+		switch {
+		case a == e.Action:
+			return true
+		case strings.HasSuffix(a, ":*") && strings.HasPrefix(e.Action, strings.TrimSuffix(a, "*")):
+			return true
+		}
+	}
+
+	return false
+}
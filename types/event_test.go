@@ -0,0 +1,31 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventMatch(t *testing.T) {
+	e := types.Event{Action: "build:create", App: "web"}
+
+	assert.True(t, e.Match("", nil))
+	assert.True(t, e.Match("web", nil))
+	assert.True(t, e.Match("", []string{"build:create"}))
+	assert.True(t, e.Match("web", []string{"release:promote", "build:*"}))
+	assert.False(t, e.Match("api", nil))
+	assert.False(t, e.Match("", []string{"build:update"}))
+	assert.False(t, e.Match("", []string{"builds:*"}))
+}
+
+func TestWebhookSign(t *testing.T) {
+	w := types.Webhook{Secret: "secret"}
+
+	sig := w.Sign([]byte("body"))
+
+	assert.Equal(t, "sha256=dc46983557fea127b43af721467eb9b3fde2338fe3e14f51952aa8478c13d355", sig)
+	assert.True(t, w.Verify([]byte("body"), sig))
+	assert.False(t, w.Verify([]byte("other"), sig))
+	assert.False(t, types.Webhook{}.Verify([]byte("body"), types.Webhook{}.Sign([]byte("body"))))
+}
@@ -1,10 +1,322 @@
 package types
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LogsOptions selects the lines of a log stream
+// Cursor resumes a stream after the line it names, Cursors prefixes each line with its own cursor
+// Filter is an expression as read by ParseLogFilter, Format is text or json for one LogEntry per line
 type LogsOptions struct {
-	Filter string
-	Follow bool
-	Prefix bool
-	Since  time.Time
+	Cursor  string
+	Cursors bool
+	Filter  string
+	Follow  bool
+	Format  string
+	Prefix  bool
+	Since   time.Time
+}
+
+// LogEntry is a line of a log stream with what is known about where it came from
+// Fields are parsed from messages written as a json object or as logfmt key=value pairs
+type LogEntry struct {
+	Cursor    string            `json:"cursor,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	App       string            `json:"app,omitempty"`
+	Service   string            `json:"service,omitempty"`
+	Process   string            `json:"process,omitempty"`
+	Release   string            `json:"release,omitempty"`
+	Stream    string            `json:"stream,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// Field returns the value of a named attribute of the entry or of one of its parsed fields
+func (e LogEntry) Field(name string) (string, bool) {
+	switch name {
+	case "app":
+		return e.App, true
+	case "message":
+		return e.Message, true
+	case "process":
+		return e.Process, true
+	case "release":
+		return e.Release, true
+	case "service":
+		return e.Service, true
+	case "stream":
+		return e.Stream, true
+	}
+
+	v, ok := e.Fields[name]
+
+	return v, ok
+}
+
+// Source names where an entry came from in the app/service/process form used to prefix text logs
+func (e LogEntry) Source() string {
+	parts := []string{}
+
+	for _, p := range []string{e.App, e.Service, e.Process} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// CursorSource names the source of an entry that its cursor is kept for, the lines of one source arrive in order
+// entries with neither a process nor a stream, such as those of a stored build log, share a single source
+func (e LogEntry) CursorSource() string {
+	parts := []string{}
+
+	for _, p := range []string{e.Process, e.Stream} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// ParseLogFields reads the fields of a message written as a json object or as logfmt key=value pairs
+// nested json values are kept as json, messages with no fields return nil
+func ParseLogFields(message string) map[string]string {
+	if m := strings.TrimSpace(message); strings.HasPrefix(m, "{") {
+		if fields, ok := parseJSONFields(m); ok {
+			return fields
+		}
+	}
+
+	return parseLogfmtFields(message)
+}
+
+func parseJSONFields(message string) (map[string]string, bool) {
+	var values map[string]interface{}
+
+	d := json.NewDecoder(strings.NewReader(message))
+	d.UseNumber()
+
+	if err := d.Decode(&values); err != nil {
+		return nil, false
+	}
+
+	fields := map[string]string{}
+
+	for k, v := range values {
+		switch t := v.(type) {
+		case nil:
+			fields[k] = ""
+		case string:
+			fields[k] = t
+		case json.Number:
+			fields[k] = t.String()
+		case bool:
+			fields[k] = strconv.FormatBool(t)
+		default:
+			data, err := json.Marshal(t)
+			if err != nil {
+				continue
+			}
+			fields[k] = string(data)
+		}
+	}
+
+	return fields, true
+}
+
+// parseLogfmtFields picks the key=value pairs out of a message, words without an equals sign are skipped
+func parseLogfmtFields(message string) map[string]string {
+	var fields map[string]string
+
+	for _, token := range splitQuoted(message) {
+		i := strings.Index(token, "=")
+		if i < 1 {
+			continue
+		}
+
+		key := token[0:i]
+
+		if strings.ContainsAny(key, "\"<>!~") {
+			continue
+		}
+
+		if fields == nil {
+			fields = map[string]string{}
+		}
+
+		fields[key] = unquote(token[i+1:])
+	}
+
+	return fields
+}
+
+// splitQuoted splits s on spaces outside of double quotes, the quotes are kept
+func splitQuoted(s string) []string {
+	tokens := []string{}
+
+	var buf bytes.Buffer
+
+	quoted := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case (r == ' ' || r == '\t') && !quoted:
+			if buf.Len() > 0 {
+				tokens = append(tokens, buf.String())
+				buf.Reset()
+			}
+			continue
+		}
+
+		buf.WriteRune(r)
+	}
+
+	if buf.Len() > 0 {
+		tokens = append(tokens, buf.String())
+	}
+
+	return tokens
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, "\"") && strings.HasSuffix(s, "\"") {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
+
+// LogCursor is the position of a line in a log stream
+// lines are ordered by time and then by their index among the lines logged at the same time
+type LogCursor struct {
+	Time  time.Time
+	Index int
+}
+
+// ParseLogCursor reads a cursor in the <unixnano>-<index> form returned by LogCursor.String
+func ParseLogCursor(value string) (LogCursor, error) {
+	parts := strings.SplitN(value, "-", 2)
+
+	if len(parts) != 2 {
+		return LogCursor{}, fmt.Errorf("invalid cursor: %s", value)
+	}
+
+	ns, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || ns < 0 {
+		return LogCursor{}, fmt.Errorf("invalid cursor: %s", value)
+	}
+
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return LogCursor{}, fmt.Errorf("invalid cursor: %s", value)
+	}
+
+	c := LogCursor{Index: index}
+
+	// lines without a time, such as those of a stored build log, are ordered by index alone
+	if ns > 0 {
+		c.Time = time.Unix(0, ns).UTC()
+	}
+
+	return c, nil
+}
+
+// Before returns true if c comes earlier in the stream than o
+func (c LogCursor) Before(o LogCursor) bool {
+	if c.Time.Equal(o.Time) {
+		return c.Index < o.Index
+	}
+
+	return c.Time.Before(o.Time)
+}
+
+func (c LogCursor) String() string {
+	var ns int64
+
+	if !c.Time.IsZero() {
+		ns = c.Time.UnixNano()
+	}
+
+	return fmt.Sprintf("%d-%d", ns, c.Index)
+}
+
+// LogCursors is the position of a stream merged from several sources, with a cursor for each source
+// the lines of different sources arrive in any order relative to each other so each source is resumed on its own
+type LogCursors map[string]LogCursor
+
+// ParseLogCursors reads cursors in the <source>=<cursor>,... form returned by LogCursors.String
+// a cursor without a source is that of the unnamed source, which keeps the cursors of single source streams in the LogCursor form
+func ParseLogCursors(value string) (LogCursors, error) {
+	cs := LogCursors{}
+
+	for _, part := range strings.Split(value, ",") {
+		source := ""
+
+		if i := strings.Index(part, "="); i >= 0 {
+			source = part[0:i]
+			part = part[i+1:]
+
+			if source == "" {
+				return nil, fmt.Errorf("invalid cursor: %s", value)
+			}
+		}
+
+		if _, ok := cs[source]; ok {
+			return nil, fmt.Errorf("invalid cursor: %s", value)
+		}
+
+		c, err := ParseLogCursor(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", value)
+		}
+
+		cs[source] = c
+	}
+
+	return cs, nil
+}
+
+func (cs LogCursors) String() string {
+	sources := make([]string, 0, len(cs))
+
+	for s := range cs {
+		sources = append(sources, s)
+	}
+
+	sort.Strings(sources)
+
+	parts := make([]string, len(sources))
+
+	for i, s := range sources {
+		if s == "" {
+			parts[i] = cs[s].String()
+		} else {
+			parts[i] = fmt.Sprintf("%s=%s", s, cs[s])
+		}
+	}
+
+	return strings.Join(parts, ",")
 }
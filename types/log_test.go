@@ -0,0 +1,126 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogCursor(t *testing.T) {
+	c := types.LogCursor{Time: time.Unix(1500000000, 123456789).UTC(), Index: 2}
+
+	assert.Equal(t, "1500000000123456789-2", c.String())
+
+	p, err := types.ParseLogCursor(c.String())
+	require.NoError(t, err)
+	assert.Equal(t, c, p)
+
+	p, err = types.ParseLogCursor("0-4")
+	require.NoError(t, err)
+	assert.Equal(t, types.LogCursor{Index: 4}, p)
+	assert.Equal(t, "0-4", p.String())
+}
+
+func TestLogCursorBefore(t *testing.T) {
+	a := types.LogCursor{Time: time.Unix(100, 0), Index: 3}
+	b := types.LogCursor{Time: time.Unix(100, 0), Index: 4}
+	c := types.LogCursor{Time: time.Unix(101, 0), Index: 0}
+
+	assert.True(t, a.Before(b))
+	assert.True(t, b.Before(c))
+	assert.False(t, c.Before(a))
+	assert.False(t, a.Before(a))
+}
+
+func TestParseLogCursorInvalid(t *testing.T) {
+	for _, v := range []string{"", "100", "foo-1", "100-foo", "-1-0", "100--1"} {
+		_, err := types.ParseLogCursor(v)
+		assert.EqualError(t, err, "invalid cursor: "+v)
+	}
+}
+
+func TestLogCursors(t *testing.T) {
+	cs := types.LogCursors{
+		"P2/stdout": {Time: time.Unix(200, 0).UTC(), Index: 1},
+		"P1/stderr": {Time: time.Unix(100, 0).UTC()},
+	}
+
+	assert.Equal(t, "P1/stderr=100000000000-0,P2/stdout=200000000000-1", cs.String())
+
+	p, err := types.ParseLogCursors(cs.String())
+	require.NoError(t, err)
+	assert.Equal(t, cs, p)
+
+	p, err = types.ParseLogCursors("0-4")
+	require.NoError(t, err)
+	assert.Equal(t, types.LogCursors{"": {Index: 4}}, p)
+	assert.Equal(t, "0-4", p.String())
+}
+
+func TestParseLogCursorsInvalid(t *testing.T) {
+	for _, v := range []string{"", "P1=", "=100-0", "P1=100-0,P1=200-0", "P1=100-0,", "P1=foo-0"} {
+		_, err := types.ParseLogCursors(v)
+		assert.EqualError(t, err, "invalid cursor: "+v)
+	}
+}
+
+func TestParseLogFields(t *testing.T) {
+	assert.Equal(t, map[string]string{"at": "info", "method": "GET", "path": "/a b", "status": "500"}, types.ParseLogFields(`at=info method=GET path="/a b" status=500 took 5ms`))
+	assert.Equal(t, map[string]string{"level": "error", "status": "502", "ok": "false", "user": `{"id":1}`, "none": ""}, types.ParseLogFields(`{"level":"error","status":502,"ok":false,"user":{"id":1},"none":null}`))
+	assert.Nil(t, types.ParseLogFields("plain text"))
+	assert.Equal(t, map[string]string{"b": "2"}, types.ParseLogFields("{not json} b=2"))
+}
+
+func TestLogFilter(t *testing.T) {
+	e := types.LogEntry{
+		App:     "app",
+		Service: "web",
+		Message: "request failed",
+		Fields:  map[string]string{"status": "503", "path": "/health"},
+	}
+
+	tests := []struct {
+		Filter string
+		Match  bool
+	}{
+		{"", true},
+		{"failed", true},
+		{"failed!", false},
+		{"succeeded", false},
+		{`"request failed"`, true},
+		{`"failed request"`, false},
+		{"service=web", true},
+		{"service=worker", false},
+		{"service!=worker", true},
+		{"status>=500", true},
+		{"status>=500 service=web failed", true},
+		{"status>503", false},
+		{"status<1000", true},
+		{"path~health", true},
+		{"path=/health", true},
+		{"missing=1", false},
+		{"missing!=1", true},
+		{`message="request failed"`, true},
+	}
+
+	for _, tt := range tests {
+		f, err := types.ParseLogFilter(tt.Filter)
+		require.NoError(t, err, tt.Filter)
+		assert.Equal(t, tt.Match, f.Match(e), tt.Filter)
+	}
+}
+
+func TestLogFilterWords(t *testing.T) {
+	f, err := types.ParseLogFilter(`error service=web "timed out"`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"error", "timed out"}, f.Words())
+}
+
+func TestParseLogFilterInvalid(t *testing.T) {
+	_, err := types.ParseLogFilter("=web")
+	assert.EqualError(t, err, "invalid filter term: =web")
+}
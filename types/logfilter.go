@@ -0,0 +1,135 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LogFilter selects log entries, every term must match
+// a term is a word or "quoted phrase" found in the message, or a field compared to a value such as service=web or status>=500
+// the operators are = != > >= < <= and ~ for a field that contains the value
+// fields are app, service, process, release, stream, message and any field parsed from the message
+// comparisons are numeric when both sides are numbers
+type LogFilter []logFilterTerm
+
+type logFilterTerm struct {
+	field string
+	op    string
+	value string
+}
+
+var logFilterOperators = []string{">=", "<=", "!=", "=", ">", "<", "~"}
+
+// ParseLogFilter reads a filter expression, an empty expression matches everything
+func ParseLogFilter(expr string) (LogFilter, error) {
+	f := LogFilter{}
+
+	for _, token := range splitQuoted(expr) {
+		t, err := parseLogFilterTerm(token)
+		if err != nil {
+			return nil, err
+		}
+
+		f = append(f, t)
+	}
+
+	return f, nil
+}
+
+func parseLogFilterTerm(token string) (logFilterTerm, error) {
+	if strings.HasPrefix(token, "\"") {
+		return logFilterTerm{value: unquote(token)}, nil
+	}
+
+	i := strings.IndexAny(token, "=!<>~")
+
+	switch {
+	case i == -1:
+		return logFilterTerm{value: token}, nil
+	case i == 0:
+		return logFilterTerm{}, fmt.Errorf("invalid filter term: %s", token)
+	}
+
+	for _, op := range logFilterOperators {
+		if strings.HasPrefix(token[i:], op) {
+			return logFilterTerm{field: token[0:i], op: op, value: unquote(token[i+len(op):])}, nil
+		}
+	}
+
+	// a lone ! is part of a word
+	return logFilterTerm{value: token}, nil
+}
+
+// Match returns true if every term of the filter matches the entry
+func (f LogFilter) Match(e LogEntry) bool {
+	for _, t := range f {
+		if !t.match(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Words returns the terms that search the message text, a log store with its own text search can apply them first
+func (f LogFilter) Words() []string {
+	words := []string{}
+
+	for _, t := range f {
+		if t.field == "" {
+			words = append(words, t.value)
+		}
+	}
+
+	return words
+}
+
+func (t logFilterTerm) match(e LogEntry) bool {
+	if t.field == "" {
+		return strings.Contains(e.Message, t.value)
+	}
+
+	v, ok := e.Field(t.field)
+	if !ok {
+		return t.op == "!="
+	}
+
+	switch t.op {
+	case "~":
+		return strings.Contains(v, t.value)
+	case "=":
+		return compare(v, t.value) == 0
+	case "!=":
+		return compare(v, t.value) != 0
+	case ">":
+		return compare(v, t.value) > 0
+	case ">=":
+		return compare(v, t.value) >= 0
+	case "<":
+		return compare(v, t.value) < 0
+	case "<=":
+		return compare(v, t.value) <= 0
+	}
+
+	return false
+}
+
+// compare orders two values as numbers if they both are and as strings otherwise
+func compare(a, b string) int {
+	fa, erra := strconv.ParseFloat(a, 64)
+	fb, errb := strconv.ParseFloat(b, 64)
+
+	if erra == nil && errb == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(a, b)
+}
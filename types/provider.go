@@ -13,17 +13,26 @@ type Provider interface {
 	AppLogs(app string, opts LogsOptions) (io.ReadCloser, error)
 	AppRegistry(app string) (*Registry, error)
 
+	BalancerList(app string) (Balancers, error)
+
 	BuildCreate(app, url string, opts BuildCreateOptions) (*Build, error)
 	// BuildExport(app, id string, w io.Writer) error
 	BuildGet(app, id string) (*Build, error)
 	// BuildImport(app string, r io.Reader) (*structs.Build, error)
-	BuildLogs(app, id string) (io.ReadCloser, error)
+	BuildLogs(app, id string, opts LogsOptions) (io.ReadCloser, error)
 	BuildList(app string) (Builds, error)
 	BuildUpdate(app, id string, opts BuildUpdateOptions) (*Build, error)
 
 	CacheFetch(app, cache, key string) (map[string]string, error)
 	CacheStore(app, cache, key string, attrs map[string]string, opts CacheStoreOptions) error
 
+	DrainCreate(app, url string) (*Drain, error)
+	DrainDelete(app, id string) error
+	DrainList(app string) (Drains, error)
+
+	EventSend(action string, opts EventSendOptions) error
+	EventStream(opts EventStreamOptions) (io.ReadCloser, error)
+
 	FilesDelete(app, pid string, files []string) error
 	FilesUpload(app, pid string, r io.Reader) error
 
@@ -68,11 +77,18 @@ type Provider interface {
 	ServiceGet(app, name string) (*Service, error)
 	ServiceList(app string) (Services, error)
 
+	SystemAudit(opts SystemAuditOptions) (AuditEvents, error)
+	SystemAuditRecord(event AuditEvent) error
 	SystemGet() (*System, error)
 	SystemInstall(name string, opts SystemInstallOptions) (string, error)
 	SystemLogs(opts LogsOptions) (io.ReadCloser, error)
 	SystemOptions() (map[string]string, error)
 	// SystemProxy(host string, port int, in io.Reader) (io.ReadCloser, error)
+	SystemResourceCreate(name, kind string) (*Resource, error)
+	SystemResourceDelete(name string) error
+	SystemResourceGet(name string) (*Resource, error)
+	SystemResourceList() (Resources, error)
+	SystemResourceProxy(name string, in io.Reader) (io.ReadCloser, error)
 	SystemUninstall(name string, opts SystemInstallOptions) error
 	SystemUpdate(opts SystemUpdateOptions) error
 
@@ -81,6 +97,15 @@ type Provider interface {
 	TableQuery(app, table, query string) (TableRows, error)
 	TableTruncate(app, table string) error
 
+	TokenCreate(name string, opts TokenCreateOptions) (*Token, error)
+	TokenDelete(id string) error
+	TokenGet(id string) (*Token, error)
+	TokenList() (Tokens, error)
+
+	WebhookCreate(url string, opts WebhookCreateOptions) (*Webhook, error)
+	WebhookDelete(id string) error
+	WebhookList() (Webhooks, error)
+
 	WithContext(ctx context.Context) Provider
 
 	Workers()
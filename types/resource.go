@@ -3,8 +3,9 @@ package types
 type Resource struct {
 	Name string `json:"name"`
 
-	Endpoint string `json:"endpoint"`
-	Type     string `json:"type"`
+	Apps     []string `json:"apps,omitempty"`
+	Endpoint string   `json:"endpoint"`
+	Type     string   `json:"type"`
 }
 
 type Resources []Resource
@@ -3,12 +3,13 @@ package types
 import "io"
 
 type System struct {
-	Account string `json:"account"`
-	Name    string `json:"name"`
-	Image   string `json:"image"`
-	Region  string `json:"region"`
-	Status  string `json:"status"`
-	Version string `json:"version"`
+	Account     string `json:"account"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	Region      string `json:"region"`
+	Status      string `json:"status"`
+	Version     string `json:"version"`
 }
 
 type SystemInstallOptions struct {
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeAdmin  = "admin"
+	ScopeDeploy = "deploy"
+	ScopeRead   = "read"
+)
+
+// Token is a named credential for the rack api
+// only a hash of the secret is kept, the secret is returned once when the token is created
+type Token struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name"`
+	Scopes  []string  `json:"scopes"`
+	Created time.Time `json:"created"`
+
+	Hash   string `json:"hash,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+type Tokens []Token
+
+type TokenCreateOptions struct {
+	Scopes []string
+}
+
+// NewToken generates a token with a random secret
+// scopes are admin, deploy, read, or app:<name> to limit the token to an app
+func NewToken(name string, scopes []string) (*Token, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name required")
+	}
+
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope required")
+	}
+
+	for _, s := range scopes {
+		if err := validScope(s); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]byte, 32)
+
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+
+	secret := fmt.Sprintf("%x", data)
+
+	t := &Token{
+		Id:      Id("T", 10),
+		Name:    name,
+		Scopes:  scopes,
+		Created: time.Now().UTC(),
+		Hash:    tokenHash(secret),
+		Secret:  secret,
+	}
+
+	return t, nil
+}
+
+// ParseToken splits a token value into its id and secret
+func ParseToken(value string) (string, string, error) {
+	parts := strings.SplitN(value, ".", 2)
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid token")
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// Apps returns the apps a token is limited to, or none if it is not limited
+func (t Token) Apps() []string {
+	apps := []string{}
+
+	for _, s := range t.Scopes {
+		if strings.HasPrefix(s, "app:") {
+			apps = append(apps, strings.TrimPrefix(s, "app:"))
+		}
+	}
+
+	return apps
+}
+
+// HasScope returns true if the token was granted scope
+func (t Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Value returns the credential to present for a newly created token
+func (t Token) Value() string {
+	return fmt.Sprintf("%s.%s", t.Id, t.Secret)
+}
+
+// Verify returns true if secret matches the token
+func (t Token) Verify(secret string) bool {
+	return t.Hash != "" && subtle.ConstantTimeCompare([]byte(tokenHash(secret)), []byte(t.Hash)) == 1
+}
+
+func tokenHash(secret string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(secret)))
+}
+
+func validScope(scope string) error {
+	switch {
+	case scope == ScopeAdmin, scope == ScopeDeploy, scope == ScopeRead:
+		return nil
+	case strings.HasPrefix(scope, "app:") && len(scope) > 4:
+		return nil
+	}
+
+	return fmt.Errorf("invalid scope: %s", scope)
+}
@@ -0,0 +1,53 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/convox/praxis/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToken(t *testing.T) {
+	tk, err := types.NewToken("ci", []string{types.ScopeDeploy, "app:web"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "T", tk.Id[0:1])
+	assert.Len(t, tk.Secret, 64)
+	assert.NotEqual(t, tk.Secret, tk.Hash)
+	assert.Equal(t, []string{"web"}, tk.Apps())
+	assert.True(t, tk.HasScope(types.ScopeDeploy))
+	assert.False(t, tk.HasScope(types.ScopeAdmin))
+
+	id, secret, err := types.ParseToken(tk.Value())
+	require.NoError(t, err)
+
+	assert.Equal(t, tk.Id, id)
+	assert.True(t, tk.Verify(secret))
+	assert.False(t, tk.Verify("wrong"))
+}
+
+func TestNewTokenInvalid(t *testing.T) {
+	_, err := types.NewToken("", []string{types.ScopeAdmin})
+	assert.EqualError(t, err, "name required")
+
+	_, err = types.NewToken("ci", nil)
+	assert.EqualError(t, err, "at least one scope required")
+
+	_, err = types.NewToken("ci", []string{"write"})
+	assert.EqualError(t, err, "invalid scope: write")
+
+	_, err = types.NewToken("ci", []string{"app:"})
+	assert.EqualError(t, err, "invalid scope: app:")
+}
+
+func TestParseTokenInvalid(t *testing.T) {
+	for _, v := range []string{"", "T123", ".secret", "T123."} {
+		_, _, err := types.ParseToken(v)
+		assert.EqualError(t, err, "invalid token", v)
+	}
+}
+
+func TestTokenVerifyWithoutHash(t *testing.T) {
+	assert.False(t, types.Token{}.Verify(""))
+}
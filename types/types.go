@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -40,3 +41,16 @@ func Key(length int) (string, error) {
 
 	return key[0:length], nil
 }
+
+// Fingerprint returns the sha256 fingerprint of a der encoded certificate
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+
+	parts := make([]string, len(sum))
+
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
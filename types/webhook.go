@@ -0,0 +1,40 @@
+package types
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// Webhook receives rack events as json posts
+// each delivery is signed with an hmac of the body in the X-Convox-Signature header
+type Webhook struct {
+	Id      string    `json:"id"`
+	Url     string    `json:"url"`
+	Actions []string  `json:"actions,omitempty"`
+	App     string    `json:"app,omitempty"`
+	Created time.Time `json:"created"`
+
+	Secret string `json:"secret,omitempty"`
+}
+
+type Webhooks []Webhook
+
+type WebhookCreateOptions struct {
+	Actions []string
+	App     string
+}
+
+// Sign returns the signature of a delivery body
+func (w Webhook) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+
+	return fmt.Sprintf("sha256=%x", mac.Sum(nil))
+}
+
+// Verify returns true if signature matches a delivery body
+func (w Webhook) Verify(body []byte, signature string) bool {
+	return w.Secret != "" && hmac.Equal([]byte(w.Sign(body)), []byte(signature))
+}